@@ -0,0 +1,210 @@
+package exiftool
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedApiOption is returned when an API option is not supported by the installed exiftool
+var ErrUnsupportedApiOption = errors.New("api option not supported by exiftool")
+
+// Names of common exiftool API options, see https://exiftool.org/ExifTool.html#Options
+const (
+	// ApiQuickTimeUTC assumes that QuickTime dates are stored as UTC and converts them to local time
+	ApiQuickTimeUTC = "QuickTimeUTC"
+	// ApiLargeFileSupport enables the support of files larger than 2GB
+	ApiLargeFileSupport = "LargeFileSupport"
+	// ApiStruct outputs structured XMP information as structures instead of flattened tags
+	ApiStruct = "Struct"
+	// ApiRequestTags extracts the given tags even if they are not extracted by default (e.g. "FileInodeChangeDate")
+	ApiRequestTags = "RequestTags"
+	// ApiTimeZone sets the time zone used for local time conversions (e.g. "Europe/Paris")
+	ApiTimeZone = "TimeZone"
+	// ApiMissingTagValue sets the value output for missing tags
+	ApiMissingTagValue = "MissingTagValue"
+	// ApiGeoMaxIntSecs sets the maximum interpolation time, in seconds, when geotagging
+	ApiGeoMaxIntSecs = "GeoMaxIntSecs"
+	// ApiGeoMaxExtSecs sets the maximum extrapolation time, in seconds, when geotagging
+	ApiGeoMaxExtSecs = "GeoMaxExtSecs"
+	// ApiFilter applies a Perl expression to all the output values
+	ApiFilter = "Filter"
+	// ApiLimitLongValues sets the length above which values are truncated
+	ApiLimitLongValues = "LimitLongValues"
+)
+
+// apiOptionNames are the API options known by exiftool up to apiOptionNamesVersion, see
+// https://exiftool.org/ExifTool.html#Options
+var apiOptionNames = []string{
+	"ByteOrder", "ByteUnit", "Charset", "CharsetEXIF", "CharsetFileName", "CharsetID3", "CharsetIPTC",
+	"CharsetPhotoshop", "CharsetQuickTime", "CharsetRIFF", "Compact", "Composite", "Compress", "CoordFormat",
+	"DateFormat", "Duplicates", "Escape", "Exclude", "ExtendedXMP", "ExtractEmbedded", "FastScan", "Filter",
+	"FilterW", "FixBase", "GeoMaxExtSecs", "GeoMaxHDOP", "GeoMaxIntSecs", "GeoMaxPDOP", "GeoMinSats",
+	"GeoSpeedRef", "Geolocation", "GeolocAltNames", "GeolocFeature", "GeolocMaxDist", "GeolocMinPop",
+	"GlobalTimeShift", "Group", "HexTagIDs", "HtmlDump", "HtmlDumpBase", "IgnoreMinorErrors", "IgnoreTags",
+	"ImageHashType", "KeepUTCTime", "Lang", "LargeFileSupport", "LimitLongValues", "List", "ListItem",
+	"ListJoin", "ListSep", "ListSplit", "MakerNotes", "MDItemTags", "MissingTagValue", "NoMultiExif",
+	"NoPDFList", "NoWarning", "Password", "PrintConv", "QuickTimeHandler", "QuickTimePad", "QuickTimeUTC",
+	"RequestAll", "RequestTags", "SaveFormat", "SavePath", "ScanForXMP", "Sort", "Sort2", "StrictDate",
+	"Struct", "SystemTags", "TextOut", "TimeZone", "Unknown", "UserParam", "Validate", "Verbose",
+	"WindowsWideFile", "WriteMode", "XAttrTags", "XMPAutoConv",
+}
+
+// apiOptionNamesVersion is the version of exiftool apiOptionNames has been built from: newer
+// versions may support options that are not listed
+const apiOptionNamesVersion = "12.78"
+
+// apiOptionVersions are the exiftool versions which introduced API options, the options that are
+// not listed being supported by every version of exiftool accepting -api
+var apiOptionVersions = map[string]string{
+	"Geolocation":    "12.78",
+	"GeolocAltNames": "12.78",
+	"GeolocFeature":  "12.78",
+	"GeolocMaxDist":  "12.78",
+	"GeolocMinPop":   "12.78",
+	"ImageHashType":  "12.26",
+}
+
+// ApiOption is an exiftool API option (see https://exiftool.org/ExifTool.html#Options). Name is
+// one of the options known by exiftool (e.g. ApiQuickTimeUTC), case insensitively. An empty Value
+// enables the option (sets it to 1). Names are checked against the installed version of exiftool
+// (see ErrUnsupportedApiOption): options introduced by recent versions are rejected by older
+// ones, and unknown names are only accepted by versions newer than the ones this library knows.
+type ApiOption struct {
+	Name  string
+	Value string
+}
+
+// String returns the option as passed to exiftool's -api
+func (o ApiOption) String() string {
+	if o.Value == "" {
+		return o.Name
+	}
+	return o.Name + "=" + o.Value
+}
+
+// validate checks that the option can be sent to exiftool, its name being checked against the
+// installed version by checkApiOptions
+func (o ApiOption) validate() error {
+	if o.Name == "" || strings.ContainsAny(o.Name, "= \r\n") || strings.ContainsAny(o.Value, "\r\n") {
+		return fmt.Errorf("%w: %q", ErrInvalidArgument, o.String())
+	}
+	return nil
+}
+
+// canonicalName returns the name of the option as spelled by exiftool, or "" if the option is unknown
+func (o ApiOption) canonicalName() string {
+	for _, n := range apiOptionNames {
+		if strings.EqualFold(n, o.Name) {
+			return n
+		}
+	}
+	return ""
+}
+
+// checkApiOptions checks that the installed exiftool supports the options. exiftool's version is
+// only read if one of the options is unknown or requires a minimum version. The caller must hold
+// the lock.
+func (e *Exiftool) checkApiOptions(opts []ApiOption) error {
+	for _, o := range opts {
+		name := o.canonicalName()
+		since, ok := apiOptionVersions[name]
+		if name != "" && !ok {
+			continue
+		}
+		v, err := e.exiftoolVersion()
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			c, err := compareVersions(v, apiOptionNamesVersion)
+			if err != nil {
+				return err
+			}
+			if c <= 0 {
+				return fmt.Errorf("%w: unknown api option %q (installed: %v)", ErrUnsupportedApiOption, o.Name, v)
+			}
+			continue
+		}
+		c, err := compareVersions(v, since)
+		if err != nil {
+			return err
+		}
+		if c < 0 {
+			return fmt.Errorf("%w: %v requires exiftool %v (installed: %v)", ErrUnsupportedApiOption, o.Name, since, v)
+		}
+	}
+	return nil
+}
+
+// compareVersions compares exiftool versions (e.g. "12.40") by major and minor numbers, returning
+// -1, 0 or 1 if a is older than, the same as or newer than b
+func compareVersions(a, b string) (int, error) {
+	va, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range va {
+		if va[i] < vb[i] {
+			return -1, nil
+		}
+		if va[i] > vb[i] {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// parseVersion parses the major and minor numbers of an exiftool version (e.g. "12.40")
+func parseVersion(v string) ([2]int, error) {
+	var res [2]int
+	parts := strings.SplitN(strings.TrimSpace(v), ".", 2)
+	if len(parts) != 2 {
+		return res, fmt.Errorf("invalid exiftool version %q", v)
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return res, fmt.Errorf("invalid exiftool version %q", v)
+		}
+		res[i] = n
+	}
+	return res, nil
+}
+
+// ApiOptions defines -api values to pass to exiftool, the names of the options being checked
+// against the installed version (see ApiOption): NewExiftool fails if the installed exiftool
+// doesn't support one of the options.
+// Sample :
+//   e, err := NewExiftool(ApiOptions(
+//     ApiOption{Name: ApiQuickTimeUTC},
+//     ApiOption{Name: ApiRequestTags, Value: "FileInodeChangeDate"},
+//   ))
+func ApiOptions(opts ...ApiOption) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		args, err := apiArgs(opts)
+		if err != nil {
+			return err
+		}
+		e.extraInitArgs = append(e.extraInitArgs, args...)
+		e.apiOptions = append(e.apiOptions, opts...)
+		return nil
+	}
+}
+
+// apiArgs validates API options and returns the matching exiftool arguments
+func apiArgs(opts []ApiOption) ([]string, error) {
+	var args []string
+	for _, o := range opts {
+		if err := o.validate(); err != nil {
+			return nil, err
+		}
+		args = append(args, "-api", o.String())
+	}
+	return args, nil
+}
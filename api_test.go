@@ -0,0 +1,154 @@
+package exiftool
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApiOptionString(t *testing.T) {
+	assert.Equal(t, "QuickTimeUTC", ApiOption{Name: ApiQuickTimeUTC}.String())
+	assert.Equal(t, "TimeZone=Europe/Paris", ApiOption{Name: ApiTimeZone, Value: "Europe/Paris"}.String())
+}
+
+func TestApiOptions(t *testing.T) {
+	var tcs = []struct {
+		tcID    string
+		inOpts  []ApiOption
+		expArgs []string
+		expErr  bool
+	}{
+		{"none", nil, nil, false},
+		{"valid", []ApiOption{{Name: ApiQuickTimeUTC}, {Name: ApiRequestTags, Value: "FileInodeChangeDate"}}, []string{"-api", "QuickTimeUTC", "-api", "RequestTags=FileInodeChangeDate"}, false},
+		{"caseInsensitive", []ApiOption{{Name: "largefilesupport", Value: "1"}}, []string{"-api", "largefilesupport=1"}, false},
+		{"unknown", []ApiOption{{Name: ApiStruct}, {Name: "Unknown2"}}, []string{"-api", "Struct", "-api", "Unknown2"}, false},
+		{"empty", []ApiOption{{}}, nil, true},
+		{"invalidName", []ApiOption{{Name: "Struct=1"}}, nil, true},
+		{"newLine", []ApiOption{{Name: ApiFilter, Value: "s/a/b/\n-execute"}}, nil, true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			e := Exiftool{}
+			err := ApiOptions(tc.inOpts...)(&e)
+			assert.Equal(t, tc.expErr, err != nil)
+			assert.Equal(t, tc.expArgs, e.extraInitArgs)
+		})
+	}
+}
+
+func TestApiOptionsInvalidArgument(t *testing.T) {
+	err := ApiOptions(ApiOption{Name: ApiFilter, Value: "\r"})(&Exiftool{})
+	assert.True(t, errors.Is(err, ErrInvalidArgument))
+}
+
+func TestCheckApiOptions(t *testing.T) {
+	var tcs = []struct {
+		tcID       string
+		inVersion  string
+		inOpts     []ApiOption
+		expErr     bool
+		expErrType error
+	}{
+		{"noMinimumVersion", "", []ApiOption{{Name: ApiQuickTimeUTC}, {Name: ApiStruct}}, false, nil},
+		{"supported", "12.78", []ApiOption{{Name: "geolocation"}}, false, nil},
+		{"newerVersion", "13.01", []ApiOption{{Name: "ImageHashType", Value: "SHA256"}}, false, nil},
+		{"unsupported", "12.40", []ApiOption{{Name: ApiQuickTimeUTC}, {Name: "Geolocation"}}, true, ErrUnsupportedApiOption},
+		{"invalidVersion", "abc", []ApiOption{{Name: "Geolocation"}}, true, nil},
+		{"unknown", "12.40", []ApiOption{{Name: "Unknown2"}}, true, ErrUnsupportedApiOption},
+		{"unknownSameVersion", apiOptionNamesVersion, []ApiOption{{Name: "Unknown2"}}, true, ErrUnsupportedApiOption},
+		{"unknownNewerVersion", "99.01", []ApiOption{{Name: "Unknown2"}}, false, nil},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			e := Exiftool{version: tc.inVersion}
+			err := e.checkApiOptions(tc.inOpts)
+			assert.Equal(t, tc.expErr, err != nil)
+			if tc.expErrType != nil {
+				assert.True(t, errors.Is(err, tc.expErrType))
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	var tcs = []struct {
+		tcID   string
+		inA    string
+		inB    string
+		expVal int
+		expErr bool
+	}{
+		{"equal", "12.40", "12.40", 0, false},
+		{"olderMinor", "12.09", "12.40", -1, false},
+		{"newerMinor", "12.100", "12.99", 1, false},
+		{"newerMajor", "13.01", "12.78", 1, false},
+		{"olderMajor", "9.99", "12.00", -1, false},
+		{"spaces", "12.40\n", "12.40", 0, false},
+		{"noMinor", "12", "12.40", 0, true},
+		{"invalid", "12.4a", "12.40", 0, true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			got, err := compareVersions(tc.inA, tc.inB)
+			assert.Equal(t, tc.expErr, err != nil)
+			assert.Equal(t, tc.expVal, got)
+		})
+	}
+}
+
+func TestApiOptionsExtraction(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool(ApiOptions(ApiOption{Name: ApiRequestTags, Value: "FileInodeChangeDate"}))
+	require.Nil(t, err)
+	defer e.Close()
+	mds := e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	_, err = mds[0].GetString("FileInodeChangeDate")
+	assert.Nil(t, err)
+}
+
+func TestExtractApi(t *testing.T) {
+	var c extractConfig
+	require.Nil(t, ExtractApi(ApiOption{Name: ApiQuickTimeUTC}, ApiOption{Name: ApiTimeZone, Value: "UTC"})(&c))
+	assert.Equal(t, []string{"-api", "QuickTimeUTC", "-api", "TimeZone=UTC"}, c.args)
+
+	assert.NotNil(t, ExtractApi(ApiOption{Name: ""})(&extractConfig{}))
+}
+
+func TestExtractMetadataWithOptions(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	opts := []ExtractOption{ExtractApi(ApiOption{Name: ApiRequestTags, Value: "FileInodeChangeDate"})}
+	mds := e.ExtractMetadataWithOptions(context.Background(), opts, "./testdata/20190404_131804.jpg")
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	assert.True(t, mds[0].Has("FileInodeChangeDate"))
+
+	mds = e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	assert.False(t, mds[0].Has("FileInodeChangeDate"))
+
+	opts = []ExtractOption{ExtractApi(ApiOption{Name: "Unknown\n2"})}
+	mds = e.ExtractMetadataWithOptions(context.Background(), opts, "./testdata/20190404_131804.jpg", "./testdata/gps.jpg")
+	require.Len(t, mds, 2)
+	for _, md := range mds {
+		assert.NotNil(t, md.Err)
+	}
+	assert.Equal(t, "./testdata/gps.jpg", mds[1].File)
+}
@@ -0,0 +1,84 @@
+package exiftool
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// RecordArgFile writes every command sent to exiftool to the given file (created or truncated),
+// in the -@ argfile format, so that the exact argument sequence can be replayed with standalone
+// exiftool when diagnosing a discrepancy. The first lines of the file are comments giving the
+// command line to run. Errors when writing the file are ignored, it is closed by Close.
+// Sample :
+//   e, err := NewExiftool(RecordArgFile("/tmp/exiftool.args"))
+//   // then: exiftool -@ /tmp/exiftool.args
+func RecordArgFile(path string) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		if path == "" {
+			return fmt.Errorf("empty argfile path")
+		}
+		e.argFilePath = path
+		return nil
+	}
+}
+
+// createArgFile creates the argfile recording the commands sent to exiftool started with args
+func createArgFile(path string, args []string) (*os.File, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("error when creating argfile: %w", err)
+	}
+
+	// the arguments reading stdin are replaced by the argfile, the other ones (-config and
+	// -common_args) have to stay on the command line
+	replay := []string{"exiftool"}
+	for i := 0; i < len(args); i++ {
+		if i+len(initArgs) <= len(args) && equalArgs(args[i:i+len(initArgs)], initArgs) {
+			replay = append(replay, "-@", quoteArg(path))
+			i += len(initArgs) - 1
+			continue
+		}
+		replay = append(replay, quoteArg(args[i]))
+	}
+	fmt.Fprintf(f, "# commands recorded by go-exiftool, replay with:\n# %v\n", strings.Join(replay, " "))
+	return f, nil
+}
+
+// recordCommand appends a command to the argfile, if any
+func (e *Exiftool) recordCommand(args []string) {
+	if e.argFile == nil {
+		return
+	}
+	for _, a := range args {
+		fmt.Fprintln(e.argFile, a)
+	}
+	fmt.Fprintln(e.argFile, executeArg)
+}
+
+// closeArgFile closes the argfile, if any
+func (e *Exiftool) closeArgFile() error {
+	if e.argFile == nil {
+		return nil
+	}
+	err := e.argFile.Close()
+	e.argFile = nil
+	return err
+}
+
+func equalArgs(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+// quoteArg quotes arguments that a shell would split
+func quoteArg(a string) string {
+	if a == "" || strings.ContainsAny(a, " \t\"'") {
+		return fmt.Sprintf("%q", a)
+	}
+	return a
+}
@@ -0,0 +1,43 @@
+package exiftool
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordArgFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "exiftool.args")
+	e, err := NewExiftool(RecordArgFile(path), ExtractAllBinaryMetadata())
+	require.Nil(t, err)
+	assert.Equal(t, path, e.Options().ArgFile)
+	mds := e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	require.Nil(t, e.Close())
+
+	b, err := ioutil.ReadFile(path)
+	require.Nil(t, err)
+	lines := strings.SplitN(string(b), "\n", 3)
+	require.Len(t, lines, 3)
+	assert.Equal(t, "# commands recorded by go-exiftool, replay with:", lines[0])
+	// the charset is also a common argument on Windows
+	assert.True(t, strings.HasPrefix(lines[1], "# exiftool -@ "+quoteArg(path)+" -common_args -b"), lines[1])
+	assert.Equal(t, "-j\n./testdata/20190404_131804.jpg\n-execute\n", lines[2])
+}
+
+func TestRecordArgFileErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewExiftool(RecordArgFile(""))
+	assert.NotNil(t, err)
+
+	_, err = NewExiftool(RecordArgFile(filepath.Join(t.TempDir(), "missing", "exiftool.args")))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,38 @@
+package exiftool
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// windowsDistributionBinary is the name of the executable of the stock Windows distribution of
+// exiftool, which is meant to be renamed to exiftool.exe. It can be used as is: the "-- press ENTER
+// --" pause that its name triggers happens when exiftool stops, and is answered by Close closing its
+// standard input. Recent distributions also need the exiftool_files folder next to the executable.
+const windowsDistributionBinary = "exiftool(-k).exe"
+
+// defaultBinaryPath returns the name of the exiftool executable found in $PATH, exiftoolBinary
+// being preferred to its alternative names. If none is found, exiftoolBinary is returned so that
+// the error reports the expected name.
+func defaultBinaryPath() string {
+	for _, name := range append([]string{exiftoolBinary}, exiftoolAltBinaries...) {
+		if _, err := exec.LookPath(name); err == nil {
+			return name
+		}
+	}
+	return exiftoolBinary
+}
+
+// binaryInDir returns the path of the first executable of names found in dir, e.g. the folder in
+// which the Windows distribution of exiftool was extracted
+func binaryInDir(dir string, names []string) (string, error) {
+	for _, name := range names {
+		p := filepath.Join(dir, name)
+		if fi, err := os.Stat(p); err == nil && !fi.IsDir() {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("no exiftool executable (%v) found in directory '%v'", names, dir)
+}
@@ -0,0 +1,50 @@
+package exiftool
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryInDir(t *testing.T) {
+	dir := t.TempDir()
+	kBin := filepath.Join(dir, windowsDistributionBinary)
+	require.Nil(t, ioutil.WriteFile(kBin, nil, 0755))
+	require.Nil(t, os.Mkdir(filepath.Join(dir, "exiftool_files"), 0755))
+
+	var tcs = []struct {
+		tcID    string
+		inNames []string
+		expPath string
+		expErr  bool
+	}{
+		{"distribution", []string{"exiftool.exe", windowsDistributionBinary}, kBin, false},
+		{"notFound", []string{"exiftool.exe"}, "", true},
+		{"directory", []string{"exiftool_files"}, "", true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			p, err := binaryInDir(dir, tc.inNames)
+			assert.Equal(t, tc.expErr, err != nil)
+			assert.Equal(t, tc.expPath, p)
+		})
+	}
+}
+
+func TestSetExiftoolBinaryPathDirectory(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, exiftoolBinary)
+	require.Nil(t, ioutil.WriteFile(bin, nil, 0755))
+
+	e := Exiftool{}
+	require.Nil(t, SetExiftoolBinaryPath(dir)(&e))
+	assert.Equal(t, bin, e.exiftoolBinPath)
+
+	assert.NotNil(t, SetExiftoolBinaryPath(t.TempDir())(&Exiftool{}))
+}
@@ -0,0 +1,93 @@
+package exiftool
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CameraInfo describes the camera and lens a file has been shot with, see FileMetadata.CameraInfo.
+// Focal lengths are in millimeters, 0 when unknown.
+type CameraInfo struct {
+	Make            string
+	Model           string
+	SerialNumber    string
+	LensModel       string
+	FocalLength     float64
+	FocalLength35mm float64
+}
+
+// Tags holding the camera information, by order of preference. Keys are resolved as with the
+// LenientKeys option: group qualified keys match ungrouped extractions, unqualified ones
+// (e.g. maker notes, whose group depends on the vendor) match any group.
+var (
+	cameraMakeKeys         = []string{"EXIF:Make", "QuickTime:Make", "XMP:Make"}
+	cameraModelKeys        = []string{"EXIF:Model", "QuickTime:Model", "XMP:Model"}
+	cameraSerialNumberKeys = []string{"EXIF:SerialNumber", "SerialNumber", "InternalSerialNumber", "XMP:SerialNumber"}
+	lensModelKeys          = []string{"Composite:LensID", "EXIF:LensModel", "LensModel", "LensType", "XMP:Lens", "XMP:LensModel"}
+	focalLengthKeys        = []string{"EXIF:FocalLength", "XMP:FocalLength"}
+)
+
+// focalLength35eflRegexp extracts the equivalent focal length from Composite:FocalLength35efl
+// (e.g. "4.3 mm (35 mm equivalent: 27.0 mm)")
+var focalLength35eflRegexp = regexp.MustCompile(`35 mm equivalent: ([0-9]+(?:\.[0-9]+)?)`)
+
+// CameraInfo returns the camera and lens information, resolved across the EXIF, maker notes,
+// Composite, QuickTime and XMP tags where vendors store them. Values are trimmed and placeholder
+// values (e.g. "Unknown (0)", "----") are ignored. The 35mm equivalent focal length is computed
+// from the scale factor when it is not stored.
+func (fm FileMetadata) CameraInfo() CameraInfo {
+	fm.lenientKeys = true
+	ci := CameraInfo{
+		Make:         fm.firstString(cameraMakeKeys...),
+		Model:        fm.firstString(cameraModelKeys...),
+		SerialNumber: fm.firstString(cameraSerialNumberKeys...),
+		LensModel:    fm.firstString(lensModelKeys...),
+		FocalLength:  fm.firstNumber(focalLengthKeys...),
+	}
+
+	ci.FocalLength35mm = fm.firstNumber("EXIF:FocalLengthIn35mmFormat")
+	if ci.FocalLength35mm == 0 {
+		if str, err := fm.GetString("Composite:FocalLength35efl"); err == nil {
+			if m := focalLength35eflRegexp.FindStringSubmatch(str); m != nil {
+				ci.FocalLength35mm, _ = strconv.ParseFloat(m[1], 64)
+			}
+		}
+	}
+	if ci.FocalLength35mm == 0 && ci.FocalLength != 0 {
+		ci.FocalLength35mm = ci.FocalLength * fm.firstNumber("Composite:ScaleFactor35efl")
+	}
+	return ci
+}
+
+// firstString returns the first meaningful value of the keys, "" if there is none
+func (fm FileMetadata) firstString(keys ...string) string {
+	for _, k := range keys {
+		str, err := fm.GetString(k)
+		if err != nil {
+			continue
+		}
+		str = strings.TrimSpace(strings.Trim(str, "\x00"))
+		if str != "" && !strings.HasPrefix(str, "Unknown") && strings.Trim(str, "-") != "" {
+			return str
+		}
+	}
+	return ""
+}
+
+// firstNumber returns the leading number of the first value of the keys that has one (e.g. 4.3
+// for "4.3 mm"), 0 if there is none
+func (fm FileMetadata) firstNumber(keys ...string) float64 {
+	for _, k := range keys {
+		str, err := fm.GetString(k)
+		if err != nil {
+			continue
+		}
+		if n := coordinateNumberRegexp.FindString(str); n != "" {
+			if f, err := strconv.ParseFloat(n, 64); err == nil && f != 0 {
+				return f
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,54 @@
+package exiftool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCameraInfo(t *testing.T) {
+	var tcs = []struct {
+		tcID     string
+		inFields map[string]interface{}
+		expInfo  CameraInfo
+	}{
+		{"empty", map[string]interface{}{}, CameraInfo{}},
+		{"exif", map[string]interface{}{
+			"Make":                    "Canon",
+			"Model":                   "Canon EOS 5D Mark IV ",
+			"SerialNumber":            "012345",
+			"LensModel":               "EF24-105mm f/4L IS USM",
+			"FocalLength":             "50.0 mm",
+			"FocalLengthIn35mmFormat": "50 mm",
+		}, CameraInfo{"Canon", "Canon EOS 5D Mark IV", "012345", "EF24-105mm f/4L IS USM", 50, 50}},
+		{"grouped", map[string]interface{}{
+			"EXIF:Make":                  "NIKON CORPORATION",
+			"EXIF:Model":                 "NIKON D750",
+			"MakerNotes:SerialNumber":    "3012345",
+			"Composite:LensID":           "AF-S Nikkor 50mm f/1.8G",
+			"EXIF:FocalLength":           float64(50),
+			"Composite:FocalLength35efl": "50.0 mm (35 mm equivalent: 50.0 mm)",
+		}, CameraInfo{"NIKON CORPORATION", "NIKON D750", "3012345", "AF-S Nikkor 50mm f/1.8G", 50, 50}},
+		{"placeholders", map[string]interface{}{
+			"Make":             "Apple",
+			"LensID":           "Unknown (0)",
+			"LensModel":        "----",
+			"LensType":         "iPhone back camera",
+			"FocalLength":      "4.2 mm",
+			"ScaleFactor35efl": "6.5",
+		}, CameraInfo{Make: "Apple", LensModel: "iPhone back camera", FocalLength: 4.2, FocalLength35mm: 4.2 * 6.5}},
+		{"video", map[string]interface{}{
+			"QuickTime:Make":  "DJI",
+			"QuickTime:Model": "FC3170",
+		}, CameraInfo{Make: "DJI", Model: "FC3170"}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			fm := EmptyFileMetadata()
+			fm.Fields = tc.inFields
+			assert.Equal(t, tc.expInfo, fm.CameraInfo())
+		})
+	}
+}
@@ -0,0 +1,325 @@
+// Command goexiftool extracts and writes metadata with go-exiftool.
+//
+// Usage :
+//   goexiftool extract [-format ndjson|csv] [-r] [-workers n] [-exiftool path] paths...
+//   goexiftool write -json tags.json [-exiftool path] files...
+//
+// extract outputs one JSON object per file (ndjson, the default) or a CSV table, with the file in
+// the SourceFile field and, if any, the error in the Error field. Folders are scanned recursively
+// with -r, using -workers exiftool instances: ndjson objects are output as soon as the files are
+// extracted, while the CSV table is output once every file is extracted (its header being the
+// union of the fields). Unreadable entries are reported in the output and the scan goes on. write imports the tags of an exiftool JSON file
+// (see exiftool's -json=), matched with the files using SourceFile.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/barasher/go-exiftool"
+)
+
+const (
+	exitOK = iota
+	exitFailure
+	exitUsage
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "usage: goexiftool extract|write [options] paths...")
+		return exitUsage
+	}
+	switch args[0] {
+	case "extract":
+		return runExtract(args[1:], stdout, stderr)
+	case "write":
+		return runWrite(args[1:], stderr)
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+		return exitUsage
+	}
+}
+
+func runExtract(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("extract", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "ndjson", "output format (ndjson or csv)")
+	recursive := fs.Bool("r", false, "scan folders recursively")
+	workers := fs.Int("workers", 1, "number of exiftool instances")
+	binary := fs.String("exiftool", "", "exiftool binary path")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *format != "ndjson" && *format != "csv" {
+		fmt.Fprintf(stderr, "unknown format %q\n", *format)
+		return exitUsage
+	}
+	if *workers < 1 || fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	failed := false
+	var fms []exiftool.FileMetadata
+	emit := ndjsonWriter(stdout)
+	if *format == "csv" {
+		emit = func(fm exiftool.FileMetadata) error {
+			fms = append(fms, fm)
+			return nil
+		}
+	}
+	err := extract(listFiles(fs.Args(), *recursive), *workers, options(*binary), func(fm exiftool.FileMetadata) error {
+		if fm.Err != nil {
+			failed = true
+		}
+		return emit(fm)
+	})
+	if err == nil && *format == "csv" {
+		err = writeCSV(stdout, fms)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitFailure
+	}
+	if failed {
+		return exitFailure
+	}
+	return exitOK
+}
+
+func runWrite(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("write", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	jsonFile := fs.String("json", "", "exiftool JSON file containing the tags to write")
+	binary := fs.String("exiftool", "", "exiftool binary path")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *jsonFile == "" || fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	e, err := exiftool.NewExiftool(options(*binary)...)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitFailure
+	}
+	defer e.Close()
+
+	if err := e.ImportJSON(*jsonFile, fs.Args()...); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+func options(binary string) []func(*exiftool.Exiftool) error {
+	if binary == "" {
+		return nil
+	}
+	return []func(*exiftool.Exiftool) error{exiftool.SetExiftoolBinaryPath(binary)}
+}
+
+// listFiles returns the given files, as well as the files of the given folders if recursive. The
+// entries that can't be read while scanning the folders are returned with their error.
+func listFiles(paths []string, recursive bool) []exiftool.FileMetadata {
+	var files []exiftool.FileMetadata
+	for _, p := range paths {
+		s, err := os.Stat(p)
+		if err != nil || !s.IsDir() || !recursive {
+			// errors are reported by the extraction
+			files = append(files, exiftool.FileMetadata{File: p})
+			continue
+		}
+		filepath.Walk(p, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				files = append(files, exiftool.FileMetadata{File: path, Err: fmt.Errorf("error while scanning: %w", err)})
+				if info != nil && info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !info.IsDir() {
+				files = append(files, exiftool.FileMetadata{File: path})
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+// extract extracts the metadata of the files with several exiftool instances and hands the
+// results to emit in the order of the files, as soon as they are available. The files having an
+// error are handed as is. Extraction stops if emit returns an error.
+func extract(files []exiftool.FileMetadata, workers int, opts []func(*exiftool.Exiftool) error, emit func(exiftool.FileMetadata) error) error {
+	var indexes []int
+	for i, f := range files {
+		if f.Err == nil {
+			indexes = append(indexes, i)
+		}
+	}
+	if workers > len(indexes) {
+		workers = len(indexes)
+	}
+	ets := make([]*exiftool.Exiftool, 0, workers)
+	defer func() {
+		for _, e := range ets {
+			e.Close()
+		}
+	}()
+	for i := 0; i < workers; i++ {
+		e, err := exiftool.NewExiftool(opts...)
+		if err != nil {
+			return err
+		}
+		ets = append(ets, e)
+	}
+
+	type result struct {
+		index int
+		fm    exiftool.FileMetadata
+	}
+	todo := make(chan int)
+	results := make(chan result)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, e := range ets {
+		wg.Add(1)
+		go func(e *exiftool.Exiftool) {
+			defer wg.Done()
+			for i := range todo {
+				results <- result{index: i, fm: e.ExtractMetadata(files[i].File)[0]}
+			}
+		}(e)
+	}
+	go func() {
+		defer close(todo)
+		for _, i := range indexes {
+			select {
+			case todo <- i:
+			case <-done:
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// results are reordered, at most one per worker waiting for the previous files
+	pending := make(map[int]exiftool.FileMetadata)
+	next := 0
+	var err error
+	flush := func() {
+		for ; next < len(files) && err == nil; next++ {
+			fm := files[next]
+			if fm.Err == nil {
+				var found bool
+				if fm, found = pending[next]; !found {
+					return
+				}
+				delete(pending, next)
+			}
+			if err = emit(fm); err != nil {
+				close(done)
+			}
+		}
+	}
+	flush()
+	for r := range results {
+		pending[r.index] = r.fm
+		flush()
+	}
+	return err
+}
+
+// record returns the flat form of a FileMetadata: its fields, SourceFile and Error
+func record(fm exiftool.FileMetadata) map[string]interface{} {
+	r := make(map[string]interface{}, len(fm.Fields)+2)
+	for k, v := range fm.Fields {
+		r[k] = v
+	}
+	r["SourceFile"] = fm.File
+	if fm.Err != nil {
+		r["Error"] = fm.Err.Error()
+	}
+	return r
+}
+
+// ndjsonWriter returns a function writing a FileMetadata as a JSON object on a line
+func ndjsonWriter(w io.Writer) func(exiftool.FileMetadata) error {
+	enc := json.NewEncoder(w)
+	return func(fm exiftool.FileMetadata) error {
+		if err := enc.Encode(record(fm)); err != nil {
+			return fmt.Errorf("error while writing %v: %w", fm.File, err)
+		}
+		return nil
+	}
+}
+
+// writeCSV writes a table whose columns are SourceFile, Error and the sorted union of the fields
+func writeCSV(w io.Writer, fms []exiftool.FileMetadata) error {
+	keys := map[string]bool{}
+	for _, fm := range fms {
+		for k := range fm.Fields {
+			keys[k] = true
+		}
+	}
+	delete(keys, "SourceFile")
+	delete(keys, "Error")
+	header := make([]string, 0, len(keys)+2)
+	for k := range keys {
+		header = append(header, k)
+	}
+	sort.Strings(header)
+	header = append([]string{"SourceFile", "Error"}, header...)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, fm := range fms {
+		r := record(fm)
+		row := make([]string, len(header))
+		for i, k := range header {
+			if v, ok := r[k]; ok {
+				row[i] = csvValue(v)
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvValue formats a field value, lists and structures being JSON encoded
+func csvValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []interface{}, map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/barasher/go-exiftool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunUsage(t *testing.T) {
+	var tcs = []struct {
+		tcID   string
+		inArgs []string
+	}{
+		{"noCommand", nil},
+		{"unknownCommand", []string{"read"}},
+		{"unknownFormat", []string{"extract", "-format", "xml", "a.jpg"}},
+		{"noFile", []string{"extract"}},
+		{"noWorker", []string{"extract", "-workers", "0", "a.jpg"}},
+		{"unknownFlag", []string{"extract", "-x", "a.jpg"}},
+		{"writeWithoutJSON", []string{"write", "a.jpg"}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, exitUsage, run(tc.inArgs, &stdout, &stderr))
+		})
+	}
+}
+
+func TestRunExtract(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	content, err := ioutil.ReadFile("../../testdata/20190404_131804.jpg")
+	require.Nil(t, err)
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "a.jpg"), content, 0644))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "sub", "b.jpg"), content, 0644))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"extract", "-r", "-workers", "2", dir}, &stdout, &stderr)
+	assert.Equal(t, exitOK, code, stderr.String())
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], filepath.Join(dir, "a.jpg"))
+	assert.Contains(t, lines[1], filepath.Join(dir, "sub", "b.jpg"))
+
+	stdout.Reset()
+	code = run([]string{"extract", "-workers", "2", filepath.Join(dir, "a.jpg"), filepath.Join(dir, "missing.jpg"), filepath.Join(dir, "sub", "b.jpg")}, &stdout, &stderr)
+	assert.Equal(t, exitFailure, code)
+	lines = strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[1], `"Error"`)
+	assert.Contains(t, lines[2], filepath.Join(dir, "sub", "b.jpg"))
+}
+
+func TestListFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "a.jpg"), nil, 0644))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "sub", "b.jpg"), nil, 0644))
+
+	files := listFiles([]string{dir, "missing.jpg"}, true)
+	assert.Equal(t, []exiftool.FileMetadata{{File: filepath.Join(dir, "a.jpg")}, {File: filepath.Join(dir, "sub", "b.jpg")}, {File: "missing.jpg"}}, files)
+
+	files = listFiles([]string{dir}, false)
+	assert.Equal(t, []exiftool.FileMetadata{{File: dir}}, files)
+}
+
+func TestListFilesUnreadable(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced")
+	}
+	dir := t.TempDir()
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, "locked"), 0755))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "locked", "a.jpg"), nil, 0644))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "z.jpg"), nil, 0644))
+	require.Nil(t, os.Chmod(filepath.Join(dir, "locked"), 0))
+	defer os.Chmod(filepath.Join(dir, "locked"), 0755)
+
+	files := listFiles([]string{dir}, true)
+	require.Len(t, files, 2)
+	assert.Equal(t, filepath.Join(dir, "locked"), files[0].File)
+	assert.NotNil(t, files[0].Err)
+	assert.Equal(t, exiftool.FileMetadata{File: filepath.Join(dir, "z.jpg")}, files[1])
+}
+
+func TestExtractListingErrors(t *testing.T) {
+	listErr := errors.New("listing error")
+	files := []exiftool.FileMetadata{{File: "a", Err: listErr}, {File: "b", Err: listErr}}
+
+	var got []string
+	err := extract(files, 2, nil, func(fm exiftool.FileMetadata) error {
+		got = append(got, fm.File)
+		assert.Equal(t, listErr, fm.Err)
+		return nil
+	})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, got)
+
+	emitErr := errors.New("emit error")
+	count := 0
+	err = extract(files, 2, nil, func(fm exiftool.FileMetadata) error {
+		count++
+		return emitErr
+	})
+	assert.Equal(t, emitErr, err)
+	assert.Equal(t, 1, count)
+}
+
+func testResults() []exiftool.FileMetadata {
+	a := exiftool.NewFileMetadata("a.jpg").WithString("Artist", "John").WithStrings("Keywords", "k1", "k2")
+	b := exiftool.NewFileMetadata("b.jpg")
+	b.Err = errors.New("error")
+	b.Fields["ISO"] = float64(100)
+	return []exiftool.FileMetadata{a, b}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	emit := ndjsonWriter(&buf)
+	for _, fm := range testResults() {
+		require.Nil(t, emit(fm))
+	}
+	exp := `{"Artist":"John","Keywords":["k1","k2"],"SourceFile":"a.jpg"}` + "\n" +
+		`{"Error":"error","ISO":100,"SourceFile":"b.jpg"}` + "\n"
+	assert.Equal(t, exp, buf.String())
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.Nil(t, writeCSV(&buf, testResults()))
+	exp := "SourceFile,Error,Artist,ISO,Keywords\n" +
+		"a.jpg,,John,,\"[\"\"k1\"\",\"\"k2\"\"]\"\n" +
+		"b.jpg,error,,100,\n"
+	assert.Equal(t, exp, buf.String())
+}
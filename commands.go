@@ -0,0 +1,685 @@
+package exiftool
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var renameLineRegexp = regexp.MustCompile(`^'(.*)' --> '(.*)'$`)
+
+// ErrFileChanged is returned by Modify when the file has been modified between the extraction of
+// its metadata and the writing of the changes
+var ErrFileChanged = errors.New("file changed since its metadata was extracted")
+
+// Modify extracts the metadata of a file, hands it to fn and writes back the fields that fn has
+// modified (set, changed or cleared). Removed fields (see FileMetadata.Remove) are not deleted
+// from the file. Nothing is written if fn returns an error or doesn't modify
+// anything.
+// The instance is locked from the extraction to the writing, so that no other call can modify the
+// file meanwhile: fn must not call the methods of the instance. If the file has been modified by
+// another process (its size or modification date, FileModifyDate, changed), ErrFileChanged is
+// returned and nothing is written.
+// Sample :
+//   err := e.Modify("photo.jpg", func(fm *FileMetadata) error {
+//     fm.SetString("Artist", "me")
+//     return nil
+//   })
+func (e *Exiftool) Modify(file string, fn func(fm *FileMetadata) error) error {
+	e.acquire()
+	defer e.lock.Unlock()
+
+	path, err := normalizePath(file)
+	if err != nil {
+		return err
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotExist
+		}
+		return err
+	}
+
+	fms := e.extractFiles(context.Background(), extractConfig{}, []string{file})
+	if fms[0].Err != nil {
+		return fms[0].Err
+	}
+	fm := fms[0]
+	before := copyFields(fm.Fields)
+
+	if err := fn(&fm); err != nil {
+		return err
+	}
+
+	md := NewFileMetadata(file)
+	for k, v := range changedFields(before, fm.Fields) {
+		md.set(k, v)
+	}
+	if len(md.Fields) == 0 {
+		return nil
+	}
+
+	current, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !current.ModTime().Equal(stat.ModTime()) || current.Size() != stat.Size() {
+		return ErrFileChanged
+	}
+
+	mds := []FileMetadata{md}
+	e.writeFiles(context.Background(), mds)
+	return mds[0].Err
+}
+
+// changedFields returns the fields that differ between before and after. Fields that have been
+// removed from after (see FileMetadata.Remove) are left untouched, Clear deletes them.
+func changedFields(before, after map[string]interface{}) map[string]interface{} {
+	changed := make(map[string]interface{})
+	for k, v := range after {
+		if old, found := before[k]; !found || !reflect.DeepEqual(old, v) {
+			changed[k] = v
+		}
+	}
+	return changed
+}
+
+// ShiftDates shifts date/time tags of a file (or of all the files of a folder) by the given
+// duration, which is typically used to fix photos taken by a camera whose clock was off.
+// When no tag is specified, AllDates (DateTimeOriginal, CreateDate and ModifyDate) are shifted.
+func (e *Exiftool) ShiftDates(file string, delta time.Duration, tags ...string) error {
+	e.acquire()
+	defer e.lock.Unlock()
+
+	if err := checkExist(file); err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		tags = []string{"AllDates"}
+	}
+	for _, tag := range tags {
+		if strings.EqualFold(tag, "AllDates") {
+			if err := e.checkWriteAllowed(allDatesTags...); err != nil {
+				return err
+			}
+		} else if err := e.checkWriteAllowed(tag); err != nil {
+			return err
+		}
+	}
+	op := "+="
+	if delta < 0 {
+		op = "-="
+		delta = -delta
+	}
+
+	args := e.overwriteArgs()
+	for _, tag := range tags {
+		args = append(args, "-"+tag+op+formatShift(delta))
+	}
+	return e.runWriteCommand(args, []string{file})
+}
+
+// ImportJSON writes the tags of a JSON document (in the format produced by exiftool -j, where
+// the SourceFile of each object identifies the file it applies to) to the given files in a single
+// exiftool command
+func (e *Exiftool) ImportJSON(jsonFile string, files ...string) error {
+	return e.importFile("-json=", jsonFile, files)
+}
+
+// ImportCSV writes the tags of a CSV document (with a SourceFile column identifying the file
+// each row applies to, and a column per tag) to the given files in a single exiftool command
+func (e *Exiftool) ImportCSV(csvFile string, files ...string) error {
+	return e.importFile("-csv=", csvFile, files)
+}
+
+func (e *Exiftool) importFile(op string, importFile string, files []string) error {
+	e.acquire()
+	defer e.lock.Unlock()
+
+	if err := checkExist(append([]string{importFile}, files...)...); err != nil {
+		return err
+	}
+	if len(e.allowedWriteTags) > 0 || len(e.deniedWriteTags) > 0 {
+		tags, err := importedTags(op, importFile)
+		if err != nil {
+			return err
+		}
+		if err := e.checkWriteAllowed(tags...); err != nil {
+			return err
+		}
+	}
+
+	args := append(e.overwriteArgs(), op+importFile)
+	return e.runWriteCommand(args, files)
+}
+
+// importedTags returns the tags of a JSON (-json=) or CSV (-csv=) import file
+func importedTags(op string, importFile string) ([]string, error) {
+	f, err := os.Open(importFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var tags []string
+	if op == "-csv=" {
+		header, err := csv.NewReader(f).Read()
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("error while reading CSV import file: %w", err)
+		}
+		tags = header
+	} else {
+		var docs []map[string]json.RawMessage
+		if err := json.NewDecoder(f).Decode(&docs); err != nil {
+			return nil, fmt.Errorf("error while reading JSON import file: %w", err)
+		}
+		for _, doc := range docs {
+			for k := range doc {
+				tags = append(tags, k)
+			}
+		}
+	}
+
+	res := tags[:0]
+	for _, t := range tags {
+		if !strings.EqualFold(t, "SourceFile") {
+			res = append(res, t)
+		}
+	}
+	return res, nil
+}
+
+// WriteMetadataJSON writes the fields of all the provided metadata in a single exiftool command,
+// through exiftool's JSON import, which is much faster than WriteMetadata for many files.
+// Deleting fields (nil values), list operations (see AddToList) and values read from files (see
+// SetBinaryFromFile) are not supported by the JSON
+// import: WriteMetadata has to be used for them.
+func (e *Exiftool) WriteMetadataJSON(fileMetadata []FileMetadata) error {
+	e.acquire()
+	defer e.lock.Unlock()
+
+	docs := make([]map[string]interface{}, len(fileMetadata))
+	files := make([]string, len(fileMetadata))
+	for i, md := range fileMetadata {
+		if err := checkExist(md.File); err != nil {
+			return err
+		}
+		doc, err := e.jsonImportDoc(md)
+		if err != nil {
+			return fmt.Errorf("error while converting metadata of %v: %w", md.File, err)
+		}
+		docs[i] = doc
+		files[i] = md.File
+	}
+
+	tmp, err := ioutil.TempFile("", "go-exiftool-*.json")
+	if err != nil {
+		return fmt.Errorf("error while creating JSON import file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	err = json.NewEncoder(tmp).Encode(docs)
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return fmt.Errorf("error while writing JSON import file: %w", err)
+	}
+
+	args := append(e.overwriteArgs(), "-json="+tmp.Name())
+	return e.runWriteCommand(args, files)
+}
+
+// jsonImportDoc converts metadata to an object of the document imported by exiftool -json=
+func (e *Exiftool) jsonImportDoc(md FileMetadata) (map[string]interface{}, error) {
+	doc := map[string]interface{}{"SourceFile": md.File}
+	for k, v := range md.Fields {
+		if !e.writeAllowed(k) {
+			return nil, fmt.Errorf("%w: %v", ErrTagNotAllowed, k)
+		}
+		if isListOperation(k) {
+			return nil, fmt.Errorf("list operation on %v is not supported", k)
+		}
+		if isFileOperation(k) {
+			return nil, fmt.Errorf("writing %v from a file is not supported", k)
+		}
+		switch val := v.(type) {
+		case nil:
+			return nil, fmt.Errorf("deletion of %v is not supported", k)
+		case time.Time:
+			str, err := e.formatDate(val)
+			if err != nil {
+				return nil, err
+			}
+			doc[k] = str
+		default:
+			doc[k] = val
+		}
+	}
+	return doc, nil
+}
+
+// RestoreOriginals restores the files (or all the files of the folders) from the "_original"
+// backups created when metadata is written with BackupOriginal, and deletes the backups
+func (e *Exiftool) RestoreOriginals(paths ...string) error {
+	return e.manageOriginals("-restore_original", paths)
+}
+
+// DeleteOriginals deletes the "_original" backups of the files (or of all the files of the
+// folders) created when metadata is written with BackupOriginal
+func (e *Exiftool) DeleteOriginals(paths ...string) error {
+	return e.manageOriginals("-delete_original!", paths)
+}
+
+func (e *Exiftool) manageOriginals(op string, paths []string) error {
+	e.acquire()
+	defer e.lock.Unlock()
+
+	if err := checkExist(paths...); err != nil {
+		return err
+	}
+	if e.dryRun != nil {
+		for _, p := range paths {
+			if _, err := fmt.Fprintf(e.dryRun, "%v %q\n", p, []string{op}); err != nil {
+				return fmt.Errorf("error while printing dry run: %w", err)
+			}
+		}
+		return nil
+	}
+
+	resp, err := e.sendCommand(OpOriginals, append([]string{op}, paths...))
+	if err != nil {
+		return err
+	}
+	if err := handleOriginalsResponse(string(resp)); err != nil {
+		return fmt.Errorf("error while managing original files: %w", err)
+	}
+	return nil
+}
+
+// handleOriginalsResponse checks the summary printed by -restore_original and -delete_original,
+// which only reports errors through the "files weren't ..." lines
+func handleOriginalsResponse(resp string) error {
+	s := bufio.NewScanner(strings.NewReader(resp))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if strings.HasPrefix(line, "Error") || strings.Contains(line, "weren't") {
+			return errors.New(strings.TrimSpace(resp))
+		}
+	}
+	return nil
+}
+
+// SetThumbnail embeds (or replaces) the EXIF thumbnail of a file with the provided JPEG image,
+// exiftool creating the required IFD1 structures when the file has no thumbnail yet
+func (e *Exiftool) SetThumbnail(file string, jpeg []byte) error {
+	if !bytes.HasPrefix(jpeg, []byte{0xFF, 0xD8}) {
+		return errors.New("thumbnail is not a JPEG image")
+	}
+	return e.writeBinaryTag(file, "ThumbnailImage", jpeg)
+}
+
+// SetICCProfile embeds (or replaces) the ICC color profile of a file
+func (e *Exiftool) SetICCProfile(file string, profile []byte) error {
+	// the profile header is 128 bytes long and contains the "acsp" signature at offset 36
+	if len(profile) < 128 || string(profile[36:40]) != "acsp" {
+		return errors.New("invalid ICC profile")
+	}
+	return e.writeBinaryTag(file, "ICC_Profile", profile)
+}
+
+// writeBinaryTag writes a binary tag of a file through a temporary file
+func (e *Exiftool) writeBinaryTag(file string, tag string, data []byte) error {
+	e.acquire()
+	defer e.lock.Unlock()
+
+	if err := checkExist(file); err != nil {
+		return err
+	}
+	if err := e.checkWriteAllowed(tag); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile("", "go-exiftool-*.bin")
+	if err != nil {
+		return fmt.Errorf("error while creating temporary file for %v: %w", tag, err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return fmt.Errorf("error while writing temporary file for %v: %w", tag, err)
+	}
+
+	args := append(e.overwriteArgs(), "-"+tag+"<="+tmp.Name())
+	return e.runWriteCommand(args, []string{file})
+}
+
+// GeotagOptions tunes how Geotag matches photos against the track log
+type GeotagOptions struct {
+	// TimeOffset is added to the photos' timestamps before matching them with the track, typically
+	// to compensate a camera clock drift or a timezone difference (exiftool's -geosync)
+	TimeOffset time.Duration
+	// MaxInterpolation is the maximum gap between two track points for a position to be interpolated
+	// (exiftool's GeoMaxIntSecs API option, 1800 seconds if zero)
+	MaxInterpolation time.Duration
+	// MaxExtrapolation is the maximum time before the first or after the last track point for which
+	// a position is still extrapolated (exiftool's GeoMaxExtSecs API option, 1800 seconds if zero)
+	MaxExtrapolation time.Duration
+}
+
+// Geotag writes GPS tags to the photos from a track log (GPX, NMEA, KML, ...), matching the
+// photos' DateTimeOriginal with the track timestamps.
+func (e *Exiftool) Geotag(trackFile string, photos ...string) error {
+	return e.GeotagWithOptions(GeotagOptions{}, trackFile, photos...)
+}
+
+// GeotagWithOptions is the same as Geotag with tuned options
+func (e *Exiftool) GeotagWithOptions(opts GeotagOptions, trackFile string, photos ...string) error {
+	e.acquire()
+	defer e.lock.Unlock()
+
+	if err := checkExist(append([]string{trackFile}, photos...)...); err != nil {
+		return err
+	}
+	if err := e.checkWriteAllowed(geotagTags...); err != nil {
+		return err
+	}
+
+	args := append(e.overwriteArgs(), "-geotag", trackFile)
+	if opts.TimeOffset != 0 {
+		args = append(args, "-geosync="+formatSeconds(opts.TimeOffset, true))
+	}
+	if opts.MaxInterpolation != 0 {
+		args = append(args, "-api", "GeoMaxIntSecs="+formatSeconds(opts.MaxInterpolation, false))
+	}
+	if opts.MaxExtrapolation != 0 {
+		args = append(args, "-api", "GeoMaxExtSecs="+formatSeconds(opts.MaxExtrapolation, false))
+	}
+	return e.runWriteCommand(args, photos)
+}
+
+// formatSeconds formats a duration as a number of seconds, optionally signed
+func formatSeconds(d time.Duration, signed bool) string {
+	s := strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+	if signed && d >= 0 {
+		s = "+" + s
+	}
+	return s
+}
+
+// RenameByTemplate renames files according to their DateTimeOriginal tag, formatted with the
+// provided template (exiftool's -d syntax, where %-c adds a copy number when the target already
+// exists and %e is the original extension; these have to be escaped as %%-c and %%e since the
+// template is also used as a date format). It returns the mapping between the original and the
+// new paths of the renamed files.
+// Sample :
+//   renamed, err := e.RenameByTemplate(files, "%Y%m%d_%H%M%S%%-c.%%e")
+func (e *Exiftool) RenameByTemplate(files []string, template string) (map[string]string, error) {
+	e.acquire()
+	defer e.lock.Unlock()
+
+	if err := checkExist(files...); err != nil {
+		return nil, err
+	}
+	if err := e.checkWriteAllowed("FileName"); err != nil {
+		return nil, err
+	}
+
+	args := []string{"-v", "-d", template, "-FileName<DateTimeOriginal"}
+	if e.dryRun != nil {
+		// TestName reports the new names without renaming the files
+		args = []string{"-d", template, "-TestName<DateTimeOriginal"}
+		for _, f := range files {
+			if _, err := fmt.Fprintf(e.dryRun, "%v %q\n", f, args); err != nil {
+				return nil, fmt.Errorf("error while printing dry run: %w", err)
+			}
+		}
+	}
+	resp, err := e.sendCommand(OpRename, append(args, files...))
+	if err != nil {
+		return nil, err
+	}
+	renamed := parseRenames(string(resp))
+	if e.dryRun != nil {
+		return renamed, nil
+	}
+	if err := handleWriteMetadataResponse(string(resp)); err != nil {
+		return renamed, fmt.Errorf("Error renaming files: %w", err)
+	}
+	return renamed, nil
+}
+
+// ErrRenameCollision is a sentinel error that is returned when applying a rename plan containing
+// collisions
+var ErrRenameCollision = errors.New("rename collision")
+
+// RenamePlan is a set of renames proposed by PlanRename, to be reviewed before being applied
+// with ApplyRenamePlan
+type RenamePlan struct {
+	// Renames maps the current paths to the new ones
+	Renames map[string]string
+	// Collisions maps the new paths that are claimed by several files, or that already exist, to
+	// the files that would be renamed to them
+	Collisions map[string][]string
+}
+
+// PlanRename computes, without renaming anything, how files would be renamed according to their
+// DateTimeOriginal tag and the template (see RenameByTemplate), and detects the collisions.
+func (e *Exiftool) PlanRename(files []string, template string) (RenamePlan, error) {
+	e.acquire()
+	defer e.lock.Unlock()
+
+	if err := checkExist(files...); err != nil {
+		return RenamePlan{}, err
+	}
+
+	args := []string{"-d", template, "-TestName<DateTimeOriginal"}
+	resp, err := e.sendCommand(OpRename, append(args, files...))
+	if err != nil {
+		return RenamePlan{}, err
+	}
+
+	plan := RenamePlan{Renames: parseRenames(string(resp)), Collisions: make(map[string][]string)}
+	sources := make(map[string][]string)
+	for from, to := range plan.Renames {
+		if from == to {
+			delete(plan.Renames, from)
+			continue
+		}
+		sources[to] = append(sources[to], from)
+	}
+	for to, froms := range sources {
+		sort.Strings(froms)
+		if _, err := os.Stat(to); len(froms) > 1 || err == nil {
+			plan.Collisions[to] = froms
+		}
+	}
+	return plan, nil
+}
+
+// ApplyRenamePlan renames the files of a plan computed by PlanRename, creating the target
+// directories when required. ErrRenameCollision is returned, before renaming anything, if the
+// plan contains collisions. Files renamed before a failure are not restored.
+func ApplyRenamePlan(plan RenamePlan) error {
+	if len(plan.Collisions) > 0 {
+		return fmt.Errorf("%w: %v files", ErrRenameCollision, len(plan.Collisions))
+	}
+
+	froms := make([]string, 0, len(plan.Renames))
+	for from := range plan.Renames {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+
+	for _, from := range froms {
+		to := plan.Renames[from]
+		if _, err := os.Stat(to); err == nil {
+			return fmt.Errorf("%w: %v already exists", ErrRenameCollision, to)
+		}
+		if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+			return fmt.Errorf("error while creating directory of %v: %w", to, err)
+		}
+		if err := os.Rename(from, to); err != nil {
+			return fmt.Errorf("error while renaming %v: %w", from, err)
+		}
+	}
+	return nil
+}
+
+// parseRenames extracts the "'old' --> 'new'" lines of exiftool's output (verbose output for
+// FileName, standard output for TestName)
+func parseRenames(resp string) map[string]string {
+	renamed := make(map[string]string)
+	s := bufio.NewScanner(strings.NewReader(resp))
+	for s.Scan() {
+		if m := renameLineRegexp.FindStringSubmatch(strings.TrimSpace(s.Text())); m != nil {
+			renamed[m[1]] = m[2]
+		}
+	}
+	return renamed
+}
+
+// StripGroups deletes all the tags of the given groups (e.g. "GPS", "XMP", "MakerNotes") from a
+// file, keeping the rest of the metadata, which is typically used to remove private data
+func (e *Exiftool) StripGroups(file string, groups ...string) error {
+	if len(groups) == 0 {
+		return errors.New("no group to strip")
+	}
+	md := EmptyFileMetadata()
+	md.File = file
+	for _, g := range groups {
+		md.ClearGroup(g)
+	}
+	mds := []FileMetadata{md}
+	e.WriteMetadata(mds, WriteClearFieldsBeforeWriting(false))
+	return mds[0].Err
+}
+
+// syncDateTags are the date tags updated by SyncDates, QuickTime ones being only written to videos
+var syncDateTags = []string{
+	"DateTimeOriginal", "CreateDate", "ModifyDate", "FileModifyDate",
+	"TrackCreateDate", "TrackModifyDate", "MediaCreateDate", "MediaModifyDate",
+}
+
+// SyncDates copies the value of the source date tag (e.g. DateTimeOriginal) to all the other
+// date tags of the file: DateTimeOriginal, CreateDate, ModifyDate, FileModifyDate and, for
+// videos, the QuickTime track and media dates.
+func (e *Exiftool) SyncDates(file string, source string) error {
+	e.acquire()
+	defer e.lock.Unlock()
+
+	if err := checkExist(file); err != nil {
+		return err
+	}
+
+	_, sourceTag := SplitTagKey(source)
+	args := e.overwriteArgs()
+	for _, tag := range syncDateTags {
+		if !strings.EqualFold(tag, sourceTag) {
+			if err := e.checkWriteAllowed(tag); err != nil {
+				return err
+			}
+			args = append(args, "-"+tag+"<"+source)
+		}
+	}
+	return e.runWriteCommand(args, []string{file})
+}
+
+// formatShift formats a positive duration as an exiftool date/time shift ("D H:M:S" when more
+// than a day, "H:M:S" otherwise)
+func formatShift(d time.Duration) string {
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	d -= minutes * time.Minute
+	seconds := strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+
+	shift := fmt.Sprintf("%d:%02d:%v", hours, minutes, seconds)
+	if days > 0 {
+		shift = fmt.Sprintf("0:0:%d %v", days, shift)
+	}
+	return shift
+}
+
+// checkExist returns ErrNotExist (wrapped with the file name) if any of the files does not exist
+func checkExist(files ...string) error {
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("%w: %v", ErrNotExist, f)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// allDatesTags are the tags written through the AllDates shortcut
+var allDatesTags = []string{"DateTimeOriginal", "CreateDate", "ModifyDate"}
+
+// geotagTags are the tags written by Geotag
+var geotagTags = []string{
+	"GPS:GPSLatitude", "GPS:GPSLatitudeRef", "GPS:GPSLongitude", "GPS:GPSLongitudeRef",
+	"GPS:GPSAltitude", "GPS:GPSAltitudeRef", "GPS:GPSDateStamp", "GPS:GPSTimeStamp",
+}
+
+// checkWriteAllowed returns ErrTagNotAllowed if any of the tags is rejected by the AllowWriteTags
+// and DenyWriteTags policies
+func (e *Exiftool) checkWriteAllowed(tags ...string) error {
+	for _, t := range tags {
+		if !e.writeAllowed(t) {
+			return fmt.Errorf("%w: %v", ErrTagNotAllowed, t)
+		}
+	}
+	return nil
+}
+
+// overwriteArgs returns the arguments that have to be provided to every command modifying files
+func (e *Exiftool) overwriteArgs() []string {
+	if e.backupOriginal {
+		return nil
+	}
+	return []string{"-overwrite_original"}
+}
+
+// runWriteCommand sends a command modifying files and checks exiftool's response. With DryRun,
+// the command is printed and validated for each file without modifying it.
+func (e *Exiftool) runWriteCommand(args []string, files []string) error {
+	if e.dryRun != nil {
+		for _, f := range files {
+			if err := e.dryRunWrite(f, args); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	resp, err := e.sendCommand(OpWrite, append(args, files...))
+	if err != nil {
+		return err
+	}
+	if err := handleWriteMetadataResponse(string(resp)); err != nil {
+		return fmt.Errorf("Error writing metadata: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,752 @@
+package exiftool
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShiftDates(t *testing.T) {
+	t.Parallel()
+
+	var tcs = []struct {
+		tcID      string
+		inDelta   time.Duration
+		inTags    []string
+		expOrig   string
+		expCreate string
+	}{
+		{"allDates", time.Hour, nil, "2019:04:04 14:18:03", "2019:04:04 14:18:03"},
+		{"negative", -25 * time.Hour, nil, "2019:04:03 12:18:03", "2019:04:03 12:18:03"},
+		{"singleTag", 90 * time.Second, []string{"DateTimeOriginal"}, "2019:04:04 13:19:33", "2019:04:04 13:18:03"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+			require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+			e, err := NewExiftool()
+			require.Nil(t, err)
+			defer e.Close()
+
+			require.Nil(t, e.ShiftDates(testFile, tc.inDelta, tc.inTags...))
+
+			mds := e.ExtractMetadata(testFile)
+			require.Len(t, mds, 1)
+			require.Nil(t, mds[0].Err)
+			got, err := mds[0].GetString("DateTimeOriginal")
+			require.Nil(t, err)
+			assert.Equal(t, tc.expOrig, got)
+			got, err = mds[0].GetString("CreateDate")
+			require.Nil(t, err)
+			assert.Equal(t, tc.expCreate, got)
+		})
+	}
+}
+
+func TestShiftDatesNonExisting(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	err = e.ShiftDates("./testdata/nonExisting.jpg", time.Hour)
+	assert.True(t, errors.Is(err, ErrNotExist))
+}
+
+func TestFormatShift(t *testing.T) {
+	var tcs = []struct {
+		in  time.Duration
+		exp string
+	}{
+		{time.Hour, "1:00:0"},
+		{90 * time.Minute, "1:30:0"},
+		{1500 * time.Millisecond, "0:00:1.5"},
+		{26*time.Hour + 3*time.Minute + 4*time.Second, "0:0:1 2:03:4"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.in.String(), func(t *testing.T) {
+			assert.Equal(t, tc.exp, formatShift(tc.in))
+		})
+	}
+}
+
+func TestRenameByTemplate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", src))
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	renamed, err := e.RenameByTemplate([]string{src}, "%Y%m%d_%H%M%S%%-c.%%e")
+	require.Nil(t, err)
+	exp := filepath.Join(dir, "20190404_131803.jpg")
+	assert.Equal(t, map[string]string{src: exp}, renamed)
+	_, err = os.Stat(exp)
+	assert.Nil(t, err)
+}
+
+func TestRenameByTemplateNonExisting(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	_, err = e.RenameByTemplate([]string{"./testdata/nonExisting.jpg"}, "%Y%m%d.%%e")
+	assert.True(t, errors.Is(err, ErrNotExist))
+}
+
+func TestParseRenames(t *testing.T) {
+	resp := "======== a.jpg\n'dir/a.jpg' --> 'dir/20190404_131803.jpg'\n" +
+		"'dir/b.jpg' --> 'dir/20190404_131803-1.jpg'\n    2 image files updated\n"
+	exp := map[string]string{
+		"dir/a.jpg": "dir/20190404_131803.jpg",
+		"dir/b.jpg": "dir/20190404_131803-1.jpg",
+	}
+	assert.Equal(t, exp, parseRenames(resp))
+	assert.Empty(t, parseRenames("    0 image files updated\n"))
+}
+
+func TestGeotag(t *testing.T) {
+	t.Parallel()
+
+	var tcs = []struct {
+		tcID    string
+		inOpts  GeotagOptions
+		expTags bool
+	}{
+		// track points are 2 days apart so that the photo is matched whatever the local timezone
+		{"interpolated", GeotagOptions{MaxInterpolation: 48 * time.Hour}, true},
+		{"notInterpolated", GeotagOptions{}, false},
+		{"outOfTrack", GeotagOptions{TimeOffset: 72 * time.Hour, MaxExtrapolation: time.Minute}, false},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+			require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+			e, err := NewExiftool()
+			require.Nil(t, err)
+			defer e.Close()
+
+			err = e.GeotagWithOptions(tc.inOpts, "testdata/track.gpx", testFile)
+			if !tc.expTags {
+				assert.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+
+			mds := e.ExtractMetadata(testFile)
+			require.Len(t, mds, 1)
+			_, err = mds[0].GetString("GPSLatitude")
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestGeotagNonExistingTrack(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	err = e.Geotag("./testdata/nonExisting.gpx", "testdata/20190404_131804.jpg")
+	assert.True(t, errors.Is(err, ErrNotExist))
+}
+
+func TestFormatSeconds(t *testing.T) {
+	assert.Equal(t, "+3600", formatSeconds(time.Hour, true))
+	assert.Equal(t, "-1.5", formatSeconds(-1500*time.Millisecond, true))
+	assert.Equal(t, "60", formatSeconds(time.Minute, false))
+}
+
+func TestImportJSON(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+	jsonFile := filepath.Join(dir, "import.json")
+	doc := `[{"SourceFile": "` + filepath.ToSlash(testFile) + `", "Artist": "imported"}]`
+	require.Nil(t, ioutil.WriteFile(jsonFile, []byte(doc), 0644))
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	require.Nil(t, e.ImportJSON(jsonFile, testFile))
+
+	mds := e.ExtractMetadata(testFile)
+	require.Len(t, mds, 1)
+	got, err := mds[0].GetString("Artist")
+	require.Nil(t, err)
+	assert.Equal(t, "imported", got)
+}
+
+func TestImportCSV(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	var files []string
+	csv := "SourceFile,Artist,Keywords\n"
+	for i := 0; i < 2; i++ {
+		f := filepath.Join(dir, fmt.Sprintf("%d.jpg", i))
+		require.Nil(t, copyFile("testdata/20190404_131804.jpg", f))
+		files = append(files, f)
+		csv += fmt.Sprintf("%v,artist%d,\"k1, k2\"\n", filepath.ToSlash(f), i)
+	}
+	csvFile := filepath.Join(dir, "import.csv")
+	require.Nil(t, ioutil.WriteFile(csvFile, []byte(csv), 0644))
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	require.Nil(t, e.ImportCSV(csvFile, files...))
+
+	for i, f := range files {
+		mds := e.ExtractMetadata(f)
+		require.Len(t, mds, 1)
+		got, err := mds[0].GetString("Artist")
+		require.Nil(t, err)
+		assert.Equal(t, fmt.Sprintf("artist%d", i), got)
+		keywords, err := mds[0].GetStrings("Keywords")
+		require.Nil(t, err)
+		assert.Equal(t, []string{"k1", "k2"}, keywords)
+	}
+}
+
+func TestImportCSVNonExisting(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	err = e.ImportCSV("./testdata/nonExisting.csv", "testdata/20190404_131804.jpg")
+	assert.True(t, errors.Is(err, ErrNotExist))
+}
+
+func TestWriteMetadataJSON(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	var mds []FileMetadata
+	for i, artist := range []string{"a1", "a2"} {
+		f := filepath.Join(dir, fmt.Sprintf("%d.jpg", i))
+		require.Nil(t, copyFile("testdata/20190404_131804.jpg", f))
+		md := EmptyFileMetadata()
+		md.File = f
+		md.SetString("Artist", artist)
+		md.SetStrings("Keywords", []string{"k1", "k2"})
+		mds = append(mds, md)
+	}
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	require.Nil(t, e.WriteMetadataJSON(mds))
+
+	for _, md := range mds {
+		got := e.ExtractMetadata(md.File)
+		require.Len(t, got, 1)
+		artist, err := got[0].GetString("Artist")
+		require.Nil(t, err)
+		assert.Equal(t, md.Fields["Artist"], artist)
+		keywords, err := got[0].GetStrings("Keywords")
+		require.Nil(t, err)
+		assert.Equal(t, []string{"k1", "k2"}, keywords)
+	}
+}
+
+func TestJSONImportDoc(t *testing.T) {
+	e := &Exiftool{}
+	date := time.Date(2019, 4, 4, 13, 18, 3, 0, time.UTC)
+
+	md := EmptyFileMetadata()
+	md.File = "a.jpg"
+	md.SetString("Artist", "a")
+	md.SetDate("DateTimeOriginal", date)
+	doc, err := e.jsonImportDoc(md)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"SourceFile":       "a.jpg",
+		"Artist":           "a",
+		"DateTimeOriginal": "2019:04:04 13:18:03",
+	}, doc)
+
+	md = EmptyFileMetadata()
+	md.Clear("Artist")
+	_, err = e.jsonImportDoc(md)
+	assert.NotNil(t, err)
+
+	md = EmptyFileMetadata()
+	md.AddToList("Keywords", "k")
+	_, err = e.jsonImportDoc(md)
+	assert.NotNil(t, err)
+}
+
+func TestRestoreOriginals(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+	e, err := NewExiftool(BackupOriginal())
+	require.Nil(t, err)
+	defer e.Close()
+
+	md := EmptyFileMetadata()
+	md.File = testFile
+	md.SetString("Artist", "modified")
+	mds := []FileMetadata{md}
+	e.WriteMetadata(mds)
+	require.Nil(t, mds[0].Err)
+	_, err = os.Stat(testFile + "_original")
+	require.Nil(t, err)
+
+	require.Nil(t, e.RestoreOriginals(testFile))
+
+	_, err = os.Stat(testFile + "_original")
+	assert.True(t, os.IsNotExist(err))
+	got := e.ExtractMetadata(testFile)
+	require.Len(t, got, 1)
+	_, err = got[0].GetString("Artist")
+	assert.Equal(t, ErrKeyNotFound, err)
+}
+
+func TestDeleteOriginals(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile+"_original"))
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	require.Nil(t, e.DeleteOriginals(dir))
+
+	_, err = os.Stat(testFile + "_original")
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(testFile)
+	assert.Nil(t, err)
+}
+
+func TestHandleOriginalsResponse(t *testing.T) {
+	var tcs = []struct {
+		tcID   string
+		inResp string
+		expErr bool
+	}{
+		{"restored", "    1 image files restored from backup\n", false},
+		{"deleted", "    1 directories scanned\n    1 original files deleted\n", false},
+		{"nothing", "    1 directories scanned\n    0 image files read\n", false},
+		{"error", "Error renaming a.jpg\n    1 files weren't restored due to errors\n", true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			assert.Equal(t, tc.expErr, handleOriginalsResponse(tc.inResp) != nil)
+		})
+	}
+}
+
+func TestSetThumbnail(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+	thumb, err := ioutil.ReadFile("testdata/thumbnail.jpg")
+	require.Nil(t, err)
+
+	e, err := NewExiftool(ExtractAllBinaryMetadata())
+	require.Nil(t, err)
+	defer e.Close()
+
+	require.Nil(t, e.SetThumbnail(testFile, thumb))
+
+	mds := e.ExtractMetadata(testFile)
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	got, err := mds[0].GetBinary("ThumbnailImage")
+	require.Nil(t, err)
+	assert.Equal(t, thumb, got)
+}
+
+func TestSetThumbnailNotJPEG(t *testing.T) {
+	e := &Exiftool{}
+	assert.NotNil(t, e.SetThumbnail("testdata/20190404_131804.jpg", []byte("\x89PNG")))
+}
+
+func TestSetICCProfile(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+	profile := iccProfile()
+
+	e, err := NewExiftool(ExtractAllBinaryMetadata())
+	require.Nil(t, err)
+	defer e.Close()
+
+	require.Nil(t, e.SetICCProfile(testFile, profile))
+
+	mds := e.ExtractMetadata(testFile)
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	got, err := mds[0].GetBinary("ICC_Profile")
+	require.Nil(t, err)
+	assert.Equal(t, profile, got)
+}
+
+func TestSetICCProfileInvalid(t *testing.T) {
+	e := &Exiftool{}
+	assert.NotNil(t, e.SetICCProfile("testdata/20190404_131804.jpg", []byte("acsp")))
+	assert.NotNil(t, e.SetICCProfile("testdata/20190404_131804.jpg", make([]byte, 132)))
+}
+
+// iccProfile builds a minimal ICC profile: a header without any tag
+func iccProfile() []byte {
+	p := make([]byte, 132)
+	binary.BigEndian.PutUint32(p, uint32(len(p)))
+	copy(p[12:], "mntr")
+	copy(p[16:], "RGB ")
+	copy(p[20:], "XYZ ")
+	copy(p[36:], "acsp")
+	return p
+}
+
+func TestModify(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	err = e.Modify(testFile, func(fm *FileMetadata) error {
+		fm.SetString("Artist", "modified")
+		return nil
+	})
+	require.Nil(t, err)
+
+	mds := e.ExtractMetadata(testFile)
+	require.Len(t, mds, 1)
+	got, err := mds[0].GetString("Artist")
+	require.Nil(t, err)
+	assert.Equal(t, "modified", got)
+}
+
+func TestModifyChangedFieldsOnly(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+	var dryRun bytes.Buffer
+	e, err := NewExiftool(WriteChangedFieldsOnly(), DryRun(&dryRun))
+	require.Nil(t, err)
+	defer e.Close()
+
+	err = e.Modify(testFile, func(fm *FileMetadata) error {
+		fm.SetString("Artist", "modified")
+		return nil
+	})
+	require.Nil(t, err)
+	assert.Equal(t, fmt.Sprintf("%v %q\n", testFile, []string{"-overwrite_original", "-Artist=modified"}), dryRun.String())
+}
+
+func TestModifyRemove(t *testing.T) {
+	t.Parallel()
+
+	var dryRun bytes.Buffer
+	e, err := NewExiftool(DryRun(&dryRun))
+	require.Nil(t, err)
+	defer e.Close()
+
+	err = e.Modify("./testdata/20190404_131804.jpg", func(fm *FileMetadata) error {
+		fm.Remove("FileName")
+		fm.Remove("ImageWidth")
+		fm.Clear("Title")
+		return nil
+	})
+	require.Nil(t, err)
+	assert.Equal(t, fmt.Sprintf("%v %q\n", "./testdata/20190404_131804.jpg", []string{"-overwrite_original", "-Title="}), dryRun.String())
+}
+
+func TestModifyFileChanged(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+	var dryRun bytes.Buffer
+	e, err := NewExiftool(DryRun(&dryRun))
+	require.Nil(t, err)
+	defer e.Close()
+
+	err = e.Modify(testFile, func(fm *FileMetadata) error {
+		fm.SetString("Artist", "modified")
+		// simulates another process modifying the file
+		old := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		return os.Chtimes(testFile, old, old)
+	})
+	assert.Equal(t, ErrFileChanged, err)
+	assert.Empty(t, dryRun.String())
+}
+
+func TestModifyCallbackError(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	expErr := errors.New("callback error")
+	err = e.Modify("testdata/20190404_131804.jpg", func(fm *FileMetadata) error {
+		fm.SetString("Artist", "modified")
+		return expErr
+	})
+	assert.Equal(t, expErr, err)
+}
+
+func TestModifyNonExisting(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	err = e.Modify("testdata/nonExisting.jpg", func(fm *FileMetadata) error { return nil })
+	assert.Equal(t, ErrNotExist, err)
+}
+
+func TestChangedFields(t *testing.T) {
+	before := map[string]interface{}{
+		"Unchanged": "a",
+		"Changed":   "a",
+		"Cleared":   "a",
+		"Deleted":   "a",
+		"List":      []interface{}{"a", "b"},
+	}
+	after := copyFields(before)
+	after["Changed"] = "b"
+	after["Cleared"] = nil
+	delete(after, "Deleted")
+	after["List"].([]interface{})[1] = "c"
+	after["Added"] = float64(1)
+
+	exp := map[string]interface{}{
+		"Changed": "b",
+		"Cleared": nil,
+		"List":    []interface{}{"a", "c"},
+		"Added":   float64(1),
+	}
+	assert.Equal(t, exp, changedFields(before, after))
+}
+
+func TestSyncDates(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	mds := []FileMetadata{EmptyFileMetadata()}
+	mds[0].File = testFile
+	mds[0].SetString("CreateDate", "2000:01:01 00:00:00")
+	mds[0].SetString("ModifyDate", "2000:01:01 00:00:00")
+	e.WriteMetadata(mds)
+	require.Nil(t, mds[0].Err)
+
+	require.Nil(t, e.SyncDates(testFile, "DateTimeOriginal"))
+
+	mds = e.ExtractMetadata(testFile)
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	for _, k := range []string{"CreateDate", "ModifyDate"} {
+		got, err := mds[0].GetString(k)
+		require.Nil(t, err)
+		assert.Equal(t, "2019:04:04 13:18:03", got)
+	}
+	got, err := mds[0].GetString("FileModifyDate")
+	require.Nil(t, err)
+	assert.True(t, strings.HasPrefix(got, "2019:04:04 13:18:03"))
+}
+
+func TestSyncDatesNonExisting(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	err = e.SyncDates("./testdata/nonExisting.jpg", "DateTimeOriginal")
+	assert.True(t, errors.Is(err, ErrNotExist))
+}
+
+func TestStripGroups(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "gps.jpg")
+	require.Nil(t, copyFile("testdata/gps.jpg", testFile))
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	require.Nil(t, e.StripGroups(testFile, "GPS"))
+
+	mds := e.ExtractMetadata(testFile)
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	_, err = mds[0].GetString("GPSLatitude")
+	assert.Equal(t, ErrKeyNotFound, err)
+	_, err = mds[0].GetString("ImageWidth")
+	assert.Nil(t, err)
+}
+
+func TestStripGroupsNoGroup(t *testing.T) {
+	assert.NotNil(t, (&Exiftool{}).StripGroups("testdata/gps.jpg"))
+}
+
+func TestPlanRename(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	var files []string
+	for _, f := range []string{"a.jpg", "b.jpg"} {
+		files = append(files, filepath.Join(dir, f))
+		require.Nil(t, copyFile("testdata/20190404_131804.jpg", filepath.Join(dir, f)))
+	}
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	// both files have the same DateTimeOriginal
+	plan, err := e.PlanRename(files, filepath.Join(dir, "out", "%Y%m%d_%H%M%S.%%e"))
+	require.Nil(t, err)
+	target := filepath.Join(dir, "out", "20190404_131803.jpg")
+	assert.Equal(t, map[string]string{files[0]: target, files[1]: target}, plan.Renames)
+	assert.Equal(t, map[string][]string{target: files}, plan.Collisions)
+	assert.True(t, errors.Is(ApplyRenamePlan(plan), ErrRenameCollision))
+
+	plan, err = e.PlanRename(files[:1], filepath.Join(dir, "out", "%Y%m%d_%H%M%S.%%e"))
+	require.Nil(t, err)
+	assert.Empty(t, plan.Collisions)
+	require.Nil(t, ApplyRenamePlan(plan))
+	_, err = os.Stat(target)
+	assert.Nil(t, err)
+}
+
+func TestApplyRenamePlan(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.jpg")
+	b := filepath.Join(dir, "b.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", a))
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", b))
+
+	var tcs = []struct {
+		tcID   string
+		inPlan RenamePlan
+		expErr error
+	}{
+		{"collision", RenamePlan{Renames: map[string]string{a: b}, Collisions: map[string][]string{b: {a}}}, ErrRenameCollision},
+		{"existingTarget", RenamePlan{Renames: map[string]string{a: b}}, ErrRenameCollision},
+		{"nominal", RenamePlan{Renames: map[string]string{a: filepath.Join(dir, "sub", "c.jpg")}}, nil},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.tcID, func(t *testing.T) {
+			err := ApplyRenamePlan(tc.inPlan)
+			assert.True(t, errors.Is(err, tc.expErr))
+		})
+	}
+	_, err := os.Stat(filepath.Join(dir, "sub", "c.jpg"))
+	assert.Nil(t, err)
+}
+
+func TestWriteTagsPolicy(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+	jsonFile := filepath.Join(dir, "import.json")
+	require.Nil(t, ioutil.WriteFile(jsonFile, []byte(`[{"SourceFile": "*", "Artist": "me", "FileName": "x.jpg"}]`), 0644))
+	csvFile := filepath.Join(dir, "import.csv")
+	require.Nil(t, ioutil.WriteFile(csvFile, []byte("SourceFile,Artist,Directory\n*,me,/tmp\n"), 0644))
+
+	e, err := NewExiftool(DenyWriteTags("FileName", "Directory", "GPS*", "ThumbnailImage", "CreateDate"))
+	require.Nil(t, err)
+	defer e.Close()
+
+	var tcs = []struct {
+		tcID string
+		inFn func() error
+	}{
+		{"importJSON", func() error { return e.ImportJSON(jsonFile, testFile) }},
+		{"importCSV", func() error { return e.ImportCSV(csvFile, testFile) }},
+		{"renameByTemplate", func() error {
+			_, err := e.RenameByTemplate([]string{testFile}, "%Y%m%d.%%e")
+			return err
+		}},
+		{"shiftAllDates", func() error { return e.ShiftDates(testFile, time.Hour) }},
+		{"shiftDates", func() error { return e.ShiftDates(testFile, time.Hour, "CreateDate") }},
+		{"syncDates", func() error { return e.SyncDates(testFile, "DateTimeOriginal") }},
+		{"setThumbnail", func() error { return e.SetThumbnail(testFile, []byte{0xff, 0xd8}) }},
+		{"geotag", func() error { return e.Geotag("testdata/track.gpx", testFile) }},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			assert.True(t, errors.Is(tc.inFn(), ErrTagNotAllowed))
+		})
+	}
+
+	allowed := filepath.Join(dir, "allowed.json")
+	require.Nil(t, ioutil.WriteFile(allowed, []byte(`[{"SourceFile": "*", "Artist": "me"}]`), 0644))
+	tags, err := importedTags("-json=", allowed)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"Artist"}, tags)
+	tags, err = importedTags("-csv=", csvFile)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"Artist", "Directory"}, tags)
+	_, err = importedTags("-json=", csvFile)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,200 @@
+package exiftool
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TreeDifference is a file whose compared tags differ between two trees, see CompareTrees. A and B
+// are the paths of the file in each tree.
+type TreeDifference struct {
+	A       string
+	B       string
+	Changes []FieldChange
+}
+
+// TreeComparison is the result of CompareTrees
+type TreeComparison struct {
+	Differences []TreeDifference
+	// OnlyInA and OnlyInB are the paths, relative to their tree, of the files without counterpart
+	OnlyInA []string
+	OnlyInB []string
+	// Failed maps the files whose metadata could not be extracted to the error
+	Failed map[string]error
+}
+
+// skippedGroups are the groups describing the file rather than its content, whose tags are not
+// compared when CompareTrees is given no tag
+var skippedGroups = map[string]bool{"File": true, "System": true, "ExifTool": true}
+
+// fileSystemKeys are the keys of the skippedGroups tags, used when keys are not group qualified
+// (e.g. with a custom Transport ignoring -G0)
+var fileSystemKeys = map[string]bool{
+	"SourceFile": true, "FileName": true, "Directory": true, "FileSize": true,
+	"FileModifyDate": true, "FileAccessDate": true, "FileInodeChangeDate": true,
+	"FileCreateDate": true, "FilePermissions": true, "FileAttributes": true,
+	"FileType": true, "FileTypeExtension": true, "MIMEType": true, "ExifByteOrder": true,
+	"ExifToolVersion": true, "Warning": true, "Error": true,
+}
+
+// CompareTrees extracts the metadata of the files of the directories a and b (and of their
+// sub-directories) and reports the files whose given tags differ, e.g. to check that transcodes
+// or exports preserved the critical metadata. Tags are resolved as with the LenientKeys option.
+// When no tag is given, all the tags are compared, except the ones of the File, System and ExifTool
+// groups (e.g. FileType, MIMEType): files are then extracted with group names (family 0) and
+// the keys of the changes are group qualified.
+// Files are matched by their path relative to a and b, then by their path without extension
+// (e.g. "2019/IMG_1.CR2" and "2019/IMG_1.jpg") when it is unambiguous. Use CompareTreesWith to
+// extract both trees in parallel.
+// Sample :
+//   cmp, err := e.CompareTrees("originals", "exports", "DateTimeOriginal", "GPSPosition", "Copyright")
+func (e *Exiftool) CompareTrees(a, b string, tags ...string) (TreeComparison, error) {
+	return compareTrees(e, e, a, b, tags)
+}
+
+// CompareTreesWith compares trees like CompareTrees, extracting a with ea and b with eb in parallel
+func CompareTreesWith(ea, eb *Exiftool, a, b string, tags ...string) (TreeComparison, error) {
+	return compareTrees(ea, eb, a, b, tags)
+}
+
+func compareTrees(ea, eb *Exiftool, a, b string, tags []string) (TreeComparison, error) {
+	var fmsA, fmsB map[string]FileMetadata
+	var errA, errB error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fmsA, errA = extractTree(ea, a)
+	}()
+	fmsB, errB = extractTree(eb, b)
+	wg.Wait()
+	if errA != nil {
+		return TreeComparison{}, errA
+	}
+	if errB != nil {
+		return TreeComparison{}, errB
+	}
+
+	cmp := TreeComparison{Failed: make(map[string]error)}
+	pairs := matchFiles(fmsA, fmsB)
+	for _, p := range pairs {
+		fmA, fmB := fmsA[p[0]], fmsB[p[1]]
+		delete(fmsA, p[0])
+		delete(fmsB, p[1])
+		if fmA.Err != nil || fmB.Err != nil {
+			for _, fm := range []FileMetadata{fmA, fmB} {
+				if fm.Err != nil {
+					cmp.Failed[fm.File] = fm.Err
+				}
+			}
+			continue
+		}
+		if changes := Diff(comparedFields(fmA, tags), comparedFields(fmB, tags)); len(changes) > 0 {
+			cmp.Differences = append(cmp.Differences, TreeDifference{A: fmA.File, B: fmB.File, Changes: changes})
+		}
+	}
+	for rel, fm := range fmsA {
+		if _, failed := cmp.Failed[fm.File]; !failed {
+			cmp.OnlyInA = append(cmp.OnlyInA, rel)
+		}
+	}
+	for rel, fm := range fmsB {
+		if _, failed := cmp.Failed[fm.File]; !failed {
+			cmp.OnlyInB = append(cmp.OnlyInB, rel)
+		}
+	}
+	sort.Strings(cmp.OnlyInA)
+	sort.Strings(cmp.OnlyInB)
+	return cmp, nil
+}
+
+// extractTree extracts the metadata of the files of a tree, by path relative to its root
+func extractTree(e *Exiftool, root string) (map[string]FileMetadata, error) {
+	files, err := walkFiles(root)
+	if err != nil {
+		return nil, fmt.Errorf("error while listing files of %v: %w", root, err)
+	}
+	res := make(map[string]FileMetadata, len(files))
+	if len(files) == 0 {
+		return res, nil
+	}
+	c := extractConfig{args: []string{"-G0"}}
+	for _, fm := range e.extractMetadata(context.Background(), c, files) {
+		rel, err := filepath.Rel(root, fm.File)
+		if err != nil {
+			return nil, err
+		}
+		res[filepath.ToSlash(rel)] = fm
+	}
+	return res, nil
+}
+
+// matchFiles returns the pairs of relative paths of a and b designating the same file, sorted
+func matchFiles(a, b map[string]FileMetadata) [][2]string {
+	var pairs [][2]string
+	unmatchedA := make(map[string][]string)
+	unmatchedB := make(map[string][]string)
+	for rel := range a {
+		if _, found := b[rel]; found {
+			pairs = append(pairs, [2]string{rel, rel})
+		} else {
+			unmatchedA[trimExt(rel)] = append(unmatchedA[trimExt(rel)], rel)
+		}
+	}
+	for rel := range b {
+		if _, found := a[rel]; !found {
+			unmatchedB[trimExt(rel)] = append(unmatchedB[trimExt(rel)], rel)
+		}
+	}
+	for base, rels := range unmatchedA {
+		if others := unmatchedB[base]; len(rels) == 1 && len(others) == 1 {
+			pairs = append(pairs, [2]string{rels[0], others[0]})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i][0] < pairs[j][0]
+	})
+	return pairs
+}
+
+func trimExt(p string) string {
+	return strings.TrimSuffix(p, filepath.Ext(p))
+}
+
+// comparedFields returns a FileMetadata holding the fields to compare: the given tags, or all the
+// fields except the ones of the skippedGroups
+func comparedFields(fm FileMetadata, tags []string) FileMetadata {
+	res := EmptyFileMetadata()
+	if len(tags) == 0 {
+		for k, v := range fm.Fields {
+			if !skippedKey(k) {
+				res.Fields[k] = v
+			}
+		}
+		return res
+	}
+	for _, t := range tags {
+		if v, found := fm.Lookup(t); found {
+			res.Fields[t] = v
+		}
+	}
+	return res
+}
+
+// skippedKey tells if a key belongs to one of the skippedGroups, whatever the family of its group
+func skippedKey(k string) bool {
+	group, tag := SplitTagKey(k)
+	if group == "" {
+		return fileSystemKeys[tag]
+	}
+	for _, g := range strings.Split(group, ":") {
+		if skippedGroups[strings.TrimLeft(g, "0123456789")] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package exiftool
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchFiles(t *testing.T) {
+	a := map[string]FileMetadata{"same.jpg": {}, "raw/IMG_1.CR2": {}, "dup.jpg": {}, "dup.png": {}, "onlyA.jpg": {}}
+	b := map[string]FileMetadata{"same.jpg": {}, "raw/IMG_1.jpg": {}, "dup.tif": {}, "onlyB.jpg": {}}
+	exp := [][2]string{{"raw/IMG_1.CR2", "raw/IMG_1.jpg"}, {"same.jpg", "same.jpg"}}
+	assert.Equal(t, exp, matchFiles(a, b))
+}
+
+func TestComparedFields(t *testing.T) {
+	fm := NewFileMetadata("a.jpg").
+		WithString("EXIF:Make", "Canon").
+		WithString("EXIF:Model", "EOS").
+		WithString("File:FileSize", "10 kB").
+		WithString("FileName", "a.jpg")
+
+	assert.Equal(t, map[string]interface{}{"EXIF:Make": "Canon", "EXIF:Model": "EOS"}, comparedFields(fm, nil).Fields)
+	assert.Equal(t, map[string]interface{}{"Make": "Canon"}, comparedFields(fm, []string{"Make", "Copyright"}).Fields)
+}
+
+func TestComparedFieldsFileTypes(t *testing.T) {
+	raw := NewFileMetadata("IMG_1.CR2").
+		WithString("EXIF:DateTimeOriginal", "2019:04:04 13:18:03").
+		WithString("File:FileType", "CR2").
+		WithString("File:FileTypeExtension", "cr2").
+		WithString("File:MIMEType", "image/x-canon-cr2").
+		WithString("File:ExifByteOrder", "Little-endian (Intel, II)").
+		WithString("ExifTool:ExifToolVersion", "12.40").
+		WithString("System:FileName", "IMG_1.CR2")
+	jpeg := NewFileMetadata("IMG_1.jpg").
+		WithString("EXIF:DateTimeOriginal", "2019:04:04 13:18:03").
+		WithString("File:FileType", "JPEG").
+		WithString("File:FileTypeExtension", "jpg").
+		WithString("File:MIMEType", "image/jpeg").
+		WithString("File:ExifByteOrder", "Big-endian (Motorola, MM)").
+		WithString("ExifTool:ExifToolVersion", "12.41").
+		WithString("System:FileName", "IMG_1.jpg")
+	assert.Empty(t, Diff(comparedFields(raw, nil), comparedFields(jpeg, nil)))
+
+	ungroupedRaw := NewFileMetadata("IMG_1.CR2").WithString("FileType", "CR2").WithString("MIMEType", "image/x-canon-cr2")
+	ungroupedJpeg := NewFileMetadata("IMG_1.jpg").WithString("FileType", "JPEG").WithString("MIMEType", "image/jpeg")
+	assert.Empty(t, Diff(comparedFields(ungroupedRaw, nil), comparedFields(ungroupedJpeg, nil)))
+
+	jpeg.SetString("EXIF:DateTimeOriginal", "2020:01:01 00:00:00")
+	changes := Diff(comparedFields(raw, nil), comparedFields(jpeg, nil))
+	require.Len(t, changes, 1)
+	assert.Equal(t, "EXIF:DateTimeOriginal", changes[0].Key)
+}
+
+func TestCompareTrees(t *testing.T) {
+	t.Parallel()
+
+	a, b := t.TempDir(), t.TempDir()
+	require.Nil(t, os.Mkdir(filepath.Join(a, "sub"), 0755))
+	require.Nil(t, os.Mkdir(filepath.Join(b, "sub"), 0755))
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", filepath.Join(a, "sub", "a.jpg")))
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", filepath.Join(b, "sub", "a.jpeg")))
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", filepath.Join(a, "onlyA.jpg")))
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	cmp, err := e.CompareTrees(a, b, "DateTimeOriginal", "FileName")
+	require.Nil(t, err)
+	require.Len(t, cmp.Differences, 1)
+	assert.Equal(t, filepath.Join(a, "sub", "a.jpg"), cmp.Differences[0].A)
+	assert.Equal(t, filepath.Join(b, "sub", "a.jpeg"), cmp.Differences[0].B)
+	assert.Equal(t, []FieldChange{{Key: "FileName", Type: FieldChanged, Old: "a.jpg", New: "a.jpeg"}}, cmp.Differences[0].Changes)
+	assert.Equal(t, []string{"onlyA.jpg"}, cmp.OnlyInA)
+	assert.Empty(t, cmp.OnlyInB)
+	assert.Empty(t, cmp.Failed)
+
+	e2, err := NewExiftool()
+	require.Nil(t, err)
+	defer e2.Close()
+
+	cmp, err = CompareTreesWith(e, e2, a, b, "DateTimeOriginal")
+	require.Nil(t, err)
+	assert.Empty(t, cmp.Differences)
+
+	cmp, err = e.CompareTrees(a, b)
+	require.Nil(t, err)
+	assert.Empty(t, cmp.Differences)
+
+	_, err = e.CompareTrees(a, filepath.Join(b, "missing"))
+	assert.NotNil(t, err)
+}
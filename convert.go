@@ -0,0 +1,105 @@
+package exiftool
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValueConverter converts the value of a tag, see ConvertExtracted and ConvertWritten. It is not
+// called for nil values.
+type ValueConverter func(k string, v interface{}) (interface{}, error)
+
+// tagConverter is a converter registered for the tags matching a pattern
+type tagConverter struct {
+	pattern string
+	fn      ValueConverter
+}
+
+// ConvertExtracted registers a converter applied to the values of the extracted tags matching the
+// pattern, which follows the same rules as AllowWriteTags ("*" matching any tag). Converters are
+// applied in registration order, the Err of the FileMetadata is set if a converter fails.
+// Sample :
+//   e, err := NewExiftool(ConvertExtracted("*", func(k string, v interface{}) (interface{}, error) {
+//     if s, ok := v.(string); ok {
+//       return strings.TrimSpace(s), nil
+//     }
+//     return v, nil
+//   }))
+func ConvertExtracted(pattern string, fn ValueConverter) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		c, err := newTagConverter(pattern, fn)
+		if err != nil {
+			return err
+		}
+		e.extractConverters = append(e.extractConverters, c)
+		return nil
+	}
+}
+
+// ConvertWritten registers a converter applied to the values of the tags matching the pattern
+// before they are written (the FileMetadata is left untouched). The pattern follows the same rules
+// as AllowWriteTags, converters are applied in registration order and the write of the file fails
+// if a converter fails. File operations (see SetBinaryFromFile) are not converted.
+// Sample :
+//   e, err := NewExiftool(ConvertWritten("Rating", func(k string, v interface{}) (interface{}, error) {
+//     if r, ok := v.(int64); ok && r > 5 {
+//       return r / 20, nil // 0-100 scale to 0-5 scale
+//     }
+//     return v, nil
+//   }))
+func ConvertWritten(pattern string, fn ValueConverter) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		c, err := newTagConverter(pattern, fn)
+		if err != nil {
+			return err
+		}
+		e.writeConverters = append(e.writeConverters, c)
+		return nil
+	}
+}
+
+func newTagConverter(pattern string, fn ValueConverter) (tagConverter, error) {
+	if fn == nil {
+		return tagConverter{}, fmt.Errorf("converter can't be nil")
+	}
+	p, err := writeTagPatterns([]string{pattern})
+	if err != nil {
+		return tagConverter{}, err
+	}
+	return tagConverter{pattern: p[0], fn: fn}, nil
+}
+
+// convertFields applies the converters to all the fields
+func convertFields(converters []tagConverter, fields map[string]interface{}) error {
+	if len(converters) == 0 {
+		return nil
+	}
+	for k, v := range fields {
+		c, err := convertValue(converters, k, v)
+		if err != nil {
+			return err
+		}
+		fields[k] = c
+	}
+	return nil
+}
+
+// convertValue applies the converters matching the key to a value
+func convertValue(converters []tagConverter, k string, v interface{}) (interface{}, error) {
+	if strings.HasSuffix(k, "<") {
+		return v, nil
+	}
+	for _, c := range converters {
+		if v == nil {
+			return nil, nil
+		}
+		if !matchesTagPatterns([]string{c.pattern}, k) {
+			continue
+		}
+		var err error
+		if v, err = c.fn(k, v); err != nil {
+			return nil, fmt.Errorf("error while converting %v: %w", k, err)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,90 @@
+package exiftool
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func trimConverter(k string, v interface{}) (interface{}, error) {
+	if s, ok := v.(string); ok {
+		return strings.TrimSpace(s), nil
+	}
+	return v, nil
+}
+
+func upperConverter(k string, v interface{}) (interface{}, error) {
+	return strings.ToUpper(toString(v)), nil
+}
+
+func failingConverter(k string, v interface{}) (interface{}, error) {
+	return nil, errors.New("failure")
+}
+
+func TestConvertFields(t *testing.T) {
+	trim, err := newTagConverter("*", trimConverter)
+	require.Nil(t, err)
+	upper, err := newTagConverter("XMP:*", upperConverter)
+	require.Nil(t, err)
+
+	fields := map[string]interface{}{"XMP:Title": " title ", "EXIF:Artist": " artist ", "EXIF:ISO": float64(100), "XMP:Label": nil}
+	require.Nil(t, convertFields([]tagConverter{trim, upper}, fields))
+	assert.Equal(t, map[string]interface{}{"XMP:Title": "TITLE", "EXIF:Artist": "artist", "EXIF:ISO": float64(100), "XMP:Label": nil}, fields)
+
+	failing, err := newTagConverter("Artist", failingConverter)
+	require.Nil(t, err)
+	assert.NotNil(t, convertFields([]tagConverter{failing}, fields))
+}
+
+func TestNewTagConverter(t *testing.T) {
+	_, err := newTagConverter("[", trimConverter)
+	assert.NotNil(t, err)
+	_, err = newTagConverter("*", nil)
+	assert.NotNil(t, err)
+}
+
+func TestWriteArgsConverters(t *testing.T) {
+	e, err := NewExiftool(ConvertWritten("Title", trimConverter), ConvertWritten("Title", upperConverter))
+	require.Nil(t, err)
+	defer e.Close()
+
+	md := EmptyFileMetadata()
+	md.SetString("Title", " title ")
+	md.SetString("Artist", " artist ")
+	md.Clear("Comment")
+	md.SetBinaryFromFile("Title", "title.txt")
+	args, err := e.writeArgs(md)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"-overwrite_original", "-Artist= artist ", "-Comment=", "-Title=TITLE", "-Title<=title.txt"}, args)
+	assert.Equal(t, " title ", md.Fields["Title"])
+
+	eFailing, err := NewExiftool(ConvertWritten("*", failingConverter))
+	require.Nil(t, err)
+	defer eFailing.Close()
+	_, err = eFailing.writeArgs(md)
+	assert.NotNil(t, err)
+}
+
+func TestExtractMetadataConverters(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool(ConvertExtracted("FileName", upperConverter))
+	require.Nil(t, err)
+	defer e.Close()
+	mds := e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	got, err := mds[0].GetString("FileName")
+	require.Nil(t, err)
+	assert.Equal(t, "20190404_131804.JPG", got)
+
+	eFailing, err := NewExiftool(ConvertExtracted("FileName", failingConverter))
+	require.Nil(t, err)
+	defer eFailing.Close()
+	mds = eFailing.ExtractMetadata("./testdata/20190404_131804.jpg")
+	require.Len(t, mds, 1)
+	assert.NotNil(t, mds[0].Err)
+}
@@ -0,0 +1,184 @@
+package exiftool
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrInvalidDecodeTarget is a sentinel error used when Decode is not given a non nil pointer to a struct
+var ErrInvalidDecodeTarget = errors.New("decode target must be a non nil pointer to a struct")
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	durationType    = reflect.TypeOf(time.Duration(0))
+	orientationType = reflect.TypeOf(Orientation(0))
+)
+
+// Decode maps the fields into the struct dst points to, using the exiftool struct tags of its fields
+// to find the matching keys. Fields without tag (or tagged with "-") are ignored, as are the keys
+// that can't be found. Supported field types are strings, integers, floats, bool, time.Time (see
+// GetDateTime), time.Duration (see GetDuration), Orientation, slices of strings, integers or floats,
+// pointers to these types (left nil when the key can't be found) and embedded structs.
+// Sample :
+//   type Photo struct {
+//     Title    string    `exiftool:"Title"`
+//     Taken    time.Time `exiftool:"DateTimeOriginal"`
+//     Width    int       `exiftool:"ImageWidth"`
+//     Keywords []string  `exiftool:"Keywords"`
+//   }
+//   var p Photo
+//   err := fm.Decode(&p)
+func (fm FileMetadata) Decode(dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrInvalidDecodeTarget
+	}
+	return fm.decodeStruct(rv.Elem())
+}
+
+func (fm FileMetadata) decodeStruct(rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, tagged := sf.Tag.Lookup("exiftool")
+		if sf.Anonymous && !tagged && sf.Type.Kind() == reflect.Struct {
+			if err := fm.decodeStruct(rv.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+		if !tagged || tag == "-" || tag == "" || sf.PkgPath != "" {
+			continue
+		}
+		if _, found := fm.field(tag); !found {
+			continue
+		}
+		if err := fm.decodeField(tag, rv.Field(i)); err != nil {
+			return fmt.Errorf("error while decoding %v into field %v: %w", tag, sf.Name, err)
+		}
+	}
+	return nil
+}
+
+func (fm FileMetadata) decodeField(k string, rv reflect.Value) error {
+	if v, _ := fm.field(k); v == nil {
+		return nil
+	}
+
+	switch rv.Type() {
+	case timeType:
+		t, err := fm.GetDateTime(k)
+		if err == nil {
+			rv.Set(reflect.ValueOf(t))
+		}
+		return err
+	case durationType:
+		d, err := fm.GetDuration(k)
+		if err == nil {
+			rv.SetInt(int64(d))
+		}
+		return err
+	case orientationType:
+		str, err := fm.GetString(k)
+		if err != nil {
+			return err
+		}
+		o, err := ParseOrientation(str)
+		if err == nil {
+			rv.SetInt(int64(o))
+		}
+		return err
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr:
+		p := reflect.New(rv.Type().Elem())
+		if err := fm.decodeField(k, p.Elem()); err != nil {
+			return err
+		}
+		rv.Set(p)
+		return nil
+	case reflect.String:
+		str, err := fm.GetString(k)
+		rv.SetString(str)
+		return err
+	case reflect.Bool:
+		b, err := fm.GetBool(k)
+		rv.SetBool(b)
+		return err
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := fm.GetInt(k)
+		if err != nil {
+			return err
+		}
+		if rv.OverflowInt(i) {
+			return fmt.Errorf("%v overflows %v", i, rv.Type())
+		}
+		rv.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := fm.GetInt(k)
+		if err != nil {
+			return err
+		}
+		if i < 0 || rv.OverflowUint(uint64(i)) {
+			return fmt.Errorf("%v overflows %v", i, rv.Type())
+		}
+		rv.SetUint(uint64(i))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := fm.GetFloat(k)
+		rv.SetFloat(f)
+		return err
+	case reflect.Slice:
+		return fm.decodeSlice(k, rv)
+	default:
+		return fmt.Errorf("unsupported type %v", rv.Type())
+	}
+}
+
+func (fm FileMetadata) decodeSlice(k string, rv reflect.Value) error {
+	var items []interface{}
+	switch rv.Type().Elem().Kind() {
+	case reflect.String:
+		strs, err := fm.GetStrings(k)
+		if err != nil {
+			return err
+		}
+		for _, s := range strs {
+			items = append(items, s)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		ints, err := fm.GetInts(k)
+		if err != nil {
+			return err
+		}
+		for _, i := range ints {
+			items = append(items, i)
+		}
+	case reflect.Float32, reflect.Float64:
+		floats, err := fm.GetFloats(k)
+		if err != nil {
+			return err
+		}
+		for _, f := range floats {
+			items = append(items, f)
+		}
+	default:
+		return fmt.Errorf("unsupported type %v", rv.Type())
+	}
+
+	s := reflect.MakeSlice(rv.Type(), len(items), len(items))
+	for i, item := range items {
+		// item decoding goes through a single field FileMetadata to share the scalar conversions
+		itemFm := FileMetadata{Fields: map[string]interface{}{k: item}}
+		if err := itemFm.decodeField(k, s.Index(i)); err != nil {
+			return err
+		}
+	}
+	rv.Set(s)
+	return nil
+}
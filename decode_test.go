@@ -0,0 +1,129 @@
+package exiftool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type decodeEmbedded struct {
+	Make string `exiftool:"Make"`
+}
+
+type decodeTarget struct {
+	decodeEmbedded
+	Title         string        `exiftool:"Title"`
+	Width         int           `exiftool:"ImageWidth"`
+	Height        uint16        `exiftool:"ImageHeight"`
+	Aperture      float32       `exiftool:"FNumber"`
+	Flash         bool          `exiftool:"FlashFired"`
+	Taken         time.Time     `exiftool:"DateTimeOriginal"`
+	Duration      time.Duration `exiftool:"Duration"`
+	Orientation   Orientation   `exiftool:"Orientation"`
+	Keywords      []string      `exiftool:"Keywords"`
+	BitsPerSample []int         `exiftool:"BitsPerSample"`
+	Artist        *string       `exiftool:"Artist"`
+	Rating        *int          `exiftool:"Rating"`
+	Missing       string        `exiftool:"Missing"`
+	Ignored       string        `exiftool:"-"`
+	Untagged      string
+}
+
+func TestDecode(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.Fields = map[string]interface{}{
+		"Make":             "Canon",
+		"Title":            "title",
+		"ImageWidth":       float64(4000),
+		"ImageHeight":      "3000",
+		"FNumber":          float64(2.8),
+		"FlashFired":       "True",
+		"DateTimeOriginal": "2020:01:02 03:04:05",
+		"Duration":         "0:00:30",
+		"Orientation":      "Rotate 90 CW",
+		"Keywords":         []interface{}{"a", "b"},
+		"BitsPerSample":    "8 8 8",
+		"Artist":           "John Doe",
+		"Ignored":          "ignored",
+		"Untagged":         "untagged",
+	}
+
+	var got decodeTarget
+	require.Nil(t, fm.Decode(&got))
+	artist := "John Doe"
+	assert.Equal(t, decodeTarget{
+		decodeEmbedded: decodeEmbedded{Make: "Canon"},
+		Title:          "title",
+		Width:          4000,
+		Height:         3000,
+		Aperture:       2.8,
+		Flash:          true,
+		Taken:          time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Duration:       30 * time.Second,
+		Orientation:    OrientationRotate90CW,
+		Keywords:       []string{"a", "b"},
+		BitsPerSample:  []int{8, 8, 8},
+		Artist:         &artist,
+	}, got)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	type overflow struct {
+		V int8 `exiftool:"V"`
+	}
+	type negative struct {
+		V uint `exiftool:"V"`
+	}
+	type unsupported struct {
+		V map[string]string `exiftool:"V"`
+	}
+	type notNumeric struct {
+		V int `exiftool:"V"`
+	}
+	type notDate struct {
+		V time.Time `exiftool:"V"`
+	}
+
+	var tcs = []struct {
+		tcID  string
+		inDst interface{}
+		inV   interface{}
+	}{
+		{"overflow", &overflow{}, float64(1000)},
+		{"negative", &negative{}, float64(-1)},
+		{"unsupported", &unsupported{}, "a"},
+		{"notNumeric", &notNumeric{}, "a"},
+		{"notDate", &notDate{}, "a"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			fm := EmptyFileMetadata()
+			fm.Fields["V"] = tc.inV
+			assert.NotNil(t, fm.Decode(tc.inDst))
+		})
+	}
+}
+
+func TestDecodeInvalidTarget(t *testing.T) {
+	var target decodeTarget
+	var nilTarget *decodeTarget
+	var str string
+	fm := EmptyFileMetadata()
+	assert.Equal(t, ErrInvalidDecodeTarget, fm.Decode(target))
+	assert.Equal(t, ErrInvalidDecodeTarget, fm.Decode(nilTarget))
+	assert.Equal(t, ErrInvalidDecodeTarget, fm.Decode(&str))
+	assert.Equal(t, ErrInvalidDecodeTarget, fm.Decode(nil))
+}
+
+func TestDecodeNilValue(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.Clear("Title")
+	fm.Clear("Artist")
+	var got decodeTarget
+	require.Nil(t, fm.Decode(&got))
+	assert.Equal(t, decodeTarget{}, got)
+}
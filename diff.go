@@ -0,0 +1,74 @@
+package exiftool
+
+import (
+	"reflect"
+	"sort"
+)
+
+// ChangeType is the kind of change reported by Diff
+type ChangeType int
+
+// Kinds of change reported by Diff
+const (
+	FieldAdded ChangeType = iota
+	FieldRemoved
+	FieldChanged
+)
+
+// String returns the name of the change type
+func (t ChangeType) String() string {
+	switch t {
+	case FieldAdded:
+		return "added"
+	case FieldRemoved:
+		return "removed"
+	case FieldChanged:
+		return "changed"
+	default:
+		return "unknown"
+	}
+}
+
+// FieldChange is a difference between two FileMetadata: Old is nil for added fields and New is
+// nil for removed ones
+type FieldChange struct {
+	Key  string
+	Type ChangeType
+	Old  interface{}
+	New  interface{}
+}
+
+// Diff returns the fields that have been added, removed or changed between a and b, sorted by key.
+// Values are compared the way exiftool writes them, so that values of different types but with
+// the same rendering (e.g. float64(72), int64(72) and "72", as returned by extractions made with
+// different options or set with different setters) are not reported as changed.
+func Diff(a, b FileMetadata) []FieldChange {
+	var changes []FieldChange
+	for k, old := range a.Fields {
+		v, found := b.Fields[k]
+		switch {
+		case !found:
+			changes = append(changes, FieldChange{Key: k, Type: FieldRemoved, Old: old})
+		case !sameValue(old, v):
+			changes = append(changes, FieldChange{Key: k, Type: FieldChanged, Old: old, New: v})
+		}
+	}
+	for k, v := range b.Fields {
+		if _, found := a.Fields[k]; !found {
+			changes = append(changes, FieldChange{Key: k, Type: FieldAdded, New: v})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+// sameValue tells if two field values are identical once rendered, nil (deleted tag) only
+// being equal to nil
+func sameValue(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return reflect.DeepEqual(a, b) || writeString(a) == writeString(b)
+}
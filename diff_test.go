@@ -0,0 +1,54 @@
+package exiftool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	date := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var tcs = []struct {
+		tcID   string
+		inA    map[string]interface{}
+		inB    map[string]interface{}
+		expRes []FieldChange
+	}{
+		{"same", map[string]interface{}{"a": "1"}, map[string]interface{}{"a": "1"}, nil},
+		{"empty", map[string]interface{}{}, map[string]interface{}{}, nil},
+		{"added", map[string]interface{}{}, map[string]interface{}{"a": "1"}, []FieldChange{{"a", FieldAdded, nil, "1"}}},
+		{"removed", map[string]interface{}{"a": "1"}, map[string]interface{}{}, []FieldChange{{"a", FieldRemoved, "1", nil}}},
+		{"changed", map[string]interface{}{"a": "1"}, map[string]interface{}{"a": "2"}, []FieldChange{{"a", FieldChanged, "1", "2"}}},
+		{"coercedNumbers", map[string]interface{}{"a": float64(72), "b": int64(3)}, map[string]interface{}{"a": "72", "b": float64(3)}, nil},
+		{"coercedDate", map[string]interface{}{"a": date}, map[string]interface{}{"a": "2020:01:02 03:04:05"}, nil},
+		{"coercedList", map[string]interface{}{"a": []interface{}{"x", "y"}}, map[string]interface{}{"a": []string{"x", "y"}}, nil},
+		{"changedList", map[string]interface{}{"a": []interface{}{"x", "y"}}, map[string]interface{}{"a": []interface{}{"y", "x"}}, []FieldChange{{"a", FieldChanged, []interface{}{"x", "y"}, []interface{}{"y", "x"}}}},
+		{"nilToEmpty", map[string]interface{}{"a": nil}, map[string]interface{}{"a": ""}, []FieldChange{{"a", FieldChanged, nil, ""}}},
+		{"nil", map[string]interface{}{"a": nil}, map[string]interface{}{"a": nil}, nil},
+		{"sorted", map[string]interface{}{"b": "1", "c": "1"}, map[string]interface{}{"a": "1", "c": "2"}, []FieldChange{
+			{"a", FieldAdded, nil, "1"},
+			{"b", FieldRemoved, "1", nil},
+			{"c", FieldChanged, "1", "2"},
+		}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			a := EmptyFileMetadata()
+			a.Fields = tc.inA
+			b := EmptyFileMetadata()
+			b.Fields = tc.inB
+			assert.Equal(t, tc.expRes, Diff(a, b))
+		})
+	}
+}
+
+func TestChangeTypeString(t *testing.T) {
+	assert.Equal(t, "added", FieldAdded.String())
+	assert.Equal(t, "removed", FieldRemoved.String())
+	assert.Equal(t, "changed", FieldChanged.String())
+	assert.Equal(t, "unknown", ChangeType(42).String())
+}
@@ -0,0 +1,150 @@
+package exiftool
+
+import (
+	"crypto"
+	_ "crypto/sha256" // hash used by FindDuplicatesInDirs
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DuplicateKind is the kind of a DuplicateGroup
+type DuplicateKind int
+
+// Kinds of duplicates
+const (
+	// ExactDuplicates have the same content
+	ExactDuplicates DuplicateKind = iota
+	// NearDuplicates have different contents (e.g. re-encoded or resized copies) but the same
+	// capture date, camera model and image size
+	NearDuplicates
+)
+
+// String returns the name of the duplicate kind
+func (k DuplicateKind) String() string {
+	switch k {
+	case ExactDuplicates:
+		return "exact"
+	case NearDuplicates:
+		return "near"
+	default:
+		return "unknown"
+	}
+}
+
+// DuplicateGroup is a group of files considered as duplicates. Key is what the files share: the
+// checksum of exact duplicates (e.g. "SHA-256:8f43..."), the capture date, camera model and image
+// size of near duplicates (e.g. "2019-04-04T13:18:04Z|Pixel 3|4032x3024").
+type DuplicateGroup struct {
+	Kind  DuplicateKind
+	Key   string
+	Files []string
+}
+
+// duplicateChecksums are the hashes used to detect exact duplicates, by order of preference
+var duplicateChecksums = []crypto.Hash{crypto.SHA256, crypto.SHA1, crypto.MD5}
+
+// FindDuplicates groups the files that are exact duplicates, according to their checksums (see the
+// Checksums option), and near duplicates, according to their capture date (see GetCaptureTime),
+// camera model and image size. Files without checksum are not compared by content, files without
+// capture date and files with an error are not compared by metadata. Near duplicates groups only
+// made of exact duplicates are not reported. Groups are sorted by kind and key.
+func FindDuplicates(fms []FileMetadata) []DuplicateGroup {
+	exact := map[string][]string{}
+	exactKeys := map[string]string{}
+	near := map[string][]string{}
+	for _, fm := range fms {
+		if k := checksumKey(fm); k != "" {
+			exact[k] = append(exact[k], fm.File)
+			exactKeys[fm.File] = k
+		}
+		if fm.Err != nil {
+			continue
+		}
+		if k := nearDuplicateKey(fm); k != "" {
+			near[k] = append(near[k], fm.File)
+		}
+	}
+
+	var groups []DuplicateGroup
+	for k, files := range exact {
+		if len(files) > 1 {
+			groups = append(groups, DuplicateGroup{Kind: ExactDuplicates, Key: k, Files: files})
+		}
+	}
+	for k, files := range near {
+		if len(files) > 1 && !sameContent(files, exactKeys) {
+			groups = append(groups, DuplicateGroup{Kind: NearDuplicates, Key: k, Files: files})
+		}
+	}
+
+	for _, g := range groups {
+		sort.Strings(g.Files)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Kind != groups[j].Kind {
+			return groups[i].Kind < groups[j].Kind
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
+
+// FindDuplicatesInDirs extracts the metadata of the files of the given directories and of their
+// sub-directories, and groups their duplicates (see FindDuplicates). Files are hashed with SHA-256
+// unless the Checksums option is enabled.
+func (e *Exiftool) FindDuplicatesInDirs(dirs ...string) ([]DuplicateGroup, error) {
+	files, err := walkFiles(dirs...)
+	if err != nil {
+		return nil, fmt.Errorf("error while listing files: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	fms := e.ExtractMetadata(files...)
+	for i := range fms {
+		if len(fms[i].Checksums) > 0 {
+			continue
+		}
+		if fms[i].Checksums, err = computeChecksums(fms[i].File, []crypto.Hash{crypto.SHA256}); err != nil {
+			return nil, fmt.Errorf("error while computing checksum of %v: %w", fms[i].File, err)
+		}
+	}
+	return FindDuplicates(fms), nil
+}
+
+// checksumKey returns the preferred checksum of a file, "" if none has been computed
+func checksumKey(fm FileMetadata) string {
+	for _, h := range duplicateChecksums {
+		if sum, found := fm.Checksums[h]; found {
+			return checksumNames[h] + ":" + sum
+		}
+	}
+	return ""
+}
+
+// nearDuplicateKey returns the capture date, camera model and image size of a file, "" if it has
+// no capture date
+func nearDuplicateKey(fm FileMetadata) string {
+	fm.lenientKeys = true
+	t, err := fm.GetCaptureTime(nil)
+	if err != nil {
+		return ""
+	}
+	size := ""
+	if w, h, err := fm.ImageSize(); err == nil {
+		size = fmt.Sprintf("%vx%v", w, h)
+	}
+	return fmt.Sprintf("%v|%v|%v", t.Format(time.RFC3339Nano), fm.CameraInfo().Model, size)
+}
+
+// sameContent tells if all the files have the same checksum
+func sameContent(files []string, checksums map[string]string) bool {
+	for _, f := range files {
+		if k, found := checksums[f]; !found || k != checksums[files[0]] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package exiftool
+
+import (
+	"crypto"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	photo := func(file, sum, date string) FileMetadata {
+		fm := NewFileMetadata(file).
+			WithString("DateTimeOriginal", date).
+			WithString("Model", "Pixel 3").
+			WithInt("ImageWidth", 4032).
+			WithInt("ImageHeight", 3024)
+		fm.Checksums = map[crypto.Hash]string{crypto.SHA256: sum}
+		return fm
+	}
+	failed := photo("failed.jpg", "f4", "2019:04:04 13:18:04")
+	failed.Err = assert.AnError
+	noDate := photo("nodate.jpg", "e5", "")
+	delete(noDate.Fields, "DateTimeOriginal")
+	md5Only := NewFileMetadata("md5.jpg")
+	md5Only.Checksums = map[crypto.Hash]string{crypto.MD5: "a1"}
+	md5Copy := NewFileMetadata("md5copy.jpg")
+	md5Copy.Checksums = map[crypto.Hash]string{crypto.MD5: "a1"}
+
+	fms := []FileMetadata{
+		photo("b/original.jpg", "a1", "2019:04:04 13:18:04"),
+		photo("a/copy.jpg", "a1", "2019:04:04 13:18:04"),
+		photo("resized.jpg", "b2", "2019:04:04 13:18:04"),
+		photo("other.jpg", "c3", "2020:01:01 00:00:00"),
+		photo("same1.jpg", "d4", "2021:01:01 00:00:00"),
+		photo("same2.jpg", "d4", "2021:01:01 00:00:00"),
+		failed,
+		noDate,
+		md5Only,
+		md5Copy,
+	}
+
+	exp := []DuplicateGroup{
+		{Kind: ExactDuplicates, Key: "MD5:a1", Files: []string{"md5.jpg", "md5copy.jpg"}},
+		{Kind: ExactDuplicates, Key: "SHA-256:a1", Files: []string{"a/copy.jpg", "b/original.jpg"}},
+		{Kind: ExactDuplicates, Key: "SHA-256:d4", Files: []string{"same1.jpg", "same2.jpg"}},
+		{Kind: NearDuplicates, Key: "2019-04-04T13:18:04Z|Pixel 3|4032x3024", Files: []string{"a/copy.jpg", "b/original.jpg", "resized.jpg"}},
+	}
+	assert.Equal(t, exp, FindDuplicates(fms))
+	assert.Nil(t, FindDuplicates(nil))
+	assert.Equal(t, "near", NearDuplicates.String())
+}
+
+func TestFindDuplicatesInDirs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.Nil(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", filepath.Join(dir, "a.jpg")))
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", filepath.Join(dir, "sub", "b.jpg")))
+	require.Nil(t, copyFile("testdata/gps.jpg", filepath.Join(dir, "c.jpg")))
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	groups, err := e.FindDuplicatesInDirs(dir)
+	require.Nil(t, err)
+	require.NotEmpty(t, groups)
+	assert.Equal(t, ExactDuplicates, groups[0].Kind)
+	assert.Equal(t, []string{filepath.Join(dir, "a.jpg"), filepath.Join(dir, "sub", "b.jpg")}, groups[0].Files)
+
+	_, err = e.FindDuplicatesInDirs(filepath.Join(dir, "missing"))
+	assert.NotNil(t, err)
+}
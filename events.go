@@ -0,0 +1,71 @@
+package exiftool
+
+// Event is a lifecycle event of the exiftool process, see OnEvent
+type Event int
+
+const (
+	// EventStarted is emitted when exiftool has been started by NewExiftool
+	EventStarted Event = iota
+	// EventClosed is emitted when Close has been called, with the error it returns
+	EventClosed
+	// EventCrashed is emitted, once, when exiftool can't be communicated with anymore (e.g. the
+	// process has exited), with the error that revealed it. Exiftool has to be restarted (see
+	// AutoRestart and Restart), or the instance closed and a new one created.
+	EventCrashed
+	// EventDesync is emitted when a response could not be read entirely (see ErrBufferTooSmall):
+	// the responses of the next commands are not the expected ones until exiftool is restarted,
+	// the instance should be replaced by one with a bigger buffer.
+	EventDesync
+	// EventRestarted is emitted when exiftool has been restarted (see AutoRestart and Restart)
+	EventRestarted
+)
+
+// String returns the name of the event
+func (ev Event) String() string {
+	switch ev {
+	case EventStarted:
+		return "started"
+	case EventClosed:
+		return "closed"
+	case EventCrashed:
+		return "crashed"
+	case EventDesync:
+		return "desync"
+	case EventRestarted:
+		return "restarted"
+	default:
+		return "unknown"
+	}
+}
+
+// EventHandler handles lifecycle events, err being the error related to the event (if any)
+type EventHandler func(ev Event, err error)
+
+// OnEvent registers a handler called on lifecycle events (see Event), e.g. to log them or to
+// replace a crashed instance. Handlers are called synchronously, while the instance is locked:
+// they must not call the Exiftool instance.
+// Sample :
+//   e, err := NewExiftool(OnEvent(func(ev Event, err error) {
+//     log.Printf("exiftool %v: %v", ev, err)
+//   }))
+func OnEvent(h EventHandler) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		e.eventHandlers = append(e.eventHandlers, h)
+		return nil
+	}
+}
+
+func (e *Exiftool) emit(ev Event, err error) {
+	for _, h := range e.eventHandlers {
+		h(ev, err)
+	}
+}
+
+// crash emits EventCrashed the first time communication with exiftool fails
+func (e *Exiftool) crash(err error) {
+	if e.crashed {
+		return
+	}
+	e.crashed = true
+	e.emit(EventCrashed, err)
+}
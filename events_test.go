@@ -0,0 +1,104 @@
+package exiftool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedEvent struct {
+	ev  Event
+	err error
+}
+
+func TestEvents(t *testing.T) {
+	t.Parallel()
+
+	var evs []recordedEvent
+	e, err := NewExiftool(OnEvent(func(ev Event, err error) {
+		evs = append(evs, recordedEvent{ev, err})
+	}))
+	require.Nil(t, err)
+	e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	require.Nil(t, e.Close())
+
+	assert.Equal(t, []recordedEvent{{EventStarted, nil}, {EventClosed, nil}}, evs)
+}
+
+func TestEventCrashed(t *testing.T) {
+	t.Parallel()
+
+	var evs []Event
+	e, err := NewExiftool(OnEvent(func(ev Event, err error) {
+		evs = append(evs, ev)
+	}))
+	require.Nil(t, err)
+	require.Nil(t, e.cmd.Process.Kill())
+
+	fms := e.ExtractMetadata("./testdata/20190404_131804.jpg", "./testdata/20190404_131804.jpg")
+	require.Len(t, fms, 2)
+	assert.NotNil(t, fms[0].Err)
+	assert.NotNil(t, fms[1].Err)
+	e.Close()
+
+	assert.Equal(t, []Event{EventStarted, EventCrashed, EventClosed}, evs)
+}
+
+func TestEventRestarted(t *testing.T) {
+	t.Parallel()
+
+	var evs []Event
+	e, err := NewExiftool(AutoRestart(), OnEvent(func(ev Event, err error) {
+		evs = append(evs, ev)
+	}))
+	require.Nil(t, err)
+	require.Nil(t, e.cmd.Process.Kill())
+
+	fms := e.ExtractMetadata("./testdata/20190404_131804.jpg", "./testdata/20190404_131804.jpg")
+	assert.NotNil(t, fms[0].Err)
+	assert.Nil(t, fms[1].Err)
+	require.Nil(t, e.Close())
+
+	assert.Equal(t, []Event{EventStarted, EventCrashed, EventRestarted, EventClosed}, evs)
+}
+
+func TestEventDesync(t *testing.T) {
+	t.Parallel()
+
+	var evs []recordedEvent
+	e, err := NewExiftool(
+		Buffer(make([]byte, 128), 256),
+		OnEvent(func(ev Event, err error) {
+			evs = append(evs, recordedEvent{ev, err})
+		}),
+	)
+	require.Nil(t, err)
+	defer e.Close()
+
+	e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	require.Len(t, evs, 2)
+	assert.Equal(t, recordedEvent{EventDesync, ErrBufferTooSmall}, evs[1])
+}
+
+func TestEventString(t *testing.T) {
+	var tcs = []struct {
+		tcID   string
+		inEv   Event
+		expStr string
+	}{
+		{"started", EventStarted, "started"},
+		{"closed", EventClosed, "closed"},
+		{"crashed", EventCrashed, "crashed"},
+		{"desync", EventDesync, "desync"},
+		{"restarted", EventRestarted, "restarted"},
+		{"unknown", Event(42), "unknown"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			assert.Equal(t, tc.expStr, tc.inEv.String())
+		})
+	}
+}
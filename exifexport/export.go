@@ -0,0 +1,273 @@
+// Package exifexport exports extraction results for data pipelines, with a schema inferred from
+// the chosen tags so that every record has the same columns with consistent types.
+//
+// NDJSON (one JSON object per line) and Parquet are supported. Parquet files are written without
+// any encoding library, uncompressed, so that the module keeps no dependency.
+package exifexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/barasher/go-exiftool"
+)
+
+// ColumnType is the type of the values of a column
+type ColumnType int
+
+// Column types, from the most specific to the most generic one
+const (
+	Boolean ColumnType = iota
+	Integer
+	Float
+	String
+	StringList
+)
+
+// String returns the name of the column type
+func (t ColumnType) String() string {
+	switch t {
+	case Boolean:
+		return "boolean"
+	case Integer:
+		return "integer"
+	case Float:
+		return "float"
+	case String:
+		return "string"
+	case StringList:
+		return "string_list"
+	default:
+		return "unknown"
+	}
+}
+
+// Column is a tag exported as a typed column
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// Schema is the list of the exported columns, SourceFile and Error being always exported first
+type Schema []Column
+
+// InferSchema returns the schema of the given tags, typed according to their values in fms. Tags
+// are resolved as with the LenientKeys option. A tag holding values of different types (e.g. a
+// number and a text) is a String column, except integers and floats that make a Float column. A
+// tag without any value is a String column.
+func InferSchema(fms []exiftool.FileMetadata, tags ...string) Schema {
+	schema := make(Schema, 0, len(tags))
+	for _, tag := range tags {
+		t, found := String, false
+		for _, fm := range fms {
+			v, ok := fm.Lookup(tag)
+			if !ok || v == nil {
+				continue
+			}
+			vt := valueType(v)
+			switch {
+			case !found:
+				t, found = vt, true
+			case t == vt:
+			case (t == Integer && vt == Float) || (t == Float && vt == Integer):
+				t = Float
+			default:
+				t = String
+			}
+		}
+		schema = append(schema, Column{Name: tag, Type: t})
+	}
+	return schema
+}
+
+func valueType(v interface{}) ColumnType {
+	switch v := v.(type) {
+	case bool:
+		return Boolean
+	case int64, int:
+		return Integer
+	case float64:
+		if v == float64(int64(v)) {
+			return Integer
+		}
+		return Float
+	case []interface{}, []string:
+		return StringList
+	default:
+		return String
+	}
+}
+
+// Exporter writes extraction results
+type Exporter interface {
+	// Write exports the metadata of a file
+	Write(fm exiftool.FileMetadata) error
+	// Close flushes what has not been written yet, without closing the underlying writer
+	Close() error
+}
+
+// Export writes the files received from ch until it is closed, then closes the exporter
+func Export(ex Exporter, ch <-chan exiftool.FileMetadata) error {
+	for fm := range ch {
+		if err := ex.Write(fm); err != nil {
+			return err
+		}
+	}
+	return ex.Close()
+}
+
+// ndjsonExporter writes a JSON object per line
+type ndjsonExporter struct {
+	enc    *json.Encoder
+	schema Schema
+}
+
+// NewNDJSONExporter returns an exporter writing a JSON object per file, with the SourceFile and
+// Error (null when the extraction succeeded) fields, followed by the columns of the schema (null
+// when the tag is missing or its value can't be converted to the column type). When the schema
+// is nil, all the fields are written as extracted.
+// Sample :
+//   fms := et.ExtractMetadata(files...)
+//   ex := exifexport.NewNDJSONExporter(os.Stdout, exifexport.InferSchema(fms, "Model", "ISO", "GPSLatitude"))
+//   for _, fm := range fms {
+//     if err := ex.Write(fm); err != nil {
+//       ...
+//     }
+//   }
+func NewNDJSONExporter(w io.Writer, schema Schema) Exporter {
+	return &ndjsonExporter{enc: json.NewEncoder(w), schema: schema}
+}
+
+func (ex *ndjsonExporter) Write(fm exiftool.FileMetadata) error {
+	if err := ex.enc.Encode(record(fm, ex.schema)); err != nil {
+		return fmt.Errorf("error while writing %v: %w", fm.File, err)
+	}
+	return nil
+}
+
+func (ex *ndjsonExporter) Close() error {
+	return nil
+}
+
+// ndjsonRecord is a JSON object whose keys are written in order
+type ndjsonRecord struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (r ndjsonRecord) MarshalJSON() ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, k := range r.keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		vb, err := json.Marshal(r.values[k])
+		if err != nil {
+			return nil, err
+		}
+		sb.Write(kb)
+		sb.WriteByte(':')
+		sb.Write(vb)
+	}
+	sb.WriteByte('}')
+	return []byte(sb.String()), nil
+}
+
+// record returns the exported object of a file
+func record(fm exiftool.FileMetadata, schema Schema) interface{} {
+	var fileErr interface{}
+	if fm.Err != nil {
+		fileErr = fm.Err.Error()
+	}
+
+	if schema == nil {
+		r := make(map[string]interface{}, len(fm.Fields)+2)
+		for k, v := range fm.Fields {
+			r[k] = v
+		}
+		r["SourceFile"] = fm.File
+		r["Error"] = fileErr
+		return r
+	}
+
+	r := ndjsonRecord{
+		keys:   []string{"SourceFile", "Error"},
+		values: map[string]interface{}{"SourceFile": fm.File, "Error": fileErr},
+	}
+	for _, c := range schema {
+		r.keys = append(r.keys, c.Name)
+		if v, found := fm.Lookup(c.Name); found && v != nil {
+			r.values[c.Name] = convert(v, c.Type)
+		} else {
+			r.values[c.Name] = nil
+		}
+	}
+	return r
+}
+
+// convert converts a value to a column type, nil if it is not possible
+func convert(v interface{}, t ColumnType) interface{} {
+	switch t {
+	case Boolean:
+		b, err := strconv.ParseBool(text(v))
+		if err != nil {
+			return nil
+		}
+		return b
+	case Integer:
+		i, err := strconv.ParseInt(text(v), 10, 64)
+		if err != nil {
+			return nil
+		}
+		return i
+	case Float:
+		f, err := strconv.ParseFloat(text(v), 64)
+		if err != nil {
+			return nil
+		}
+		return f
+	case StringList:
+		switch l := v.(type) {
+		case []string:
+			return l
+		case []interface{}:
+			res := make([]string, len(l))
+			for i, e := range l {
+				res[i] = text(e)
+			}
+			return res
+		default:
+			return []string{text(v)}
+		}
+	default:
+		return text(v)
+	}
+}
+
+// text returns the text form of a value
+func text(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, e := range v {
+			parts[i] = text(e)
+		}
+		return strings.Join(parts, ", ")
+	case []string:
+		return strings.Join(v, ", ")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
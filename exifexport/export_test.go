@@ -0,0 +1,86 @@
+package exifexport
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/barasher/go-exiftool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func extracted(file string, fields map[string]interface{}) exiftool.FileMetadata {
+	fm := exiftool.NewFileMetadata(file)
+	fm.Fields = fields
+	return fm
+}
+
+func testFiles() []exiftool.FileMetadata {
+	failed := exiftool.NewFileMetadata("failed.jpg")
+	failed.Err = exiftool.ErrNotExist
+	return []exiftool.FileMetadata{
+		extracted("a.jpg", map[string]interface{}{
+			"Model": "HERO4 Silver", "ISO": float64(800), "ExposureCompensation": float64(0),
+			"Flash": true, "Keywords": []interface{}{"beach", "sea"}, "Rating": float64(3),
+		}),
+		extracted("b.jpg", map[string]interface{}{
+			"Model": "Pixel 3", "ISO": float64(100), "ExposureCompensation": 0.3,
+			"Keywords": "beach", "Rating": "n/a",
+		}),
+		failed,
+	}
+}
+
+func TestInferSchema(t *testing.T) {
+	exp := Schema{
+		{"Model", String},
+		{"ISO", Integer},
+		{"ExposureCompensation", Float},
+		{"Flash", Boolean},
+		{"Keywords", String},
+		{"Rating", String},
+		{"Missing", String},
+	}
+	assert.Equal(t, exp, InferSchema(testFiles(), "Model", "ISO", "ExposureCompensation", "Flash", "Keywords", "Rating", "Missing"))
+	assert.Equal(t, Schema{{"Keywords", StringList}}, InferSchema(testFiles()[:1], "Keywords"))
+	assert.Equal(t, "string_list", StringList.String())
+}
+
+func TestNDJSONExporter(t *testing.T) {
+	var tcs = []struct {
+		tcID     string
+		inSchema Schema
+		expOut   string
+	}{
+		{
+			"schema",
+			Schema{{"Model", String}, {"ISO", Integer}, {"Flash", Boolean}, {"Keywords", StringList}, {"Rating", Float}},
+			`{"SourceFile":"a.jpg","Error":null,"Model":"HERO4 Silver","ISO":800,"Flash":true,"Keywords":["beach","sea"],"Rating":3}` + "\n" +
+				`{"SourceFile":"b.jpg","Error":null,"Model":"Pixel 3","ISO":100,"Flash":null,"Keywords":["beach"],"Rating":null}` + "\n" +
+				`{"SourceFile":"failed.jpg","Error":"file does not exist","Model":null,"ISO":null,"Flash":null,"Keywords":null,"Rating":null}` + "\n",
+		},
+		{
+			"noSchema",
+			nil,
+			`{"Error":null,"ExposureCompensation":0,"Flash":true,"ISO":800,"Keywords":["beach","sea"],"Model":"HERO4 Silver","Rating":3,"SourceFile":"a.jpg"}` + "\n" +
+				`{"Error":null,"ExposureCompensation":0.3,"ISO":100,"Keywords":"beach","Model":"Pixel 3","Rating":"n/a","SourceFile":"b.jpg"}` + "\n" +
+				`{"Error":"file does not exist","SourceFile":"failed.jpg"}` + "\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			var buf bytes.Buffer
+			ch := make(chan exiftool.FileMetadata)
+			go func() {
+				for _, fm := range testFiles() {
+					ch <- fm
+				}
+				close(ch)
+			}()
+			require.Nil(t, Export(NewNDJSONExporter(&buf, tc.inSchema), ch))
+			assert.Equal(t, tc.expOut, buf.String())
+		})
+	}
+}
@@ -0,0 +1,522 @@
+package exifexport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+
+	"github.com/barasher/go-exiftool"
+)
+
+// ErrExporterClosed is returned when writing to a closed exporter
+var ErrExporterClosed = errors.New("exporter closed")
+
+// rowGroupSize is the number of files written per Parquet row group
+var rowGroupSize = 10000
+
+var parquetMagic = []byte("PAR1")
+
+// Parquet physical types, repetition types, converted types and encodings
+const (
+	parquetBoolean   int32 = 0
+	parquetInt64     int32 = 2
+	parquetDouble    int32 = 5
+	parquetByteArray int32 = 6
+
+	parquetRequired int32 = 0
+	parquetOptional int32 = 1
+	parquetRepeated int32 = 2
+
+	parquetUTF8 int32 = 0
+	parquetList int32 = 3
+
+	parquetPlain int32 = 0
+	parquetRLE   int32 = 3
+)
+
+// parquetColumn is a leaf column, buffering the values of the current row group
+type parquetColumn struct {
+	path      []string
+	physical  int32
+	maxDef    int
+	maxRep    int
+	defs      []int
+	reps      []int
+	numValues int
+	values    bytes.Buffer
+	bools     []bool
+}
+
+// parquetChunk is the location of a column in a row group
+type parquetChunk struct {
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+type parquetRowGroup struct {
+	chunks []parquetChunk
+	size   int64
+	rows   int64
+}
+
+// parquetExporter writes a Parquet file, a row group every rowGroupSize files
+type parquetExporter struct {
+	w         io.Writer
+	offset    int64
+	schema    Schema
+	columns   []*parquetColumn
+	rows      int
+	rowGroups []parquetRowGroup
+	closed    bool
+}
+
+// NewParquetExporter returns an exporter writing a Parquet file, with the SourceFile and Error
+// (null when the extraction succeeded) columns, followed by the columns of the schema (null when
+// the tag is missing or its value can't be converted to the column type). StringList columns are
+// lists of strings. The file is complete once the exporter is closed, data being written
+// uncompressed.
+// Sample :
+//   fms := et.ExtractMetadata(files...)
+//   ex := exifexport.NewParquetExporter(f, exifexport.InferSchema(fms, "Model", "ISO", "Keywords"))
+//   for _, fm := range fms {
+//     if err := ex.Write(fm); err != nil {
+//       ...
+//     }
+//   }
+//   if err := ex.Close(); err != nil {
+//     ...
+//   }
+func NewParquetExporter(w io.Writer, schema Schema) Exporter {
+	ex := parquetExporter{
+		w:      w,
+		schema: schema,
+		columns: []*parquetColumn{
+			{path: []string{"SourceFile"}, physical: parquetByteArray},
+			{path: []string{"Error"}, physical: parquetByteArray, maxDef: 1},
+		},
+	}
+	for _, c := range schema {
+		col := parquetColumn{path: []string{c.Name}, maxDef: 1}
+		switch c.Type {
+		case Boolean:
+			col.physical = parquetBoolean
+		case Integer:
+			col.physical = parquetInt64
+		case Float:
+			col.physical = parquetDouble
+		case StringList:
+			col.path = append(col.path, "list", "element")
+			col.physical = parquetByteArray
+			col.maxDef, col.maxRep = 2, 1
+		default:
+			col.physical = parquetByteArray
+		}
+		ex.columns = append(ex.columns, &col)
+	}
+	return &ex
+}
+
+func (ex *parquetExporter) Write(fm exiftool.FileMetadata) error {
+	if ex.closed {
+		return ErrExporterClosed
+	}
+	var fileErr interface{}
+	if fm.Err != nil {
+		fileErr = fm.Err.Error()
+	}
+	ex.columns[0].add(fm.File)
+	ex.columns[1].add(fileErr)
+	for i, c := range ex.schema {
+		var v interface{}
+		if raw, found := fm.Lookup(c.Name); found && raw != nil {
+			v = convert(raw, c.Type)
+		}
+		ex.columns[i+2].add(v)
+	}
+	ex.rows++
+	if ex.rows == rowGroupSize {
+		if err := ex.flush(); err != nil {
+			return fmt.Errorf("error while writing %v: %w", fm.File, err)
+		}
+	}
+	return nil
+}
+
+func (ex *parquetExporter) Close() error {
+	if ex.closed {
+		return nil
+	}
+	ex.closed = true
+	if err := ex.flush(); err != nil {
+		return err
+	}
+	if ex.offset == 0 {
+		if err := ex.write(parquetMagic); err != nil {
+			return err
+		}
+	}
+	footer := ex.footer()
+	length := make([]byte, 4)
+	binary.LittleEndian.PutUint32(length, uint32(len(footer)))
+	for _, b := range [][]byte{footer, length, parquetMagic} {
+		if err := ex.write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ex *parquetExporter) write(b []byte) error {
+	n, err := ex.w.Write(b)
+	ex.offset += int64(n)
+	if err != nil {
+		return fmt.Errorf("error while writing parquet file: %w", err)
+	}
+	return nil
+}
+
+// flush writes the buffered files as a row group, each column being a single data page
+func (ex *parquetExporter) flush() error {
+	if ex.rows == 0 {
+		return nil
+	}
+	if ex.offset == 0 {
+		if err := ex.write(parquetMagic); err != nil {
+			return err
+		}
+	}
+	rg := parquetRowGroup{rows: int64(ex.rows)}
+	for _, c := range ex.columns {
+		page := c.page()
+		t := newThriftWriter()
+		t.i32(1, 0) // data page
+		t.i32(2, int32(len(page)))
+		t.i32(3, int32(len(page)))
+		t.structField(5, func() {
+			t.i32(1, int32(c.numValues))
+			t.i32(2, parquetPlain)
+			t.i32(3, parquetRLE)
+			t.i32(4, parquetRLE)
+		})
+		header := t.bytes()
+
+		chunk := parquetChunk{
+			offset:    ex.offset,
+			size:      int64(len(header) + len(page)),
+			numValues: int64(c.numValues),
+		}
+		if err := ex.write(header); err != nil {
+			return err
+		}
+		if err := ex.write(page); err != nil {
+			return err
+		}
+		rg.chunks = append(rg.chunks, chunk)
+		rg.size += chunk.size
+		c.reset()
+	}
+	ex.rowGroups = append(ex.rowGroups, rg)
+	ex.rows = 0
+	return nil
+}
+
+// footer returns the FileMetaData structure of the file
+func (ex *parquetExporter) footer() []byte {
+	var numRows int64
+	for _, rg := range ex.rowGroups {
+		numRows += rg.rows
+	}
+
+	t := newThriftWriter()
+	t.i32(1, 1)
+	elements := 1 + len(ex.columns)
+	for _, c := range ex.schema {
+		if c.Type == StringList {
+			elements += 2
+		}
+	}
+	t.listField(2, thriftStruct, elements)
+	t.structElem(func() {
+		t.binary(4, "schema")
+		t.i32(5, int32(len(ex.columns)))
+	})
+	t.structElem(func() {
+		t.i32(1, parquetByteArray)
+		t.i32(3, parquetRequired)
+		t.binary(4, "SourceFile")
+		t.i32(6, parquetUTF8)
+	})
+	t.structElem(func() {
+		t.i32(1, parquetByteArray)
+		t.i32(3, parquetOptional)
+		t.binary(4, "Error")
+		t.i32(6, parquetUTF8)
+	})
+	for i, c := range ex.schema {
+		col := ex.columns[i+2]
+		if c.Type == StringList {
+			t.structElem(func() {
+				t.i32(3, parquetOptional)
+				t.binary(4, c.Name)
+				t.i32(5, 1)
+				t.i32(6, parquetList)
+			})
+			t.structElem(func() {
+				t.i32(3, parquetRepeated)
+				t.binary(4, "list")
+				t.i32(5, 1)
+			})
+			t.structElem(func() {
+				t.i32(1, parquetByteArray)
+				t.i32(3, parquetRequired)
+				t.binary(4, "element")
+				t.i32(6, parquetUTF8)
+			})
+			continue
+		}
+		t.structElem(func() {
+			t.i32(1, col.physical)
+			t.i32(3, parquetOptional)
+			t.binary(4, c.Name)
+			if col.physical == parquetByteArray {
+				t.i32(6, parquetUTF8)
+			}
+		})
+	}
+	t.i64(3, numRows)
+	t.listField(4, thriftStruct, len(ex.rowGroups))
+	for _, rg := range ex.rowGroups {
+		t.structElem(func() {
+			t.listField(1, thriftStruct, len(rg.chunks))
+			for i, chunk := range rg.chunks {
+				col := ex.columns[i]
+				t.structElem(func() {
+					t.i64(2, chunk.offset)
+					t.structField(3, func() {
+						t.i32(1, col.physical)
+						t.listField(2, thriftI32, 2)
+						t.i32Elem(parquetPlain)
+						t.i32Elem(parquetRLE)
+						t.listField(3, thriftBinary, len(col.path))
+						for _, p := range col.path {
+							t.binaryElem(p)
+						}
+						t.i32(4, 0) // uncompressed
+						t.i64(5, chunk.numValues)
+						t.i64(6, chunk.size)
+						t.i64(7, chunk.size)
+						t.i64(9, chunk.offset)
+					})
+				})
+			}
+			t.i64(2, rg.size)
+			t.i64(3, rg.rows)
+		})
+	}
+	return t.bytes()
+}
+
+// add appends the value of a file, nil for null values
+func (c *parquetColumn) add(v interface{}) {
+	if c.maxRep > 0 {
+		l, ok := v.([]string)
+		switch {
+		case !ok:
+			c.level(0, 0)
+		case len(l) == 0:
+			c.level(0, 1)
+		default:
+			for i, e := range l {
+				rep := 1
+				if i == 0 {
+					rep = 0
+				}
+				c.level(rep, 2)
+				c.value(e)
+			}
+		}
+		return
+	}
+	if v == nil {
+		c.level(0, 0)
+		return
+	}
+	c.level(0, c.maxDef)
+	c.value(v)
+}
+
+func (c *parquetColumn) level(rep, def int) {
+	c.numValues++
+	if c.maxRep > 0 {
+		c.reps = append(c.reps, rep)
+	}
+	if c.maxDef > 0 {
+		c.defs = append(c.defs, def)
+	}
+}
+
+// value appends a value with the plain encoding
+func (c *parquetColumn) value(v interface{}) {
+	b := make([]byte, 8)
+	switch v := v.(type) {
+	case bool:
+		c.bools = append(c.bools, v)
+	case int64:
+		binary.LittleEndian.PutUint64(b, uint64(v))
+		c.values.Write(b)
+	case float64:
+		binary.LittleEndian.PutUint64(b, math.Float64bits(v))
+		c.values.Write(b)
+	case string:
+		binary.LittleEndian.PutUint32(b, uint32(len(v)))
+		c.values.Write(b[:4])
+		c.values.WriteString(v)
+	}
+}
+
+// page returns the content of the data page of the column
+func (c *parquetColumn) page() []byte {
+	var page bytes.Buffer
+	if c.maxRep > 0 {
+		page.Write(encodeLevels(c.reps, c.maxRep))
+	}
+	if c.maxDef > 0 {
+		page.Write(encodeLevels(c.defs, c.maxDef))
+	}
+	if c.physical == parquetBoolean {
+		packed := make([]byte, (len(c.bools)+7)/8)
+		for i, b := range c.bools {
+			if b {
+				packed[i/8] |= 1 << uint(i%8)
+			}
+		}
+		page.Write(packed)
+	}
+	page.Write(c.values.Bytes())
+	return page.Bytes()
+}
+
+func (c *parquetColumn) reset() {
+	c.defs, c.reps, c.bools = c.defs[:0], c.reps[:0], c.bools[:0]
+	c.numValues = 0
+	c.values.Reset()
+}
+
+// encodeLevels encodes levels with runs of the RLE/bit-packing hybrid encoding, prefixed by their
+// length
+func encodeLevels(levels []int, maxLevel int) []byte {
+	width := (bits.Len(uint(maxLevel)) + 7) / 8
+	buf := make([]byte, 4, 4+len(levels))
+	varint := make([]byte, binary.MaxVarintLen64)
+	for i := 0; i < len(levels); {
+		j := i + 1
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		n := binary.PutUvarint(varint, uint64(j-i)<<1)
+		buf = append(buf, varint[:n]...)
+		for k := 0; k < width; k++ {
+			buf = append(buf, byte(levels[i]>>(8*uint(k))))
+		}
+		i = j
+	}
+	binary.LittleEndian.PutUint32(buf, uint32(len(buf)-4))
+	return buf
+}
+
+// Thrift compact protocol types
+const (
+	thriftI32    byte = 5
+	thriftI64    byte = 6
+	thriftBinary byte = 8
+	thriftList   byte = 9
+	thriftStruct byte = 12
+)
+
+// thriftWriter encodes the Parquet metadata structures with the Thrift compact protocol
+type thriftWriter struct {
+	buf  bytes.Buffer
+	last []int16 // last field ID of each open structure
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{last: []int16{0}}
+}
+
+// bytes closes the top-level structure and returns the encoded bytes
+func (t *thriftWriter) bytes() []byte {
+	t.buf.WriteByte(0)
+	return t.buf.Bytes()
+}
+
+func (t *thriftWriter) uvarint(v uint64) {
+	b := make([]byte, binary.MaxVarintLen64)
+	t.buf.Write(b[:binary.PutUvarint(b, v)])
+}
+
+func (t *thriftWriter) zigzag(v int64) {
+	t.uvarint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	last := &t.last[len(t.last)-1]
+	if d := id - *last; d > 0 && d <= 15 {
+		t.buf.WriteByte(byte(d)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.zigzag(int64(id))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.zigzag(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.zigzag(v)
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.binaryElem(s)
+}
+
+func (t *thriftWriter) structField(id int16, fields func()) {
+	t.field(id, thriftStruct)
+	t.structElem(fields)
+}
+
+// listField writes the header of a list, whose elements are then written with the *Elem methods
+func (t *thriftWriter) listField(id int16, elemType byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elemType)
+		return
+	}
+	t.buf.WriteByte(0xf0 | elemType)
+	t.uvarint(uint64(size))
+}
+
+func (t *thriftWriter) i32Elem(v int32) {
+	t.zigzag(int64(v))
+}
+
+func (t *thriftWriter) binaryElem(s string) {
+	t.uvarint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftWriter) structElem(fields func()) {
+	t.last = append(t.last, 0)
+	fields()
+	t.buf.WriteByte(0)
+	t.last = t.last[:len(t.last)-1]
+}
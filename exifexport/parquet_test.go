@@ -0,0 +1,246 @@
+package exifexport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/barasher/go-exiftool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readThrift decodes a Thrift compact structure, fields being indexed by their ID
+func readThrift(t *testing.T, r *bytes.Reader) map[int16]interface{} {
+	res := map[int16]interface{}{}
+	var last int16
+	for {
+		b, err := r.ReadByte()
+		require.Nil(t, err)
+		if b == 0 {
+			return res
+		}
+		id := last + int16(b>>4)
+		if b>>4 == 0 {
+			id = int16(readZigzag(t, r))
+		}
+		last = id
+		res[id] = readThriftValue(t, r, b&0x0f)
+	}
+}
+
+func readThriftValue(t *testing.T, r *bytes.Reader, typ byte) interface{} {
+	switch typ {
+	case thriftI32, thriftI64:
+		return readZigzag(t, r)
+	case thriftBinary:
+		n, err := binary.ReadUvarint(r)
+		require.Nil(t, err)
+		b := make([]byte, n)
+		_, err = r.Read(b)
+		require.Nil(t, err)
+		return string(b)
+	case thriftList:
+		h, err := r.ReadByte()
+		require.Nil(t, err)
+		n := uint64(h >> 4)
+		if n == 15 {
+			n, err = binary.ReadUvarint(r)
+			require.Nil(t, err)
+		}
+		l := make([]interface{}, n)
+		for i := range l {
+			l[i] = readThriftValue(t, r, h&0x0f)
+		}
+		return l
+	case thriftStruct:
+		return readThrift(t, r)
+	}
+	require.FailNow(t, "unexpected thrift type", "%v", typ)
+	return nil
+}
+
+func readZigzag(t *testing.T, r *bytes.Reader) int64 {
+	v, err := binary.ReadUvarint(r)
+	require.Nil(t, err)
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// readLevels decodes levels written with RLE runs
+func readLevels(t *testing.T, r *bytes.Reader, maxLevel int, count int) []int {
+	var length uint32
+	require.Nil(t, binary.Read(r, binary.LittleEndian, &length))
+	var levels []int
+	for len(levels) < count {
+		h, err := binary.ReadUvarint(r)
+		require.Nil(t, err)
+		require.Equal(t, uint64(0), h&1, "bit-packed run")
+		require.True(t, maxLevel < 256)
+		v, err := r.ReadByte()
+		require.Nil(t, err)
+		for i := uint64(0); i < h>>1; i++ {
+			levels = append(levels, int(v))
+		}
+	}
+	return levels
+}
+
+// readParquet returns the schema element names and the rows of a file written by the parquet
+// exporter, lists being read as []string
+func readParquet(t *testing.T, content []byte) ([]string, [][]interface{}) {
+	require.True(t, len(content) > 12)
+	require.Equal(t, "PAR1", string(content[:4]))
+	require.Equal(t, "PAR1", string(content[len(content)-4:]))
+	length := int(binary.LittleEndian.Uint32(content[len(content)-8:]))
+	meta := readThrift(t, bytes.NewReader(content[len(content)-8-length:len(content)-8]))
+
+	var names []string
+	var leaves []map[int16]interface{}
+	for i, e := range meta[2].([]interface{}) {
+		el := e.(map[int16]interface{})
+		names = append(names, el[4].(string))
+		if _, group := el[5]; !group && i > 0 {
+			leaves = append(leaves, el)
+		}
+	}
+
+	var rows [][]interface{}
+	for _, g := range meta[4].([]interface{}) {
+		rg := g.(map[int16]interface{})
+		numRows := int(rg[3].(int64))
+		columns := make([][]interface{}, numRows)
+		for c, cc := range rg[1].([]interface{}) {
+			cm := cc.(map[int16]interface{})[3].(map[int16]interface{})
+			path := cm[3].([]interface{})
+			r := bytes.NewReader(content[cm[9].(int64):])
+			header := readThrift(t, r)
+			assert.Equal(t, header[2], header[3])
+			numValues := int(header[5].(map[int16]interface{})[1].(int64))
+			assert.Equal(t, cm[5], int64(numValues))
+
+			maxDef, maxRep := 0, 0
+			if leaves[c][3].(int64) != int64(parquetRequired) {
+				maxDef = 1
+			}
+			if len(path) == 3 {
+				maxDef, maxRep = 2, 1
+			}
+			reps := make([]int, numValues)
+			defs := make([]int, numValues)
+			if maxRep > 0 {
+				reps = readLevels(t, r, maxRep, numValues)
+			}
+			if maxDef > 0 {
+				defs = readLevels(t, r, maxDef, numValues)
+			}
+
+			var bools []byte
+			if cm[1].(int64) == int64(parquetBoolean) {
+				bools = make([]byte, r.Len())
+				r.Read(bools)
+			}
+			row, boolIndex := -1, 0
+			for i := 0; i < numValues; i++ {
+				if reps[i] == 0 {
+					row++
+				}
+				if defs[i] < maxDef {
+					if maxRep > 0 && defs[i] == 1 {
+						columns[row] = append(columns[row], []string{})
+					} else if maxRep == 0 || reps[i] == 0 {
+						columns[row] = append(columns[row], nil)
+					}
+					continue
+				}
+				var v interface{}
+				switch cm[1].(int64) {
+				case int64(parquetBoolean):
+					v = bools[boolIndex/8]&(1<<uint(boolIndex%8)) != 0
+					boolIndex++
+				case int64(parquetInt64):
+					var i int64
+					require.Nil(t, binary.Read(r, binary.LittleEndian, &i))
+					v = i
+				case int64(parquetDouble):
+					var u uint64
+					require.Nil(t, binary.Read(r, binary.LittleEndian, &u))
+					v = math.Float64frombits(u)
+				default:
+					var n uint32
+					require.Nil(t, binary.Read(r, binary.LittleEndian, &n))
+					b := make([]byte, n)
+					r.Read(b)
+					v = string(b)
+				}
+				if maxRep == 0 {
+					columns[row] = append(columns[row], v)
+				} else if reps[i] == 0 {
+					columns[row] = append(columns[row], []string{v.(string)})
+				} else {
+					l := columns[row][len(columns[row])-1].([]string)
+					columns[row][len(columns[row])-1] = append(l, v.(string))
+				}
+			}
+			assert.Equal(t, numRows-1, row, "column %v", c)
+		}
+		rows = append(rows, columns...)
+	}
+	assert.Equal(t, meta[3], int64(len(rows)))
+	return names, rows
+}
+
+func TestParquetExporter(t *testing.T) {
+	defer func(s int) { rowGroupSize = s }(rowGroupSize)
+	rowGroupSize = 2
+
+	fms := testFiles()
+	empty := extracted("c.jpg", map[string]interface{}{"Keywords": []interface{}{}})
+	fms = append(fms, empty)
+	schema := Schema{
+		{"Model", String},
+		{"ISO", Integer},
+		{"ExposureCompensation", Float},
+		{"Flash", Boolean},
+		{"Keywords", StringList},
+	}
+	var buf bytes.Buffer
+	ex := NewParquetExporter(&buf, schema)
+	for _, fm := range fms {
+		require.Nil(t, ex.Write(fm))
+	}
+	require.Nil(t, ex.Close())
+	assert.Equal(t, ErrExporterClosed, ex.Write(fms[0]))
+
+	names, rows := readParquet(t, buf.Bytes())
+	assert.Equal(t, []string{"schema", "SourceFile", "Error", "Model", "ISO", "ExposureCompensation",
+		"Flash", "Keywords", "list", "element"}, names)
+	assert.Equal(t, [][]interface{}{
+		{"a.jpg", nil, "HERO4 Silver", int64(800), float64(0), true, []string{"beach", "sea"}},
+		{"b.jpg", nil, "Pixel 3", int64(100), 0.3, nil, []string{"beach"}},
+		{"failed.jpg", exiftool.ErrNotExist.Error(), nil, nil, nil, nil, nil},
+		{"c.jpg", nil, nil, nil, nil, nil, []string{}},
+	}, rows)
+}
+
+func TestParquetExporterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	ex := NewParquetExporter(&buf, nil)
+	require.Nil(t, ex.Close())
+	names, rows := readParquet(t, buf.Bytes())
+	assert.Equal(t, []string{"schema", "SourceFile", "Error"}, names)
+	assert.Empty(t, rows)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestParquetExporterWriteError(t *testing.T) {
+	ex := NewParquetExporter(failingWriter{}, nil)
+	require.Nil(t, ex.Write(testFiles()[0]))
+	assert.NotNil(t, ex.Close())
+}
@@ -0,0 +1,372 @@
+// Package exifhttp exposes go-exiftool over HTTP, so that a metadata sidecar can be deployed
+// without writing the server glue.
+//
+// Requests :
+//   POST   multipart/form-data with a "file" part : extracts the metadata of the uploaded file
+//   POST   {"path": "a/b.jpg"}                    : extracts the metadata of a file of the root folder
+//   PATCH  {"path": "a/b.jpg", "fields": {...}}   : writes the fields (a null value clears the tag)
+//
+// Written keys are plain tag names, optionally group qualified: operations ("Keywords+",
+// "Title<"), wildcards and, unless AllowFileTags is set, the tags moving or linking the files
+// (FileName, Directory, File:*, System:*) are rejected.
+//
+// Metadata is returned in the JSON form of exiftool.FileMetadata, errors as {"error": "..."}.
+package exifhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/barasher/go-exiftool"
+)
+
+// DefaultMaxUploadSize is the default maximum size of the uploaded files
+const DefaultMaxUploadSize = 32 << 20
+
+// ErrPathNotAllowed is returned when a path is given while no root folder is configured
+var ErrPathNotAllowed = errors.New("paths are not allowed (see Root)")
+
+// Handler is an http.Handler extracting and writing metadata with a pool of exiftool instances
+type Handler struct {
+	created       int
+	exiftoolOpts  []func(*exiftool.Exiftool) error
+	maxUploadSize int64
+	root          string
+	fileTags      bool
+	pool          chan *exiftool.Exiftool
+}
+
+// NewHandler creates a Handler with the given number of exiftool instances, which is also the
+// maximum number of requests processed concurrently: other requests wait for an instance until
+// they are canceled. The instances have to be released with Close.
+// Sample :
+//   h, err := NewHandler(4, Root("/srv/photos"), MaxUploadSize(64<<20))
+//   http.Handle("/metadata", h)
+func NewHandler(instances int, opts ...func(*Handler) error) (*Handler, error) {
+	if instances < 1 {
+		return nil, fmt.Errorf("invalid number of instances: %v", instances)
+	}
+	h := Handler{
+		maxUploadSize: DefaultMaxUploadSize,
+	}
+	for _, opt := range opts {
+		if err := opt(&h); err != nil {
+			return nil, fmt.Errorf("error when configuring handler: %w", err)
+		}
+	}
+
+	h.pool = make(chan *exiftool.Exiftool, instances)
+	for i := 0; i < instances; i++ {
+		e, err := exiftool.NewExiftool(h.exiftoolOpts...)
+		if err != nil {
+			h.Close()
+			return nil, err
+		}
+		h.pool <- e
+		h.created++
+	}
+	return &h, nil
+}
+
+// ExiftoolOptions defines the options of the exiftool instances
+func ExiftoolOptions(opts ...func(*exiftool.Exiftool) error) func(*Handler) error {
+	return func(h *Handler) error {
+		h.exiftoolOpts = append(h.exiftoolOpts, opts...)
+		return nil
+	}
+}
+
+// MaxUploadSize defines the maximum size of the request bodies, DefaultMaxUploadSize by default
+func MaxUploadSize(n int64) func(*Handler) error {
+	return func(h *Handler) error {
+		if n <= 0 {
+			return fmt.Errorf("invalid max upload size: %v", n)
+		}
+		h.maxUploadSize = n
+		return nil
+	}
+}
+
+// Root allows the clients to extract and write the metadata of the files of a folder, paths being
+// relative to it. Without root, only uploaded files can be processed.
+func Root(dir string) func(*Handler) error {
+	return func(h *Handler) error {
+		s, err := os.Stat(dir)
+		if err != nil {
+			return fmt.Errorf("error while checking root %v: %w", dir, err)
+		}
+		if !s.IsDir() {
+			return fmt.Errorf("root %v is not a folder", dir)
+		}
+		h.root = dir
+		return nil
+	}
+}
+
+// AllowFileTags allows the clients to write the tags of the File and System groups, e.g. FileName
+// and Directory, which rename or move the files (within the root folder or not)
+func AllowFileTags() func(*Handler) error {
+	return func(h *Handler) error {
+		h.fileTags = true
+		return nil
+	}
+}
+
+// Close closes the exiftool instances, once the requests in progress are processed
+func (h *Handler) Close() error {
+	var errs []error
+	for i := 0; i < h.created; i++ {
+		if err := (<-h.pool).Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("error while closing exiftool instances: %v", errs)
+	}
+	return nil
+}
+
+type request struct {
+	Path   string                 `json:"path"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// httpError is an error with the HTTP status it has to be reported with
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e httpError) Error() string {
+	return e.err.Error()
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxUploadSize)
+
+	var fm exiftool.FileMetadata
+	var err error
+	switch r.Method {
+	case http.MethodPost:
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			fm, err = h.extractUpload(r)
+		} else {
+			fm, err = h.extractPath(r)
+		}
+	case http.MethodPatch:
+		err = h.write(r)
+		if err == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "POST, PATCH")
+		err = httpError{http.StatusMethodNotAllowed, fmt.Errorf("method %v not allowed", r.Method)}
+	}
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fm)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var he httpError
+	switch {
+	case errors.As(err, &he):
+		status = he.status
+	case errors.Is(err, exiftool.ErrNotExist):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrPathNotAllowed), errors.Is(err, exiftool.ErrTagNotAllowed):
+		status = http.StatusForbidden
+	case errors.Is(err, exiftool.ErrInvalidTagKey), errors.Is(err, exiftool.ErrInvalidTagValue),
+		errors.Is(err, exiftool.ErrInvalidArgument), errors.Is(err, exiftool.ErrNotFile):
+		status = http.StatusBadRequest
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// acquire takes an exiftool instance from the pool, the caller has to release it
+func (h *Handler) acquire(ctx context.Context) (*exiftool.Exiftool, error) {
+	select {
+	case e := <-h.pool:
+		return e, nil
+	case <-ctx.Done():
+		return nil, httpError{http.StatusServiceUnavailable, ctx.Err()}
+	}
+}
+
+func (h *Handler) release(e *exiftool.Exiftool) {
+	h.pool <- e
+}
+
+func (h *Handler) extract(ctx context.Context, file string) (exiftool.FileMetadata, error) {
+	e, err := h.acquire(ctx)
+	if err != nil {
+		return exiftool.FileMetadata{}, err
+	}
+	defer h.release(e)
+	fm := e.ExtractMetadataContext(ctx, file)[0]
+	return fm, fm.Err
+}
+
+func (h *Handler) extractUpload(r *http.Request) (exiftool.FileMetadata, error) {
+	part, header, err := r.FormFile("file")
+	if err != nil {
+		return exiftool.FileMetadata{}, requestError(err)
+	}
+	defer part.Close()
+	defer r.MultipartForm.RemoveAll()
+
+	tmp, err := ioutil.TempFile("", "exifhttp-*"+filepath.Ext(header.Filename))
+	if err != nil {
+		return exiftool.FileMetadata{}, fmt.Errorf("error while creating temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, part)
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return exiftool.FileMetadata{}, fmt.Errorf("error while storing uploaded file: %w", err)
+	}
+
+	fm, err := h.extract(r.Context(), tmp.Name())
+	fm.File = header.Filename
+	return fm, err
+}
+
+func (h *Handler) extractPath(r *http.Request) (exiftool.FileMetadata, error) {
+	req, err := decodeRequest(r)
+	if err != nil {
+		return exiftool.FileMetadata{}, err
+	}
+	file, err := h.resolve(req.Path)
+	if err != nil {
+		return exiftool.FileMetadata{}, err
+	}
+	fm, err := h.extract(r.Context(), file)
+	fm.File = req.Path
+	return fm, err
+}
+
+func (h *Handler) write(r *http.Request) error {
+	req, err := decodeRequest(r)
+	if err != nil {
+		return err
+	}
+	file, err := h.resolve(req.Path)
+	if err != nil {
+		return err
+	}
+	fm := exiftool.NewFileMetadata(file)
+	for k, v := range req.Fields {
+		if err := h.checkKey(k); err != nil {
+			return err
+		}
+		if err := setField(fm, k, v); err != nil {
+			return err
+		}
+	}
+
+	e, err := h.acquire(r.Context())
+	if err != nil {
+		return err
+	}
+	defer h.release(e)
+	fms := []exiftool.FileMetadata{fm}
+	e.WriteMetadataContext(r.Context(), fms)
+	return fms[0].Err
+}
+
+// fileTags are the pseudo tags of the File and System groups moving or linking files
+var fileTags = map[string]bool{"filename": true, "directory": true, "hardlink": true, "symlink": true, "testname": true}
+
+// checkKey checks that a key written by a client is a plain tag: operations would make exiftool
+// read server files ("Title<") and wildcards would match any tag
+func (h *Handler) checkKey(k string) error {
+	if strings.ContainsAny(k, "*?") || strings.HasSuffix(k, "+") || strings.HasSuffix(k, "-") || strings.HasSuffix(k, "<") {
+		return fmt.Errorf("%w: %q", exiftool.ErrInvalidTagKey, k)
+	}
+	if h.fileTags {
+		return nil
+	}
+	group, tag := exiftool.SplitTagKey(strings.ToLower(k))
+	if fileTags[strings.TrimSuffix(tag, "#")] {
+		return fmt.Errorf("%w: %v", exiftool.ErrTagNotAllowed, k)
+	}
+	for _, g := range strings.Split(group, ":") {
+		if g = strings.TrimLeft(g, "0123456789"); g == "file" || g == "system" {
+			return fmt.Errorf("%w: %v", exiftool.ErrTagNotAllowed, k)
+		}
+	}
+	return nil
+}
+
+// setField sets a field decoded from JSON
+func setField(fm exiftool.FileMetadata, k string, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		fm.Clear(k)
+	case string:
+		fm.SetString(k, v)
+	case float64:
+		fm.SetFloat(k, v)
+	case bool:
+		fm.SetString(k, fmt.Sprint(v))
+	case map[string]interface{}:
+		fm.SetStruct(k, v)
+	case []interface{}:
+		values := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return httpError{http.StatusBadRequest, fmt.Errorf("%w: list %v can only contain strings", exiftool.ErrInvalidTagValue, k)}
+			}
+			values[i] = s
+		}
+		fm.SetStrings(k, values)
+	}
+	return nil
+}
+
+func decodeRequest(r *http.Request) (request, error) {
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return req, requestError(err)
+	}
+	if req.Path == "" {
+		return req, httpError{http.StatusBadRequest, errors.New("path is missing")}
+	}
+	return req, nil
+}
+
+// requestError reports errors reading the body, that may be caused by its size
+func requestError(err error) error {
+	if strings.Contains(err.Error(), "request body too large") {
+		return httpError{http.StatusRequestEntityTooLarge, err}
+	}
+	return httpError{http.StatusBadRequest, err}
+}
+
+// resolve returns the file matching a path relative to the root, which can't be escaped
+func (h *Handler) resolve(p string) (string, error) {
+	if h.root == "" {
+		return "", ErrPathNotAllowed
+	}
+	return filepath.Join(h.root, filepath.FromSlash(path.Clean("/"+filepath.ToSlash(p)))), nil
+}
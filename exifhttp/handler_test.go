@@ -0,0 +1,198 @@
+package exifhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/barasher/go-exiftool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFile = "../testdata/20190404_131804.jpg"
+
+func newTestHandler(t *testing.T, opts ...func(*Handler) error) *Handler {
+	h, err := NewHandler(1, opts...)
+	require.Nil(t, err)
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+func serve(h http.Handler, method string, contentType string, body []byte) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "/", bytes.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func uploadBody(t *testing.T, name string, content []byte) ([]byte, string) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fw, err := mw.CreateFormFile("file", name)
+	require.Nil(t, err)
+	_, err = fw.Write(content)
+	require.Nil(t, err)
+	require.Nil(t, mw.Close())
+	return buf.Bytes(), mw.FormDataContentType()
+}
+
+func TestNewHandlerErrors(t *testing.T) {
+	var tcs = []struct {
+		tcID        string
+		inInstances int
+		inOpts      []func(*Handler) error
+	}{
+		{"noInstance", 0, nil},
+		{"invalidMaxUploadSize", 1, []func(*Handler) error{MaxUploadSize(0)}},
+		{"missingRoot", 1, []func(*Handler) error{Root("./doesNotExist")}},
+		{"fileRoot", 1, []func(*Handler) error{Root(testFile)}},
+		{"invalidExiftoolOption", 1, []func(*Handler) error{ExiftoolOptions(exiftool.SetExiftoolBinaryPath("./doesNotExist"))}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			_, err := NewHandler(tc.inInstances, tc.inOpts...)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestExtractUpload(t *testing.T) {
+	content, err := ioutil.ReadFile(testFile)
+	require.Nil(t, err)
+	body, contentType := uploadBody(t, "photo.jpg", content)
+
+	w := serve(newTestHandler(t), http.MethodPost, contentType, body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var fm exiftool.FileMetadata
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &fm))
+	assert.Equal(t, "photo.jpg", fm.File)
+	assert.Nil(t, fm.Err)
+	assert.NotEmpty(t, fm.Fields)
+}
+
+func TestExtractUploadTooLarge(t *testing.T) {
+	body, contentType := uploadBody(t, "photo.jpg", make([]byte, 2048))
+	w := serve(newTestHandler(t, MaxUploadSize(1024)), http.MethodPost, contentType, body)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+}
+
+func TestExtractPath(t *testing.T) {
+	h := newTestHandler(t, Root("../testdata"))
+
+	var tcs = []struct {
+		tcID      string
+		inBody    string
+		expStatus int
+	}{
+		{"ok", `{"path": "20190404_131804.jpg"}`, http.StatusOK},
+		{"escapeRoot", `{"path": "../testdata/20190404_131804.jpg"}`, http.StatusNotFound},
+		{"notExist", `{"path": "nonExisting.jpg"}`, http.StatusNotFound},
+		{"noPath", `{}`, http.StatusBadRequest},
+		{"invalidJSON", `{`, http.StatusBadRequest},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			w := serve(h, http.MethodPost, "application/json", []byte(tc.inBody))
+			assert.Equal(t, tc.expStatus, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestPathWithoutRoot(t *testing.T) {
+	h := newTestHandler(t)
+	w := serve(h, http.MethodPost, "application/json", []byte(`{"path": "a.jpg"}`))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serve(h, http.MethodPatch, "application/json", []byte(`{"path": "a.jpg", "fields": {"Artist": "a"}}`))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	w := serve(newTestHandler(t), http.MethodGet, "", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "POST, PATCH", w.Header().Get("Allow"))
+}
+
+func TestPatchInvalidList(t *testing.T) {
+	h := newTestHandler(t, Root("../testdata"))
+	w := serve(h, http.MethodPatch, "application/json", []byte(`{"path": "20190404_131804.jpg", "fields": {"Keywords": [1]}}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
+
+func TestPatchRejectedKeys(t *testing.T) {
+	var tcs = []struct {
+		tcID      string
+		inFields  string
+		expStatus int
+	}{
+		{"readFile", `{"Title<": "/etc/passwd"}`, http.StatusBadRequest},
+		{"addToList", `{"Keywords+": "k"}`, http.StatusBadRequest},
+		{"removeFromList", `{"Keywords-": "k"}`, http.StatusBadRequest},
+		{"wildcard", `{"FileNam?": "x.jpg"}`, http.StatusBadRequest},
+		{"wildcardGroup", `{"Sys*:File*": "x.jpg"}`, http.StatusBadRequest},
+		{"fileName", `{"FileName": "../../x.jpg"}`, http.StatusForbidden},
+		{"fileNameCase", `{"filename": "../../x.jpg"}`, http.StatusForbidden},
+		{"directory", `{"Directory": "/tmp"}`, http.StatusForbidden},
+		{"symLink", `{"SymLink": "/tmp/link"}`, http.StatusForbidden},
+		{"fileGroup", `{"File:FileModifyDate": "2019:04:04 13:18:03"}`, http.StatusForbidden},
+		{"systemGroup", `{"System:FilePermissions": "777"}`, http.StatusForbidden},
+		{"numberedGroup", `{"1System:FileName": "x.jpg"}`, http.StatusForbidden},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			h := newTestHandler(t, Root("../testdata"))
+			w := serve(h, http.MethodPatch, "application/json",
+				[]byte(`{"path": "20190404_131804.jpg", "fields": `+tc.inFields+`}`))
+			assert.Equal(t, tc.expStatus, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestAllowFileTags(t *testing.T) {
+	h := &Handler{}
+	assert.NotNil(t, h.checkKey("Directory"))
+	require.Nil(t, AllowFileTags()(h))
+	assert.Nil(t, h.checkKey("Directory"))
+	assert.Nil(t, h.checkKey("System:FileName"))
+	assert.NotNil(t, h.checkKey("Title<"))
+}
+
+func TestPatch(t *testing.T) {
+	dir := t.TempDir()
+	content, err := ioutil.ReadFile(testFile)
+	require.Nil(t, err)
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "a.jpg"), content, 0644))
+	h := newTestHandler(t, Root(dir))
+
+	w := serve(h, http.MethodPatch, "application/json",
+		[]byte(`{"path": "a.jpg", "fields": {"Artist": "John Doe", "Keywords": ["k1", "k2"], "Make": null}}`))
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	w = serve(h, http.MethodPost, "application/json", []byte(`{"path": "a.jpg"}`))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var fm exiftool.FileMetadata
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &fm))
+	assert.Equal(t, "John Doe", fm.GetStringDefault("Artist", ""))
+	_, err = fm.GetString("Make")
+	assert.NotNil(t, err)
+	assert.False(t, strings.HasPrefix(fm.File, dir))
+	_, err = os.Stat(filepath.Join(dir, "a.jpg_original"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,202 @@
+// Package exifindex stores extracted metadata in a SQLite database, so that large libraries can be
+// queried locally without running exiftool again.
+//
+// The package only relies on database/sql: the database is opened by the application with the
+// SQLite driver of its choice (e.g. github.com/mattn/go-sqlite3 or modernc.org/sqlite), the
+// module not depending on any of them. The tests against SQLite are in the exifindex/sqlitetest
+// module.
+//
+// The schema is stable and created if needed by New:
+//   files      (id INTEGER PRIMARY KEY, path TEXT UNIQUE, error TEXT)
+//   tags       (id INTEGER PRIMARY KEY, name TEXT UNIQUE)
+//   tag_values (file_id, tag_id, value TEXT), one row per file and tag
+// Values are stored as text: strings as is, other values JSON encoded (e.g. 72, true or
+// ["beach","sea"]).
+// Sample query:
+//   SELECT f.path FROM files f JOIN tag_values v ON v.file_id = f.id JOIN tags t ON t.id = v.tag_id
+//   WHERE t.name = 'Model' AND v.value = 'Pixel 3'
+package exifindex
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/barasher/go-exiftool"
+)
+
+// DefaultBatchSize is the default number of files indexed per transaction by IndexStream
+const DefaultBatchSize = 100
+
+// schema are the statements creating the tables
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS files (id INTEGER PRIMARY KEY, path TEXT NOT NULL UNIQUE, error TEXT)`,
+	`CREATE TABLE IF NOT EXISTS tags (id INTEGER PRIMARY KEY, name TEXT NOT NULL UNIQUE)`,
+	`CREATE TABLE IF NOT EXISTS tag_values (file_id INTEGER NOT NULL REFERENCES files(id), ` +
+		`tag_id INTEGER NOT NULL REFERENCES tags(id), value TEXT, PRIMARY KEY (file_id, tag_id))`,
+	`CREATE INDEX IF NOT EXISTS tag_values_by_tag ON tag_values (tag_id, value)`,
+}
+
+// Indexer stores extracted metadata in a database
+type Indexer struct {
+	db     *sql.DB
+	lock   sync.Mutex
+	tagIDs map[string]int64
+}
+
+// New creates an indexer storing metadata in db, creating the tables if needed
+// Sample :
+//   db, err := sql.Open("sqlite3", "library.db")
+//   idx, err := exifindex.New(db)
+//   err = idx.Index(et.ExtractMetadata(files...)...)
+func New(db *sql.DB) (*Indexer, error) {
+	for _, s := range schema {
+		if _, err := db.Exec(s); err != nil {
+			return nil, fmt.Errorf("error while creating schema: %w", err)
+		}
+	}
+	return &Indexer{db: db, tagIDs: make(map[string]int64)}, nil
+}
+
+// Index stores the metadata of files in a single transaction. Files already indexed are updated:
+// their previous values are replaced. The error of the files whose extraction failed is stored
+// in files.error, without any value.
+func (i *Indexer) Index(fms ...exiftool.FileMetadata) error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	tx, err := i.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	// tag ids created by a transaction that is rolled back are forgotten
+	created := make(map[string]bool)
+	for _, fm := range fms {
+		if err := i.index(tx, fm, created); err != nil {
+			tx.Rollback()
+			for name := range created {
+				delete(i.tagIDs, name)
+			}
+			return fmt.Errorf("error while indexing %v: %w", fm.File, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		for name := range created {
+			delete(i.tagIDs, name)
+		}
+		return fmt.Errorf("error while committing transaction: %w", err)
+	}
+	return nil
+}
+
+// IndexStream indexes the files received from ch until it is closed, batchSize files per
+// transaction (DefaultBatchSize if not positive), e.g. the files reported by exifwatch. When a
+// batch can't be indexed, the following files are received without being indexed, so that the
+// sender isn't blocked, and the error is returned once ch is closed.
+func (i *Indexer) IndexStream(ch <-chan exiftool.FileMetadata, batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	batch := make([]exiftool.FileMetadata, 0, batchSize)
+	for fm := range ch {
+		batch = append(batch, fm)
+		if len(batch) == batchSize {
+			if err := i.Index(batch...); err != nil {
+				for range ch {
+				}
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		return i.Index(batch...)
+	}
+	return nil
+}
+
+func (i *Indexer) index(tx *sql.Tx, fm exiftool.FileMetadata, created map[string]bool) error {
+	var fileErr interface{}
+	if fm.Err != nil {
+		fileErr = fm.Err.Error()
+	}
+	var fileID int64
+	err := tx.QueryRow(`SELECT id FROM files WHERE path = ?`, fm.File).Scan(&fileID)
+	switch {
+	case err == sql.ErrNoRows:
+		res, err := tx.Exec(`INSERT INTO files (path, error) VALUES (?, ?)`, fm.File, fileErr)
+		if err != nil {
+			return err
+		}
+		if fileID, err = res.LastInsertId(); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		if _, err := tx.Exec(`UPDATE files SET error = ? WHERE id = ?`, fileErr, fileID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM tag_values WHERE file_id = ?`, fileID); err != nil {
+			return err
+		}
+	}
+
+	if fm.Err != nil {
+		return nil
+	}
+	for name, v := range fm.Fields {
+		tagID, err := i.tagID(tx, name, created)
+		if err != nil {
+			return err
+		}
+		value, err := textValue(v)
+		if err != nil {
+			return fmt.Errorf("error while encoding %v: %w", name, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO tag_values (file_id, tag_id, value) VALUES (?, ?, ?)`, fileID, tagID, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagID returns the id of a tag, creating it if needed
+func (i *Indexer) tagID(tx *sql.Tx, name string, created map[string]bool) (int64, error) {
+	if id, found := i.tagIDs[name]; found {
+		return id, nil
+	}
+	var id int64
+	err := tx.QueryRow(`SELECT id FROM tags WHERE name = ?`, name).Scan(&id)
+	if err == sql.ErrNoRows {
+		res, err := tx.Exec(`INSERT INTO tags (name) VALUES (?)`, name)
+		if err != nil {
+			return 0, err
+		}
+		if id, err = res.LastInsertId(); err != nil {
+			return 0, err
+		}
+		created[name] = true
+	} else if err != nil {
+		return 0, err
+	}
+	i.tagIDs[name] = id
+	return id, nil
+}
+
+// textValue returns the text stored for a value
+func textValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return v, nil
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	}
+}
@@ -0,0 +1,297 @@
+package exifindex
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/barasher/go-exiftool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memDB is an in-memory database understanding the statements of the indexer, no SQLite driver
+// being a dependency of the module
+type memDB struct {
+	lock    sync.Mutex
+	files   map[string]int64
+	errors  map[int64]interface{}
+	tags    map[string]int64
+	values  map[[2]int64]interface{}
+	schema  int
+	failOn  string
+	nextID  int64
+	pending []func()
+}
+
+func newMemDB() *memDB {
+	return &memDB{files: map[string]int64{}, errors: map[int64]interface{}{}, tags: map[string]int64{}, values: map[[2]int64]interface{}{}}
+}
+
+var (
+	memDBs     = map[string]*memDB{}
+	memDBsLock sync.Mutex
+)
+
+func init() {
+	sql.Register("exifindex-mem", memDriver{})
+}
+
+type memDriver struct{}
+
+func (memDriver) Open(name string) (driver.Conn, error) {
+	memDBsLock.Lock()
+	defer memDBsLock.Unlock()
+	return &memConn{db: memDBs[name]}, nil
+}
+
+type memConn struct {
+	db *memDB
+}
+
+func (c *memConn) Prepare(query string) (driver.Stmt, error) {
+	return &memStmt{db: c.db, query: query}, nil
+}
+
+func (c *memConn) Close() error { return nil }
+
+func (c *memConn) Begin() (driver.Tx, error) {
+	c.db.lock.Lock()
+	c.db.pending = nil
+	c.db.lock.Unlock()
+	return &memTx{db: c.db}, nil
+}
+
+// memTx applies the statements on commit
+type memTx struct {
+	db *memDB
+}
+
+func (t *memTx) Commit() error {
+	t.db.lock.Lock()
+	defer t.db.lock.Unlock()
+	for _, f := range t.db.pending {
+		f()
+	}
+	t.db.pending = nil
+	return nil
+}
+
+func (t *memTx) Rollback() error {
+	t.db.lock.Lock()
+	defer t.db.lock.Unlock()
+	t.db.pending = nil
+	return nil
+}
+
+type memStmt struct {
+	db    *memDB
+	query string
+}
+
+func (s *memStmt) Close() error  { return nil }
+func (s *memStmt) NumInput() int { return -1 }
+
+func (s *memStmt) Exec(args []driver.Value) (driver.Result, error) {
+	db := s.db
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.failOn != "" && strings.HasPrefix(s.query, db.failOn) {
+		return nil, errors.New("failure")
+	}
+	switch {
+	case strings.HasPrefix(s.query, "CREATE"):
+		db.schema++
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(s.query, "INSERT INTO files"):
+		db.nextID++
+		id := db.nextID
+		db.pending = append(db.pending, func() {
+			db.files[args[0].(string)] = id
+			db.errors[id] = args[1]
+		})
+		return memResult(id), nil
+	case strings.HasPrefix(s.query, "UPDATE files"):
+		db.pending = append(db.pending, func() { db.errors[args[1].(int64)] = args[0] })
+		return memResult(0), nil
+	case strings.HasPrefix(s.query, "DELETE FROM tag_values"):
+		db.pending = append(db.pending, func() {
+			for k := range db.values {
+				if k[0] == args[0].(int64) {
+					delete(db.values, k)
+				}
+			}
+		})
+		return memResult(0), nil
+	case strings.HasPrefix(s.query, "INSERT INTO tags"):
+		db.nextID++
+		id := db.nextID
+		db.pending = append(db.pending, func() { db.tags[args[0].(string)] = id })
+		return memResult(id), nil
+	case strings.HasPrefix(s.query, "INSERT INTO tag_values"):
+		db.pending = append(db.pending, func() { db.values[[2]int64{args[0].(int64), args[1].(int64)}] = args[2] })
+		return memResult(0), nil
+	}
+	return nil, fmt.Errorf("unexpected statement %v", s.query)
+}
+
+func (s *memStmt) Query(args []driver.Value) (driver.Rows, error) {
+	db := s.db
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	var ids map[string]int64
+	switch {
+	case strings.HasPrefix(s.query, "SELECT id FROM files"):
+		ids = db.files
+	case strings.HasPrefix(s.query, "SELECT id FROM tags"):
+		ids = db.tags
+	default:
+		return nil, fmt.Errorf("unexpected query %v", s.query)
+	}
+	if id, found := ids[args[0].(string)]; found {
+		return &memRows{ids: []int64{id}}, nil
+	}
+	return &memRows{}, nil
+}
+
+type memResult int64
+
+func (r memResult) LastInsertId() (int64, error) { return int64(r), nil }
+func (r memResult) RowsAffected() (int64, error) { return 1, nil }
+
+type memRows struct {
+	ids []int64
+}
+
+func (r *memRows) Columns() []string { return []string{"id"} }
+func (r *memRows) Close() error      { return nil }
+
+func (r *memRows) Next(dest []driver.Value) error {
+	if len(r.ids) == 0 {
+		return io.EOF
+	}
+	dest[0], r.ids = r.ids[0], r.ids[1:]
+	return nil
+}
+
+func openMemDB(t *testing.T) (*sql.DB, *memDB) {
+	mem := newMemDB()
+	memDBsLock.Lock()
+	memDBs[t.Name()] = mem
+	memDBsLock.Unlock()
+	db, err := sql.Open("exifindex-mem", t.Name())
+	require.Nil(t, err)
+	db.SetMaxOpenConns(1)
+	return db, mem
+}
+
+// values returns the indexed values of a file, by tag name
+func (db *memDB) valuesOf(path string) map[string]interface{} {
+	res := map[string]interface{}{}
+	for name, tagID := range db.tags {
+		if v, found := db.values[[2]int64{db.files[path], tagID}]; found {
+			res[name] = v
+		}
+	}
+	return res
+}
+
+func TestIndex(t *testing.T) {
+	db, mem := openMemDB(t)
+	defer db.Close()
+
+	idx, err := New(db)
+	require.Nil(t, err)
+	assert.Equal(t, len(schema), mem.schema)
+
+	failed := exiftool.NewFileMetadata("failed.jpg")
+	failed.Err = exiftool.ErrNotExist
+	a := exiftool.NewFileMetadata("a.jpg").
+		WithString("Model", "Pixel 3").
+		WithInt("ISO", 800).
+		WithStrings("Keywords", "beach", "sea")
+	require.Nil(t, idx.Index(a, failed))
+	assert.Equal(t, map[string]interface{}{"Model": "Pixel 3", "ISO": "800", "Keywords": `["beach","sea"]`}, mem.valuesOf("a.jpg"))
+	assert.Equal(t, exiftool.ErrNotExist.Error(), mem.errors[mem.files["failed.jpg"]])
+	assert.Empty(t, mem.valuesOf("failed.jpg"))
+
+	// reindexing replaces the values
+	a = exiftool.NewFileMetadata("a.jpg").WithString("Model", "Pixel 4")
+	require.Nil(t, idx.Index(a))
+	assert.Equal(t, map[string]interface{}{"Model": "Pixel 4"}, mem.valuesOf("a.jpg"))
+	assert.Len(t, mem.files, 2)
+}
+
+func TestIndexRollback(t *testing.T) {
+	db, mem := openMemDB(t)
+	defer db.Close()
+
+	idx, err := New(db)
+	require.Nil(t, err)
+
+	mem.failOn = "INSERT INTO tag_values"
+	assert.NotNil(t, idx.Index(exiftool.NewFileMetadata("a.jpg").WithString("Model", "Pixel 3")))
+	assert.Empty(t, mem.files)
+	assert.Empty(t, idx.tagIDs)
+
+	mem.failOn = ""
+	require.Nil(t, idx.Index(exiftool.NewFileMetadata("a.jpg").WithString("Model", "Pixel 3")))
+	assert.Equal(t, map[string]interface{}{"Model": "Pixel 3"}, mem.valuesOf("a.jpg"))
+}
+
+func TestIndexStream(t *testing.T) {
+	db, mem := openMemDB(t)
+	defer db.Close()
+
+	idx, err := New(db)
+	require.Nil(t, err)
+
+	ch := make(chan exiftool.FileMetadata)
+	go func() {
+		for _, f := range []string{"a.jpg", "b.jpg", "c.jpg"} {
+			ch <- exiftool.NewFileMetadata(f).WithString("Model", "Pixel 3")
+		}
+		close(ch)
+	}()
+	require.Nil(t, idx.IndexStream(ch, 2))
+	assert.Len(t, mem.files, 3)
+	assert.Equal(t, map[string]interface{}{"Model": "Pixel 3"}, mem.valuesOf("c.jpg"))
+}
+
+func TestIndexStreamError(t *testing.T) {
+	db, mem := openMemDB(t)
+	defer db.Close()
+
+	idx, err := New(db)
+	require.Nil(t, err)
+
+	mem.failOn = "INSERT INTO files"
+	ch := make(chan exiftool.FileMetadata)
+	sent := make(chan struct{})
+	go func() {
+		for _, f := range []string{"a.jpg", "b.jpg", "c.jpg"} {
+			ch <- exiftool.NewFileMetadata(f)
+		}
+		close(ch)
+		close(sent)
+	}()
+	assert.NotNil(t, idx.IndexStream(ch, 1))
+	<-sent
+	assert.Empty(t, mem.files)
+}
+
+func TestNewError(t *testing.T) {
+	db, mem := openMemDB(t)
+	defer db.Close()
+
+	mem.failOn = "CREATE"
+	_, err := New(db)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,4 @@
+// Package sqlitetest tests exifindex against a real SQLite database (github.com/mattn/go-sqlite3,
+// requiring cgo). It is a separate module, so that go-exiftool doesn't depend on any SQLite
+// driver.
+package sqlitetest
@@ -0,0 +1,16 @@
+module github.com/barasher/go-exiftool/exifindex/sqlitetest
+
+go 1.19
+
+require (
+	github.com/barasher/go-exiftool v0.0.0-00010101000000-000000000000
+	github.com/mattn/go-sqlite3 v1.14.33
+	github.com/stretchr/testify v1.3.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
+
+replace github.com/barasher/go-exiftool => ../../
@@ -0,0 +1,113 @@
+package sqlitetest
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/barasher/go-exiftool"
+	"github.com/barasher/go-exiftool/exifindex"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "library.db"))
+	require.Nil(t, err)
+	return db
+}
+
+// valuesOf returns the indexed values of a file, by tag name
+func valuesOf(t *testing.T, db *sql.DB, path string) map[string]string {
+	rows, err := db.Query(`SELECT t.name, v.value FROM files f JOIN tag_values v ON v.file_id = f.id `+
+		`JOIN tags t ON t.id = v.tag_id WHERE f.path = ?`, path)
+	require.Nil(t, err)
+	defer rows.Close()
+	res := map[string]string{}
+	for rows.Next() {
+		var name, value string
+		require.Nil(t, rows.Scan(&name, &value))
+		res[name] = value
+	}
+	require.Nil(t, rows.Err())
+	return res
+}
+
+func TestIndex(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	idx, err := exifindex.New(db)
+	require.Nil(t, err)
+	// the schema is created if needed
+	_, err = exifindex.New(db)
+	require.Nil(t, err)
+
+	failed := exiftool.NewFileMetadata("failed.jpg")
+	failed.Err = exiftool.ErrNotExist
+	a := exiftool.NewFileMetadata("a.jpg").
+		WithString("Model", "Pixel 3").
+		WithInt("ISO", 800).
+		WithStrings("Keywords", "beach", "sea")
+	b := exiftool.NewFileMetadata("b.jpg").WithString("Model", "HERO4 Silver")
+	require.Nil(t, idx.Index(a, b, failed))
+	assert.Equal(t, map[string]string{"Model": "Pixel 3", "ISO": "800", "Keywords": `["beach","sea"]`}, valuesOf(t, db, "a.jpg"))
+	assert.Empty(t, valuesOf(t, db, "failed.jpg"))
+	var fileErr string
+	require.Nil(t, db.QueryRow(`SELECT error FROM files WHERE path = ?`, "failed.jpg").Scan(&fileErr))
+	assert.Equal(t, exiftool.ErrNotExist.Error(), fileErr)
+
+	// sample query of the package documentation
+	var path string
+	require.Nil(t, db.QueryRow(`SELECT f.path FROM files f JOIN tag_values v ON v.file_id = f.id `+
+		`JOIN tags t ON t.id = v.tag_id WHERE t.name = 'Model' AND v.value = 'Pixel 3'`).Scan(&path))
+	assert.Equal(t, "a.jpg", path)
+
+	// reindexing replaces the values
+	require.Nil(t, idx.Index(exiftool.NewFileMetadata("a.jpg").WithString("Model", "Pixel 4")))
+	assert.Equal(t, map[string]string{"Model": "Pixel 4"}, valuesOf(t, db, "a.jpg"))
+	var count int
+	require.Nil(t, db.QueryRow(`SELECT COUNT(*) FROM files`).Scan(&count))
+	assert.Equal(t, 3, count)
+}
+
+func TestIndexStream(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	idx, err := exifindex.New(db)
+	require.Nil(t, err)
+
+	ch := make(chan exiftool.FileMetadata)
+	go func() {
+		for _, f := range []string{"a.jpg", "b.jpg", "c.jpg"} {
+			ch <- exiftool.NewFileMetadata(f).WithString("Model", "Pixel 3")
+		}
+		close(ch)
+	}()
+	require.Nil(t, idx.IndexStream(ch, 2))
+	assert.Equal(t, map[string]string{"Model": "Pixel 3"}, valuesOf(t, db, "c.jpg"))
+}
+
+func TestIndexStreamError(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	idx, err := exifindex.New(db)
+	require.Nil(t, err)
+	_, err = db.Exec(`CREATE TRIGGER no_b BEFORE INSERT ON files WHEN NEW.path = 'b.jpg' ` +
+		`BEGIN SELECT RAISE(ABORT, 'rejected'); END`)
+	require.Nil(t, err)
+
+	ch := make(chan exiftool.FileMetadata)
+	go func() {
+		for _, f := range []string{"a.jpg", "b.jpg", "c.jpg", "d.jpg"} {
+			ch <- exiftool.NewFileMetadata(f).WithString("Model", "Pixel 3")
+		}
+		close(ch)
+	}()
+	assert.NotNil(t, idx.IndexStream(ch, 2))
+	assert.Equal(t, map[string]string{}, valuesOf(t, db, "a.jpg"))
+	assert.Equal(t, map[string]string{}, valuesOf(t, db, "c.jpg"))
+}
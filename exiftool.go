@@ -3,12 +3,25 @@ package exiftool
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"crypto"
+	_ "crypto/md5" // hash implementations available to the Checksums option
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -34,79 +47,215 @@ var ErrNotFile = errors.New("can't extract metadata from folder")
 // ErrBufferTooSmall is a sentinel error that is returned when the buffer used to store Exiftool's output is too small.
 var ErrBufferTooSmall = errors.New("exiftool's buffer too small (see Buffer init option)")
 
+// ErrInvalidTagKey is a sentinel error that is returned when a field key can't be written
+var ErrInvalidTagKey = errors.New("invalid tag key")
+
+// ErrInvalidTagValue is a sentinel error that is returned when a field value can't be written
+var ErrInvalidTagValue = errors.New("invalid tag value")
+
+// ErrInvalidArgument is a sentinel error that is returned when an argument can't be sent to
+// exiftool (e.g. a file name containing a line break)
+var ErrInvalidArgument = errors.New("invalid exiftool argument")
+
+// ErrTagNotAllowed is a sentinel error that is returned when writing a tag that is not allowed
+// (see AllowWriteTags and DenyWriteTags)
+var ErrTagNotAllowed = errors.New("tag not allowed")
+
+// DecodeFunc decodes the JSON object that exiftool outputs for a single file into the given FileMetadata
+type DecodeFunc func(payload []byte, fm *FileMetadata) error
+
 // Exiftool is the exiftool utility wrapper
 type Exiftool struct {
+	// first field, to be 64-bit aligned for atomic operations on 32-bit platforms
+	waiting                  int64
 	lock                     sync.Mutex
 	stdin                    io.WriteCloser
 	stdMergedOut             io.ReadCloser
-	scanMergedOut            *bufio.Scanner
+	parser                   *OutputParser
 	bufferSet                bool
 	buffer                   []byte
 	bufferMaxSize            int
 	extraInitArgs            []string
+	apiOptions               []ApiOption
 	exiftoolBinPath          string
+	configFile               string
+	configFileSet            bool
 	cmd                      *exec.Cmd
+	process                  *processTransport
 	backupOriginal           bool
 	clearFieldsBeforeWriting bool
+	writeChangedOnly         bool
+	checksums                []crypto.Hash
+	dropBinaryPlaceholders   bool
+	preserveFieldOrder       bool
+	decodeFunc               DecodeFunc
+	xmpSidecar               bool
+	dateFormat               string
+	dateLayouts              []string
+	dryRun                   io.Writer
+	allowedWriteTags         []string
+	deniedWriteTags          []string
+	writeFromFiles           bool
+	lenientKeys              bool
+	extractConverters        []tagConverter
+	writeConverters          []tagConverter
+	trace                    io.Writer
+	metrics                  MetricsRecorder
+	tracer                   Tracer
+	beforeHooks              []BeforeHook
+	afterHooks               []AfterHook
+	stats                    stats
+	eventHandlers            []EventHandler
+	crashed                  bool
+	desynced                 bool
+	autoRestart              bool
+	expvarName               string
+	transport                Transport
+	processAttrs             processAttrs
+	args                     []string
+	version                  string
+	argFilePath              string
+	argFile                  *os.File
+}
+
+// defaultOptions are the options applied by NewExiftool before its own ones, see SetDefaultOptions
+var defaultOptions struct {
+	sync.Mutex
+	opts []func(*Exiftool) error
 }
 
-// NewExiftool instanciates a new Exiftool with configuration functions. If anything went
-// wrong, a non empty error will be returned.
+// SetDefaultOptions sets the options applied by every subsequent NewExiftool before the options it is
+// given, e.g. to enforce application wide settings from a single place. Calling it again replaces
+// the previous default options, calling it without option removes them.
+// Sample :
+//   SetDefaultOptions(Charset("filename=utf8"), Api("largefilesupport=1"))
+func SetDefaultOptions(opts ...func(*Exiftool) error) {
+	defaultOptions.Lock()
+	defer defaultOptions.Unlock()
+	defaultOptions.opts = append([]func(*Exiftool) error(nil), opts...)
+}
+
+// NewExiftool instanciates a new Exiftool with configuration functions, applied after the default
+// options (see SetDefaultOptions). If anything went wrong, a non empty error will be returned.
 func NewExiftool(opts ...func(*Exiftool) error) (*Exiftool, error) {
 	e := Exiftool{
-		exiftoolBinPath: exiftoolBinary,
+		exiftoolBinPath: defaultBinaryPath(),
 	}
 
+	defaultOptions.Lock()
+	opts = append(append([]func(*Exiftool) error(nil), defaultOptions.opts...), opts...)
+	defaultOptions.Unlock()
+
 	for _, opt := range opts {
 		if err := opt(&e); err != nil {
 			return nil, fmt.Errorf("error when configuring exiftool: %w", err)
 		}
 	}
 
-	args := append([]string(nil), initArgs...)
-	if len(e.extraInitArgs) > 0 {
+	var args []string
+	if e.configFileSet {
+		// -config has to be the first argument
+		args = append(args, "-config", e.configFile)
+	}
+	args = append(args, initArgs...)
+	extraArgs := withDefaultCharset(e.extraInitArgs, filenameCharset)
+	if len(extraArgs) > 0 {
 		args = append(args, "-common_args")
-		args = append(args, e.extraInitArgs...)
+		args = append(args, extraArgs...)
 	}
 
-	e.cmd = exec.Command(e.exiftoolBinPath, args...)
-	r, w := io.Pipe()
-	e.stdMergedOut = r
-
-	e.cmd.Stdout = w
-	e.cmd.Stderr = w
+	e.args = args
 
 	var err error
-	if e.stdin, err = e.cmd.StdinPipe(); err != nil {
-		return nil, fmt.Errorf("error when piping stdin: %w", err)
+	if e.argFilePath != "" {
+		if e.argFile, err = createArgFile(e.argFilePath, args); err != nil {
+			return nil, err
+		}
 	}
 
-	e.scanMergedOut = bufio.NewScanner(r)
-	if e.bufferSet {
-		e.scanMergedOut.Buffer(e.buffer, e.bufferMaxSize)
+	if err := e.start(); err != nil {
+		e.closeArgFile()
+		return nil, err
 	}
-	e.scanMergedOut.Split(splitReadyToken)
 
-	if err = e.cmd.Start(); err != nil {
-		return nil, fmt.Errorf("error when executing command: %w", err)
+	if err := e.checkApiOptions(e.apiOptions); err != nil {
+		e.close()
+		return nil, fmt.Errorf("error when configuring exiftool: %w", err)
 	}
 
+	e.stats.startedAt = time.Now()
+	e.emit(EventStarted, nil)
+
 	return &e, nil
 }
 
+// start starts exiftool with the arguments of the instance
+func (e *Exiftool) start() error {
+	var stdin io.WriteCloser
+	var stdout io.ReadCloser
+	var err error
+	if e.transport != nil {
+		if stdin, stdout, err = e.transport.Start(e.args); err != nil {
+			return fmt.Errorf("error when starting transport: %w", err)
+		}
+	} else {
+		p := &processTransport{path: e.exiftoolBinPath, attrs: e.processAttrs}
+		if stdin, stdout, err = p.Start(e.args); err != nil {
+			return err
+		}
+		e.cmd = p.cmd
+		e.process = p
+	}
+	e.stdin, e.stdMergedOut = stdin, stdout
+
+	var out io.Reader = e.stdMergedOut
+	if e.trace != nil {
+		t := &protocolTracer{w: e.trace}
+		e.stdin = tracedWriteCloser{Writer: io.MultiWriter(e.stdin, t.writer("> ")), Closer: e.stdin}
+		out = io.TeeReader(out, t.writer("< "))
+	}
+
+	e.parser = NewOutputParser(out)
+	if e.bufferSet {
+		e.parser.Buffer(e.buffer, e.bufferMaxSize)
+	}
+	return nil
+}
+
 // Close closes exiftool. If anything went wrong, a non empty error will be returned
 func (e *Exiftool) Close() error {
-	e.lock.Lock()
+	e.acquire()
 	defer e.lock.Unlock()
 
+	err := e.close()
+	e.emit(EventClosed, err)
+	return err
+}
+
+func (e *Exiftool) close() error {
+	errs := e.stop()
+	if err := e.closeArgFile(); err != nil {
+		errs = append(errs, fmt.Errorf("error while closing argfile: %w", err))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("error while closing exiftool: %v", errs)
+	}
+
+	return nil
+}
+
+// stop asks exiftool to exit and waits for it. The pipes are closed even if exiftool can't be
+// communicated with anymore, e.g. after a crash.
+func (e *Exiftool) stop() []error {
+	var errs []error
 	for _, v := range closeArgs {
-		_, err := fmt.Fprintln(e.stdin, v)
-		if err != nil {
-			return err
+		if _, err := fmt.Fprintln(e.stdin, v); err != nil {
+			errs = append(errs, err)
+			break
 		}
 	}
 
-	var errs []error
 	if err := e.stdMergedOut.Close(); err != nil {
 		errs = append(errs, fmt.Errorf("error while closing stdMergedOut: %w", err))
 	}
@@ -117,8 +266,12 @@ func (e *Exiftool) Close() error {
 
 	ch := make(chan struct{})
 	go func() {
-		if e.cmd != nil {
-			if err := e.cmd.Wait(); err != nil {
+		if e.transport != nil {
+			if err := e.transport.Wait(); err != nil {
+				errs = append(errs, fmt.Errorf("error while waiting for the transport to stop: %w", err))
+			}
+		} else if e.process != nil {
+			if err := e.process.Wait(); err != nil {
 				errs = append(errs, fmt.Errorf("error while waiting for exiftool to exit: %w", err))
 			}
 		}
@@ -132,23 +285,106 @@ func (e *Exiftool) Close() error {
 	case <-time.After(WaitTimeout):
 		errs = append(errs, errors.New("Timed out waiting for exiftool to exit"))
 	}
+	return errs
+}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("error while closing exiftool: %v", errs)
+// ExtractMetadata extracts metadata from files. On Windows, paths are normalized (slashes are
+// replaced with backslashes) and reserved device names are rejected with ErrInvalidPath.
+func (e *Exiftool) ExtractMetadata(files ...string) []FileMetadata {
+	return e.ExtractMetadataContext(context.Background(), files...)
+}
+
+// ExtractMetadataContext extracts metadata from files, and stops sending commands to exiftool
+// when the context is done: the remaining files then get the context's error
+func (e *Exiftool) ExtractMetadataContext(ctx context.Context, files ...string) []FileMetadata {
+	return e.extractMetadata(ctx, extractConfig{}, files)
+}
+
+// ExtractOption configures a single extraction, see ExtractMetadataWithOptions
+type ExtractOption func(*extractConfig) error
+
+type extractConfig struct {
+	args []string
+	api  []ApiOption
+}
+
+// ExtractApi defines -api values (see ApiOptions) for a single extraction, e.g. to enable
+// QuickTimeUTC for a batch of videos only
+func ExtractApi(opts ...ApiOption) ExtractOption {
+	return func(c *extractConfig) error {
+		args, err := apiArgs(opts)
+		if err != nil {
+			return err
+		}
+		c.args = append(c.args, args...)
+		c.api = append(c.api, opts...)
+		return nil
 	}
+}
 
-	return nil
+// ExtractMetadataWithOptions extracts metadata from files (see ExtractMetadataContext), the options
+// completing the configuration of the Exiftool instance for this call only. If an option is
+// invalid or not supported by the installed exiftool, no file is extracted and the Err of every
+// FileMetadata is set.
+// Sample :
+//   fms := e.ExtractMetadataWithOptions(ctx, []ExtractOption{ExtractApi(ApiOption{Name: ApiQuickTimeUTC})}, "a.mp4")
+func (e *Exiftool) ExtractMetadataWithOptions(ctx context.Context, opts []ExtractOption, files ...string) []FileMetadata {
+	var c extractConfig
+	var err error
+	for _, opt := range opts {
+		if err = opt(&c); err != nil {
+			break
+		}
+	}
+	if err == nil && len(c.api) > 0 {
+		e.acquire()
+		err = e.checkApiOptions(c.api)
+		e.lock.Unlock()
+	}
+	if err != nil {
+		fms := make([]FileMetadata, len(files))
+		for i, f := range files {
+			fms[i].File = f
+			fms[i].Err = fmt.Errorf("error when configuring extraction: %w", err)
+		}
+		return fms
+	}
+	return e.extractMetadata(ctx, c, files)
 }
 
-// ExtractMetadata extracts metadata from files
-func (e *Exiftool) ExtractMetadata(files ...string) []FileMetadata {
-	e.lock.Lock()
+func (e *Exiftool) extractMetadata(ctx context.Context, c extractConfig, files []string) []FileMetadata {
+	e.acquire()
 	defer e.lock.Unlock()
+	return e.extractFiles(ctx, c, files)
+}
 
+// extractFiles extracts metadata from files, the caller must hold the lock
+func (e *Exiftool) extractFiles(ctx context.Context, c extractConfig, files []string) []FileMetadata {
+	e.runBeforeHooks(OpExtract, files)
+	ctx, end := e.startSpan(ctx, OpExtract, len(files))
 	fms := make([]FileMetadata, len(files))
+	defer func() {
+		end(fms)
+		e.runAfterHooks(OpExtract, fms, nil)
+	}()
 
 	for i, f := range files {
 		fms[i].File = f
+		fms[i].dateFormat = e.dateFormat
+		fms[i].dateLayouts = e.dateLayouts
+		fms[i].lenientKeys = e.lenientKeys
+		fms[i].changed = make(map[string]bool)
+
+		if err := ctx.Err(); err != nil {
+			fms[i].Err = err
+			continue
+		}
+
+		f, err := normalizePath(f)
+		if err != nil {
+			fms[i].Err = err
+			continue
+		}
 
 		s, err := os.Stat(f)
 		if err != nil {
@@ -164,59 +400,116 @@ func (e *Exiftool) ExtractMetadata(files ...string) []FileMetadata {
 			continue
 		}
 
-		for _, curA := range extractArgs {
-			if _, err := fmt.Fprintln(e.stdin, curA); err != nil {
-				fms[i].Err = err
-				continue
-			}
+		var sums chan checksumsResult
+		if len(e.checksums) > 0 {
+			// buffered so that the goroutine never blocks if the result is not consumed
+			sums = make(chan checksumsResult, 1)
+			go func(f string) {
+				s, err := computeChecksums(f, e.checksums)
+				sums <- checksumsResult{sums: s, err: err}
+			}(f)
 		}
 
-		if _, err := fmt.Fprintln(e.stdin, f); err != nil {
+		resp, err := e.sendCommand(OpExtract, append(append(append([]string(nil), extractArgs...), c.args...), f))
+		if err != nil {
 			fms[i].Err = err
 			continue
 		}
-		if _, err := fmt.Fprintln(e.stdin, executeArg); err != nil {
-			fms[i].Err = err
-			continue
+
+		if e.decodeFunc != nil {
+			var payloads []json.RawMessage
+			if err := json.Unmarshal(resp, &payloads); err != nil {
+				fms[i].Err = fmt.Errorf("error during unmarshaling (%v): %w)", string(resp), err)
+				continue
+			}
+			if err := e.decodeFunc(payloads[0], &fms[i]); err != nil {
+				fms[i].Err = fmt.Errorf("error during decoding: %w", err)
+				continue
+			}
+		} else {
+			parsed, err := ParseJSONResponse(resp)
+			if err != nil {
+				fms[i].Err = err
+				continue
+			}
+			fms[i].Fields = parsed[0].Fields
 		}
 
-		scanOk := e.scanMergedOut.Scan()
-		scanErr := e.scanMergedOut.Err()
-		if scanErr != nil {
-			if scanErr == bufio.ErrTooLong {
-				fms[i].Err = ErrBufferTooSmall
+		if e.preserveFieldOrder {
+			order, err := fieldOrder(resp)
+			if err != nil {
+				fms[i].Err = fmt.Errorf("error while reading field order: %w", err)
 				continue
 			}
-			fms[i].Err = fmt.Errorf("error while reading stdMergedOut: %w", e.scanMergedOut.Err())
-			continue
+			fms[i].order = order
 		}
-		if !scanOk {
-			fms[i].Err = fmt.Errorf("error while reading stdMergedOut: EOF")
-			continue
+		if e.dropBinaryPlaceholders {
+			dropBinaryPlaceholders(fms[i].Fields)
 		}
-
-		var m []map[string]interface{}
-		if err := json.Unmarshal(e.scanMergedOut.Bytes(), &m); err != nil {
-			fms[i].Err = fmt.Errorf("error during unmarshaling (%v): %w)", string(e.scanMergedOut.Bytes()), err)
+		if err := convertFields(e.extractConverters, fms[i].Fields); err != nil {
+			fms[i].Err = err
 			continue
 		}
 
-		fms[i].Fields = m[0]
+		if sums != nil {
+			res := <-sums
+			if res.err != nil {
+				fms[i].Err = fmt.Errorf("error while computing checksums: %w", res.err)
+				continue
+			}
+			fms[i].Checksums = res.sums
+		}
 	}
 
+	e.observeFiles(OpExtract, fms)
 	return fms
 }
 
-// WriteMetadata writes the given metadata for each file.
+// WriteMetadata writes the given metadata for each file (paths being normalized like by ExtractMetadata).
 // Any errors will be saved to FileMetadata.Err
-// Note: If you're reusing an existing FileMetadata instance,
-//       you should nil the Err before passing it to WriteMetadata
-func (e *Exiftool) WriteMetadata(fileMetadata []FileMetadata) {
-	e.lock.Lock()
+// Err is reset before writing, so that FileMetadata returned by ExtractMetadata (or built with
+// NewFileMetadata) can be passed as is.
+// Write options override the configuration of the Exiftool instance for this call, and are
+// themselves overridden by the WriteOptions of each FileMetadata.
+func (e *Exiftool) WriteMetadata(fileMetadata []FileMetadata, opts ...WriteOption) {
+	e.WriteMetadataContext(context.Background(), fileMetadata, opts...)
+}
+
+// WriteMetadataContext writes the given metadata for each file (see WriteMetadata), and stops
+// sending commands to exiftool when the context is done: the Err of the remaining metadata is
+// then set to the context's error
+func (e *Exiftool) WriteMetadataContext(ctx context.Context, fileMetadata []FileMetadata, opts ...WriteOption) {
+	e.acquire()
 	defer e.lock.Unlock()
+	e.writeFiles(ctx, fileMetadata, opts...)
+}
+
+// writeFiles writes the given metadata for each file, the caller must hold the lock
+func (e *Exiftool) writeFiles(ctx context.Context, fileMetadata []FileMetadata, opts ...WriteOption) {
+	files := make([]string, len(fileMetadata))
+	for i, md := range fileMetadata {
+		files[i] = md.File
+	}
+	e.runBeforeHooks(OpWrite, files)
+	ctx, end := e.startSpan(ctx, OpWrite, len(fileMetadata))
+	defer func() {
+		end(fileMetadata)
+		e.runAfterHooks(OpWrite, fileMetadata, nil)
+	}()
 
 	for i, md := range fileMetadata {
 		fileMetadata[i].Err = nil
+		fileMetadata[i].WriteResult = nil
+		if err := ctx.Err(); err != nil {
+			fileMetadata[i].Err = err
+			continue
+		}
+		file, err := normalizePath(md.File)
+		if err != nil {
+			fileMetadata[i].Err = err
+			continue
+		}
+		md.File = file
 		if _, err := os.Stat(md.File); err != nil {
 			if os.IsNotExist(err) {
 				fileMetadata[i].Err = ErrNotExist
@@ -226,89 +519,583 @@ func (e *Exiftool) WriteMetadata(fileMetadata []FileMetadata) {
 			continue
 		}
 
-		if !e.backupOriginal {
-			if _, err := fmt.Fprintln(e.stdin, "-overwrite_original"); err != nil {
-				fileMetadata[i].Err = err
-				continue
-			}
+		args, err := e.writeArgs(md, opts...)
+		if err == errNothingToWrite {
+			continue
+		}
+		if err != nil {
+			fileMetadata[i].Err = err
+			continue
 		}
 
-		if e.clearFieldsBeforeWriting {
-			if _, err := fmt.Fprintln(e.stdin, "-All="); err != nil {
-				fileMetadata[i].Err = err
-				continue
-			}
+		if e.dryRun != nil {
+			fileMetadata[i].Err = e.dryRunWrite(md.File, args)
+			continue
 		}
 
-		for k, v := range md.Fields {
-			switch v.(type) {
-			case nil:
-				if _, err := fmt.Fprintln(e.stdin, "-"+k+"="); err != nil {
-					fileMetadata[i].Err = err
-					continue
-				}
-			default:
-				strTab, err := md.GetStrings(k)
-				if err != nil {
+		// errors have already been reported by writeArgs
+		c, _ := e.writeConfig(md, opts...)
+
+		target := md.File
+		if c.output != "" {
+			args = append(args, "-o", c.output)
+		} else if e.xmpSidecar {
+			target = sidecarPath(md.File)
+			if _, err := os.Stat(target); err != nil {
+				if !os.IsNotExist(err) {
 					fileMetadata[i].Err = err
 					continue
 				}
-				for _, str := range strTab {
-					// TODO: support writing an empty string via '^='
-					if _, err := fmt.Fprintln(e.stdin, "-"+k+"="+str); err != nil {
-						fileMetadata[i].Err = err
-						continue
-					}
-				}
+				// the sidecar is created from the original file's metadata
+				args = append(args, "-o", target)
+				target = md.File
 			}
 		}
 
-		if _, err := fmt.Fprintln(e.stdin, md.File); err != nil {
+		resp, err := e.sendCommand(OpWrite, append(args, target))
+		if err != nil {
 			fileMetadata[i].Err = err
 			continue
 		}
-		if _, err := fmt.Fprintln(e.stdin, executeArg); err != nil {
-			fileMetadata[i].Err = err
+
+		res := parseWriteResult(string(resp))
+		fileMetadata[i].WriteResult = &res
+		if err := handleWriteMetadataResponse(string(resp)); err != nil {
+			fileMetadata[i].Err = fmt.Errorf("Error writing metadata: %w", err)
 			continue
 		}
+	}
+
+	e.observeFiles(OpWrite, fileMetadata)
+}
+
+// dryRunWrite prints the arguments that would be used to write the file and validates them by
+// writing a copy of the file in a temporary folder
+func (e *Exiftool) dryRunWrite(file string, args []string) error {
+	if _, err := fmt.Fprintf(e.dryRun, "%v %q\n", file, args); err != nil {
+		return fmt.Errorf("error while printing dry run: %w", err)
+	}
+
+	tmpDir, err := ioutil.TempDir("", "go-exiftool-dryrun")
+	if err != nil {
+		return fmt.Errorf("error while creating dry run folder: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	resp, err := e.sendCommand(OpWrite, append(args, "-o", tmpDir+string(filepath.Separator), file))
+	if err != nil {
+		return err
+	}
+	if err := handleWriteMetadataResponse(string(resp)); err != nil {
+		return fmt.Errorf("Error writing metadata: %w", err)
+	}
+	return nil
+}
+
+// WriteMetadataBatch writes the fields of the given metadata (its File is ignored) to all the
+// files in a single exiftool command, which is much faster than WriteMetadata when the same tags
+// have to be written to many files. An error is returned if any of the files could not be written.
+func (e *Exiftool) WriteMetadataBatch(md FileMetadata, files ...string) error {
+	e.acquire()
+	defer e.lock.Unlock()
+
+	e.runBeforeHooks(OpWrite, files)
+	err := e.writeMetadataBatch(md, files)
+	e.runAfterHooks(OpWrite, nil, err)
+	return err
+}
+
+func (e *Exiftool) writeMetadataBatch(md FileMetadata, files []string) error {
+	if err := checkExist(files...); err != nil {
+		return err
+	}
+
+	args, err := e.writeArgs(md)
+	if err == errNothingToWrite {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if e.dryRun != nil {
+		return e.runWriteCommand(args, files)
+	}
 
-		scanOk := e.scanMergedOut.Scan()
-		scanErr := e.scanMergedOut.Err()
-		if scanErr != nil {
-			if scanErr == bufio.ErrTooLong {
-				fileMetadata[i].Err = ErrBufferTooSmall
+	if c, _ := e.writeConfig(md); c.output != "" {
+		return e.runWriteCommand(append(args, "-o", c.output), files)
+	}
+
+	var targets, missingSidecars []string
+	for _, f := range files {
+		target := f
+		if e.xmpSidecar {
+			target = sidecarPath(f)
+			if _, err := os.Stat(target); err != nil {
+				if !os.IsNotExist(err) {
+					return err
+				}
+				missingSidecars = append(missingSidecars, f)
 				continue
 			}
-			fileMetadata[i].Err = fmt.Errorf("error while reading stdMergedOut: %w", e.scanMergedOut.Err())
+		}
+		targets = append(targets, target)
+	}
+
+	if len(targets) > 0 {
+		if err := e.runWriteCommand(append([]string(nil), args...), targets); err != nil {
+			return err
+		}
+	}
+	if len(missingSidecars) > 0 {
+		// the sidecars are created from the original files' metadata
+		cmd := append(append([]string(nil), args...), "-o", "%d%f.xmp")
+		if err := e.runWriteCommand(cmd, missingSidecars); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteOption overrides, for a single call or file, how metadata is written
+type WriteOption func(*writeConfig) error
+
+type writeConfig struct {
+	backupOriginal           bool
+	clearFieldsBeforeWriting bool
+	preserveModTime          bool
+	changedOnly              bool
+	output                   string
+}
+
+// WriteBackupOriginal enables or disables the backup of the original files (see BackupOriginal)
+func WriteBackupOriginal(enabled bool) WriteOption {
+	return func(c *writeConfig) error {
+		c.backupOriginal = enabled
+		return nil
+	}
+}
+
+// WriteClearFieldsBeforeWriting enables or disables the removal of all the existing fields
+// before writing (see ClearFieldsBeforeWriting)
+func WriteClearFieldsBeforeWriting(enabled bool) WriteOption {
+	return func(c *writeConfig) error {
+		c.clearFieldsBeforeWriting = enabled
+		return nil
+	}
+}
+
+// WritePreserveModTime enables or disables the preservation of the file modification date
+// (exiftool's -P)
+func WritePreserveModTime(enabled bool) WriteOption {
+	return func(c *writeConfig) error {
+		c.preserveModTime = enabled
+		return nil
+	}
+}
+
+// WriteChangedOnly enables or disables the restriction of the written fields to the ones modified
+// with the setters (see WriteChangedFieldsOnly)
+func WriteChangedOnly(enabled bool) WriteOption {
+	return func(c *writeConfig) error {
+		c.changedOnly = enabled
+		return nil
+	}
+}
+
+// WriteOutput writes the result to a new file instead of modifying the original one (exiftool's
+// -o). The output can be a file, a directory (with a trailing separator) or contain exiftool's
+// format codes (e.g. "out/%f.xmp" to create a sidecar in another directory). Exiftool doesn't
+// overwrite existing output files.
+func WriteOutput(output string) WriteOption {
+	return func(c *writeConfig) error {
+		if output == "" {
+			return errors.New("output can't be empty")
+		}
+		c.output = output
+		return nil
+	}
+}
+
+// writeConfig returns the write configuration of the given metadata, the options of the
+// metadata being applied after the provided ones
+func (e *Exiftool) writeConfig(md FileMetadata, opts ...WriteOption) (writeConfig, error) {
+	c := writeConfig{
+		backupOriginal:           e.backupOriginal,
+		clearFieldsBeforeWriting: e.clearFieldsBeforeWriting,
+		changedOnly:              e.writeChangedOnly,
+	}
+	for _, opt := range append(append([]WriteOption(nil), opts...), md.WriteOptions...) {
+		if err := opt(&c); err != nil {
+			return c, fmt.Errorf("error when configuring write: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// errNothingToWrite is returned by writeArgs when, the unchanged fields being skipped (see
+// WriteChangedFieldsOnly), no field is left to write: no command has to be sent
+var errNothingToWrite = errors.New("nothing to write")
+
+// writeArgs returns the exiftool arguments that write the fields of the given metadata (the
+// output file excepted)
+func (e *Exiftool) writeArgs(md FileMetadata, opts ...WriteOption) ([]string, error) {
+	c, err := e.writeConfig(md, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []string
+	if !c.backupOriginal {
+		args = append(args, "-overwrite_original")
+	}
+	if c.clearFieldsBeforeWriting {
+		args = append(args, "-All=")
+	}
+	if c.preserveModTime {
+		args = append(args, "-P")
+	}
+
+	keys := make([]string, 0, len(md.Fields))
+	for k := range md.Fields {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return writeOrderKey(keys[i]) < writeOrderKey(keys[j])
+	})
+
+	var ops []writeOp
+	for _, k := range keys {
+		if c.changedOnly && md.changed != nil && !md.changed[k] {
 			continue
 		}
-		if !scanOk {
-			fileMetadata[i].Err = fmt.Errorf("error while reading stdMergedOut: EOF")
+		if !tagKeyRegexp.MatchString(k) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTagKey, k)
+		}
+		if !e.writeAllowed(k) {
+			return nil, fmt.Errorf("%w: %v", ErrTagNotAllowed, k)
+		}
+		if isFileOperation(k) {
+			path := fmt.Sprint(md.Fields[k])
+			if strings.ContainsAny(path, "\r\n\x00") {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidTagValue, k)
+			}
+			ops = append(ops, writeOp{prefix: "-" + k + "=", value: path})
 			continue
 		}
+		v, err := convertValue(e.writeConverters, k, md.Fields[k])
+		if err != nil {
+			return nil, err
+		}
+		switch v := v.(type) {
+		case nil:
+			ops = append(ops, writeOp{prefix: "-" + k + "="})
+		case time.Time:
+			str, err := e.formatDate(v)
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, writeOp{prefix: "-" + k + "=", value: str, escapable: true})
+		default:
+			items := []interface{}{v}
+			if l, ok := v.([]interface{}); ok {
+				items = l
+			}
+			for _, item := range items {
+				str := writeString(item)
+				if strings.Contains(str, "\x00") {
+					return nil, fmt.Errorf("%w: %v", ErrInvalidTagValue, k)
+				}
+				if str == "" && !isListOperation(k) {
+					// "-TAG=" deletes the tag, "-TAG^=" writes an empty value
+					ops = append(ops, writeOp{prefix: "-" + k + "^="})
+					continue
+				}
+				ops = append(ops, writeOp{prefix: "-" + k + "=", value: str, escapable: true})
+			}
+		}
+	}
 
-		if err := handleWriteMetadataResponse(e.scanMergedOut.Text()); err != nil {
-			fileMetadata[i].Err = fmt.Errorf("Error writing metadata: %w", err)
-			continue
+	if len(ops) == 0 && c.changedOnly && !c.clearFieldsBeforeWriting {
+		return nil, errNothingToWrite
+	}
+
+	// a line break would split the argument in two: values are C-escaped when required and
+	// exiftool unescapes them (-ec)
+	escape := false
+	for _, op := range ops {
+		escape = escape || (op.escapable && strings.ContainsAny(op.value, "\r\n"))
+	}
+	if escape {
+		args = append(args, "-ec")
+	}
+	for _, op := range ops {
+		v := op.value
+		if escape && op.escapable {
+			v = cEscaper.Replace(v)
+		}
+		args = append(args, op.prefix+v)
+	}
+
+	return args, nil
+}
+
+// writeAllowed checks a key against the AllowWriteTags and DenyWriteTags patterns. When a policy
+// is set, keys containing wildcards are rejected (they can't be matched against the patterns) as
+// well as file operations, unless AllowWriteFromFiles is set.
+func (e *Exiftool) writeAllowed(k string) bool {
+	if len(e.allowedWriteTags) == 0 && len(e.deniedWriteTags) == 0 {
+		return true
+	}
+	if strings.ContainsAny(k, "*?") || (isFileOperation(k) && !e.writeFromFiles) {
+		return false
+	}
+	if matchesTagPatterns(e.deniedWriteTags, k) {
+		return false
+	}
+	return len(e.allowedWriteTags) == 0 || matchesTagPatterns(e.allowedWriteTags, k)
+}
+
+// matchesTagPatterns checks a key, stripped from its operation suffix, against lower cased tag
+// patterns (see AllowWriteTags)
+func matchesTagPatterns(patterns []string, k string) bool {
+	k = strings.ToLower(strings.TrimRight(k, "+-<#"))
+	_, tag := SplitTagKey(k)
+	for _, p := range patterns {
+		target := tag
+		if strings.Contains(p, ":") {
+			target = k
+		}
+		if ok, _ := path.Match(p, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// writeOp is a tag assignment, the value being escaped when required
+type writeOp struct {
+	prefix    string
+	value     string
+	escapable bool
+}
+
+var cEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+
+// tagKeyRegexp matches the keys that can be written: an optionally group qualified tag name,
+// followed by an optional operation suffix (see AddToList, RemoveFromList, SetBinaryFromFile)
+var tagKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_\-:#*?]*[+\-<]?$`)
+
+// formatDate formats a date with the configured date format (see DateFormant), exiftool's
+// default format otherwise
+func (e *Exiftool) formatDate(t time.Time) (string, error) {
+	if e.dateFormat == "" {
+		return t.Format(exifDateLayout), nil
+	}
+	return formatStrftime(t, e.dateFormat)
+}
+
+func isListOperation(k string) bool {
+	return strings.HasSuffix(k, "+") || strings.HasSuffix(k, "-")
+}
+
+func isFileOperation(k string) bool {
+	return strings.HasSuffix(k, "<")
+}
+
+// writeOrderKey returns the key used to sort the written fields: list removals ("Keywords-") are
+// written before list additions ("Keywords+") so that a value can be removed and added back
+func writeOrderKey(k string) string {
+	switch {
+	case strings.HasSuffix(k, "-"):
+		return k[:len(k)-1] + "\x01"
+	case strings.HasSuffix(k, "+"):
+		return k[:len(k)-1] + "\x02"
+	default:
+		return k + "\x00"
+	}
+}
+
+// sendCommand sends the arguments (one per line) followed by -execute to exiftool and returns its response
+func (e *Exiftool) sendCommand(op Op, args []string) ([]byte, error) {
+	start := time.Now()
+	resp, err := e.roundTrip(args)
+	d := time.Since(start)
+	e.stats.commandDone(d, len(resp), err)
+	if e.metrics != nil {
+		e.metrics.ObserveCommand(op, d, len(resp), err)
+	}
+	return resp, err
+}
+
+func (e *Exiftool) roundTrip(args []string) ([]byte, error) {
+	for _, a := range args {
+		// arguments are line separated
+		if strings.ContainsAny(a, "\r\n") {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidArgument, a)
 		}
 	}
+	if e.autoRestart && (e.crashed || e.desynced) {
+		if err := e.restart(); err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range args {
+		if _, err := fmt.Fprintln(e.stdin, a); err != nil {
+			e.crash(err)
+			return nil, err
+		}
+	}
+	if _, err := fmt.Fprintln(e.stdin, executeArg); err != nil {
+		e.crash(err)
+		return nil, err
+	}
+	e.recordCommand(args)
+
+	resp, err := e.parser.Next()
+	if err == ErrBufferTooSmall {
+		// the rest of the response will be read as the response of the next command
+		e.desynced = true
+		e.emit(EventDesync, ErrBufferTooSmall)
+		return nil, ErrBufferTooSmall
+	}
+	if err != nil {
+		err = fmt.Errorf("error while reading stdMergedOut: %w", err)
+		e.crash(err)
+		return nil, err
+	}
+
+	return resp, nil
 }
 
-func splitReadyToken(data []byte, atEOF bool) (int, []byte, error) {
-	idx := bytes.Index(data, readyToken)
-	if idx == -1 {
-		if atEOF && len(data) > 0 {
-			return 0, data, fmt.Errorf("no final token found")
+// fieldOrder returns the keys of the first object of exiftool's JSON output, in order of appearance
+func fieldOrder(data []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for _, exp := range []json.Delim{'[', '{'} {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if t != exp {
+			return nil, fmt.Errorf("unexpected token %v, %v expected", t, exp)
 		}
+	}
 
-		return 0, nil, nil
+	var keys []string
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, t.(string))
+		var skipped json.RawMessage
+		if err := dec.Decode(&skipped); err != nil {
+			return nil, err
+		}
 	}
+	return keys, nil
+}
 
-	return idx + readyTokenLen, data[:idx], nil
+// dropBinaryPlaceholders removes the "(Binary data N bytes, use -b option to extract)" values
+func dropBinaryPlaceholders(fields map[string]interface{}) {
+	for k, v := range fields {
+		if str, ok := v.(string); ok && isBinaryPlaceholder(str) {
+			delete(fields, k)
+		}
+	}
+}
+
+func isBinaryPlaceholder(v string) bool {
+	return strings.HasPrefix(v, "(Binary data ") && strings.HasSuffix(v, "use -b option to extract)")
+}
+
+type checksumsResult struct {
+	sums map[crypto.Hash]string
+	err  error
+}
+
+// computeChecksums streams the file once through all the requested hash functions
+func computeChecksums(file string, hashes []crypto.Hash) (map[crypto.Hash]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hs := make([]hash.Hash, len(hashes))
+	ws := make([]io.Writer, len(hashes))
+	for i, h := range hashes {
+		hs[i] = h.New()
+		ws[i] = hs[i]
+	}
+	if _, err := io.Copy(io.MultiWriter(ws...), f); err != nil {
+		return nil, err
+	}
+
+	res := make(map[crypto.Hash]string, len(hashes))
+	for i, h := range hashes {
+		res[h] = hex.EncodeToString(hs[i].Sum(nil))
+	}
+	return res, nil
+}
+
+// sidecarPath returns the path of the XMP sidecar associated to a file (same name, .xmp extension)
+func sidecarPath(file string) string {
+	return strings.TrimSuffix(file, filepath.Ext(file)) + ".xmp"
+}
+
+// WriteResult is the outcome of a write, parsed from the summary printed by exiftool
+type WriteResult struct {
+	// Updated is the number of files that have been modified
+	Updated int
+	// Unchanged is the number of files that already contained the written values
+	Unchanged int
+	// Created is the number of files that have been created (e.g. XMP sidecars)
+	Created int
+	// Failed is the number of files that couldn't be written
+	Failed int
+	// Warnings contains the warnings printed by exiftool, without the "Warning: " prefix
+	Warnings []string
+	// Errors contains the errors printed by exiftool, without the "Error: " prefix
+	Errors []string
+	// Raw is exiftool's complete response
+	Raw string
+}
+
+var writeSummaryRegexp = regexp.MustCompile(`^(\d+) (?:image files (updated|unchanged|created)|files weren't (?:updated|created) due to errors)$`)
+
+func parseWriteResult(resp string) WriteResult {
+	res := WriteResult{Raw: resp}
+	s := bufio.NewScanner(strings.NewReader(resp))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case strings.HasPrefix(line, "Warning: "):
+			res.Warnings = append(res.Warnings, strings.TrimPrefix(line, "Warning: "))
+		case strings.HasPrefix(line, "Error: "):
+			res.Errors = append(res.Errors, strings.TrimPrefix(line, "Error: "))
+		default:
+			m := writeSummaryRegexp.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			n, _ := strconv.Atoi(m[1])
+			switch m[2] {
+			case "updated":
+				res.Updated = n
+			case "unchanged":
+				res.Unchanged = n
+			case "created":
+				res.Created = n
+			default:
+				res.Failed = n
+			}
+		}
+	}
+	return res
 }
 
 func handleWriteMetadataResponse(resp string) error {
-	if strings.HasSuffix(resp, writeMetadataSuccessToken) {
+	if strings.HasSuffix(resp, writeMetadataSuccessToken) || strings.HasSuffix(resp, writeMetadataCreatedToken) {
 		return nil
 	}
 	return errors.New(strings.TrimSpace(resp))
@@ -328,6 +1115,7 @@ func Buffer(buf []byte, max int) func(*Exiftool) error {
 }
 
 // Charset defines the -charset value to pass to Exiftool, see https://exiftool.org/faq.html#Q10 and https://exiftool.org/faq.html#Q18
+// On Windows, file names are passed as UTF-8 ("filename=utf8") unless a filename charset is defined.
 // Sample :
 //   e, err := NewExiftool(Charset("filename=utf8"))
 func Charset(charset string) func(*Exiftool) error {
@@ -338,6 +1126,7 @@ func Charset(charset string) func(*Exiftool) error {
 }
 
 // Api defines an -api value to pass to Exiftool, see https://www.exiftool.org/exiftool_pod.html#Advanced-options
+// (see ApiOptions for validated options)
 // Sample :
 //   e, err := NewExiftool(Api("QuickTimeUTC"))
 func Api(apiValue string) func(*Exiftool) error {
@@ -347,6 +1136,48 @@ func Api(apiValue string) func(*Exiftool) error {
 	}
 }
 
+// CommonArgs appends arbitrary arguments to the ones passed to exiftool for every command, for the
+// exiftool options that have no dedicated option. Arguments that would break the communication
+// with exiftool (-stay_open, -@, -execute, -common_args or values containing a line break) are
+// rejected with ErrInvalidArgument.
+// Sample :
+//   e, err := NewExiftool(CommonArgs("-struct", "-lang", "fr"))
+func CommonArgs(args ...string) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		for _, a := range args {
+			if err := checkCommonArg(a); err != nil {
+				return err
+			}
+		}
+		e.extraInitArgs = append(e.extraInitArgs, args...)
+		return nil
+	}
+}
+
+// withDefaultCharset appends the default filename charset to the arguments, unless they already
+// define one
+func withDefaultCharset(args []string, charset string) []string {
+	if charset == "" {
+		return args
+	}
+	for i := 0; i+1 < len(args); i++ {
+		if strings.EqualFold(args[i], "-charset") && strings.HasPrefix(strings.ToLower(args[i+1]), "filename=") {
+			return args
+		}
+	}
+	return append(append([]string(nil), args...), "-charset", "filename="+charset)
+}
+
+// reservedArgRegexp matches the exiftool options used by the stay_open protocol
+var reservedArgRegexp = regexp.MustCompile(`(?i)^-(?:stay_open|@|execute\d*|common_args)$`)
+
+func checkCommonArg(a string) error {
+	if strings.ContainsAny(a, "\r\n") || reservedArgRegexp.MatchString(a) {
+		return fmt.Errorf("%w: %q", ErrInvalidArgument, a)
+	}
+	return nil
+}
+
 // NoPrintConversion enables 'No print conversion' mode, see https://exiftool.org/exiftool_pod.html.
 // Sample :
 //   e, err := NewExiftool(NoPrintConversion())
@@ -377,12 +1208,66 @@ func ExtractAllBinaryMetadata() func(*Exiftool) error {
 	}
 }
 
+// DropBinaryPlaceholders removes the fields whose value is exiftool's binary placeholder
+// ("(Binary data N bytes, use -b option to extract)") from the extracted fields
+// Sample :
+//   e, err := NewExiftool(DropBinaryPlaceholders())
+func DropBinaryPlaceholders() func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		e.dropBinaryPlaceholders = true
+		return nil
+	}
+}
+
+// PreserveFieldOrder keeps track of the order in which exiftool outputs the fields, see FileMetadata.OrderedFields
+// Sample :
+//   e, err := NewExiftool(PreserveFieldOrder())
+func PreserveFieldOrder() func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		e.preserveFieldOrder = true
+		return nil
+	}
+}
+
+// Decoder replaces the default decoding of the JSON object exiftool outputs for each file
+// (into FileMetadata.Fields as map[string]interface{}). The function can populate Fields on its
+// own (e.g. with another JSON library or with renamed keys) or decode the payload somewhere else
+// (e.g. into a user struct) and leave Fields empty.
+// Sample :
+//   e, err := NewExiftool(Decoder(func(payload []byte, fm *FileMetadata) error {
+//     return jsoniter.Unmarshal(payload, &fm.Fields)
+//   }))
+func Decoder(fn DecodeFunc) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		e.decodeFunc = fn
+		return nil
+	}
+}
+
+// DateLayouts registers additional layouts (see time.Parse) tried in order by GetDateTime when a
+// date can't be parsed with the configured date format (see DateFormant) nor with exiftool's default
+// formats, e.g. to handle dates written in a non standard way by some cameras or softwares.
+// Sample :
+//   e, err := NewExiftool(DateLayouts("2006/01/02 15:04:05", "Mon Jan 2 15:04:05 2006"))
+func DateLayouts(layouts ...string) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		for _, l := range layouts {
+			if l == "" {
+				return errors.New("date layout can't be empty")
+			}
+		}
+		e.dateLayouts = append(e.dateLayouts, layouts...)
+		return nil
+	}
+}
+
 // DateFormant defines the -dateFormat value to pass to Exiftool, see https://exiftool.org/ExifTool.html#DateFormat
 // Sample :
 //   e, err := NewExiftool(DateFormant("%s"))
 func DateFormant(format string) func(*Exiftool) error {
 	return func(e *Exiftool) error {
 		e.extraInitArgs = append(e.extraInitArgs, "-dateFormat", format)
+		e.dateFormat = format
 		return nil
 	}
 }
@@ -429,15 +1314,176 @@ func ClearFieldsBeforeWriting() func(*Exiftool) error {
 	}
 }
 
+// WriteChangedFieldsOnly only writes the fields that have been modified with the setters (see
+// FileMetadata.Changed) instead of all the fields, which avoids writing back every extracted value
+// when only a few fields of an extracted FileMetadata have been modified. Fields of FileMetadata
+// that have not been created by ExtractMetadata, EmptyFileMetadata or NewFileMetadata are all written.
+// When no field has been modified, nothing is written and no error is reported.
+// Sample :
+//   e, err := NewExiftool(WriteChangedFieldsOnly())
+func WriteChangedFieldsOnly() func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		e.writeChangedOnly = true
+		return nil
+	}
+}
+
+// XMPSidecar writes metadata to the XMP sidecar of each file (same name with the .xmp extension,
+// e.g. IMG_0001.xmp for IMG_0001.CR2) instead of modifying the file itself. Missing sidecars are
+// created from the metadata of the original file.
+// Sample :
+//   e, err := NewExiftool(XMPSidecar())
+func XMPSidecar() func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		e.xmpSidecar = true
+		return nil
+	}
+}
+
+// DryRun makes WriteMetadata print the arguments it would send to exiftool for each file to w,
+// without modifying the files. The tags are still validated by exiftool (unknown or non writable
+// tags are reported in FileMetadata.Err) by writing a copy of each file in a temporary folder.
+// The other methods modifying files (WriteMetadataBatch, WriteMetadataJSON, Modify, ImportJSON,
+// ImportCSV, ShiftDates, SyncDates, SetThumbnail, SetICCProfile, Geotag, StripGroups) print and
+// validate their command the same way. RenameByTemplate only reports the new names, and
+// RestoreOriginals and DeleteOriginals only print their command.
+// Sample :
+//   e, err := NewExiftool(DryRun(os.Stdout))
+func DryRun(w io.Writer) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		if w == nil {
+			return errors.New("dry run writer can't be nil")
+		}
+		e.dryRun = w
+		return nil
+	}
+}
+
+// AllowWriteTags restricts the tags that can be written to the ones matching at least one of
+// the patterns, other tags being rejected with ErrTagNotAllowed. Patterns are case insensitive
+// globs (see path.Match) matched against the tag name, or against the group qualified key if
+// they contain a group (e.g. "XMP-dc:*"). The policy covers every method writing tags:
+// WriteMetadata, WriteMetadataBatch, WriteMetadataJSON, Modify, StripGroups, ImportJSON and
+// ImportCSV (the tags of the imported file), ShiftDates, SyncDates, SetThumbnail, SetICCProfile,
+// Geotag (the GPS tags) and RenameByTemplate (FileName). Keys containing wildcards ("*" or "?")
+// and, unless AllowWriteFromFiles is set, file operations (see SetBinaryFromFile) are rejected.
+// Sample :
+//   e, err := NewExiftool(AllowWriteTags("Artist", "Copyright", "XMP-dc:*"))
+func AllowWriteTags(patterns ...string) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		p, err := writeTagPatterns(patterns)
+		if err != nil {
+			return err
+		}
+		e.allowedWriteTags = append(e.allowedWriteTags, p...)
+		return nil
+	}
+}
+
+// DenyWriteTags rejects the tags matching any of the patterns with ErrTagNotAllowed, even if
+// they are allowed by AllowWriteTags. Patterns follow the same rules as AllowWriteTags.
+// Sample :
+//   e, err := NewExiftool(DenyWriteTags("FileName", "Directory", "File*", "System:*"))
+func DenyWriteTags(patterns ...string) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		p, err := writeTagPatterns(patterns)
+		if err != nil {
+			return err
+		}
+		e.deniedWriteTags = append(e.deniedWriteTags, p...)
+		return nil
+	}
+}
+
+func writeTagPatterns(patterns []string) ([]string, error) {
+	lower := make([]string, len(patterns))
+	for i, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid tag pattern %q: %w", p, err)
+		}
+		lower[i] = strings.ToLower(p)
+	}
+	return lower, nil
+}
+
+// AllowWriteFromFiles allows writing tags from the content of files (see SetBinaryFromFile) when
+// AllowWriteTags or DenyWriteTags are used: as these operations make exiftool read any file
+// readable by the process, they are rejected with ErrTagNotAllowed by default when a policy is set.
+// Sample :
+//   e, err := NewExiftool(AllowWriteTags("Picture"), AllowWriteFromFiles())
+func AllowWriteFromFiles() func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		e.writeFromFiles = true
+		return nil
+	}
+}
+
+// LenientKeys makes the getters of the extracted FileMetadata resolve keys case insensitively
+// and regardless of their group (see FileMetadata.ResolveKey), e.g. GetString("createdate")
+// returns the QuickTime:CreateDate field when group names are printed.
+// Sample :
+//   e, err := NewExiftool(LenientKeys())
+func LenientKeys() func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		e.lenientKeys = true
+		return nil
+	}
+}
+
 // SetExiftoolBinaryPath sets exiftool's binary path. When not specified, the binary will have to be in $PATH
+// (on Windows, exiftool.exe or the "exiftool(-k).exe" of the stock distribution). The path can
+// also be the directory containing the binary, e.g. the folder in which the Windows distribution
+// was extracted.
 // Sample :
 //   e, err := NewExiftool(SetExiftoolBinaryPath("/usr/bin/exiftool"))
+//   e, err := NewExiftool(SetExiftoolBinaryPath(`C:\exiftool-12.96_64`))
 func SetExiftoolBinaryPath(p string) func(*Exiftool) error {
 	return func(e *Exiftool) error {
-		if _, err := os.Stat(p); err != nil {
+		fi, err := os.Stat(p)
+		if err != nil {
 			return fmt.Errorf("error while checking if path '%v' exists: %w", p, err)
 		}
+		if fi.IsDir() {
+			if p, err = binaryInDir(p, append([]string{exiftoolBinary}, exiftoolAltBinaries...)); err != nil {
+				return err
+			}
+		}
 		e.exiftoolBinPath = p
 		return nil
 	}
 }
+
+// ConfigFile loads an exiftool configuration file (exiftool's -config), e.g. to define user-defined
+// tags, which can then be extracted and written like any other tag, with their group (e.g.
+// "XMP-custom:ProjectID") when group names are printed or written. An empty path disables the
+// loading of the default configuration file (~/.ExifTool_config).
+// Sample :
+//   e, err := NewExiftool(ConfigFile("custom.config"))
+func ConfigFile(p string) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		if p != "" {
+			if _, err := os.Stat(p); err != nil {
+				return fmt.Errorf("error while checking if config file '%v' exists: %w", p, err)
+			}
+		}
+		e.configFile = p
+		e.configFileSet = true
+		return nil
+	}
+}
+
+// Checksums computes the given hashes (crypto.MD5, crypto.SHA1 and crypto.SHA256 are supported) of each
+// file while its metadata is being extracted. Hex encoded results are stored in FileMetadata.Checksums.
+// Sample :
+//   e, err := NewExiftool(Checksums(crypto.MD5, crypto.SHA256))
+func Checksums(hashes ...crypto.Hash) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		for _, h := range hashes {
+			if !h.Available() {
+				return fmt.Errorf("hash function %v is not available", h)
+			}
+		}
+		e.checksums = append(e.checksums, hashes...)
+		return nil
+	}
+}
@@ -2,9 +2,14 @@ package exiftool
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -379,6 +384,141 @@ func TestExtractAllBinaryMetadata(t *testing.T) {
 	assert.True(t, strings.HasPrefix(osn, "base64"))
 }
 
+func TestGetBinaryWithExtractAllBinaryMetadata(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool(ExtractAllBinaryMetadata())
+	require.Nil(t, err)
+	defer e.Close()
+	metas := e.ExtractMetadata("./testdata/binary.mp3")
+	require.Len(t, metas, 1)
+	require.Nil(t, metas[0].Err)
+	picture, err := metas[0].GetBinary("Picture")
+	require.Nil(t, err)
+	assert.True(t, bytes.HasPrefix(picture, []byte("\x89PNG")))
+}
+
+func TestDropBinaryPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	eWithout, err := NewExiftool()
+	assert.Nil(t, err)
+	defer eWithout.Close()
+	metas := eWithout.ExtractMetadata("./testdata/binary.mp3")
+	assert.Equal(t, 1, len(metas))
+	assert.Nil(t, metas[0].Err)
+	_, err = metas[0].GetString("Picture")
+	assert.Nil(t, err) // backward compatibility
+
+	eWith, err := NewExiftool(DropBinaryPlaceholders())
+	assert.Nil(t, err)
+	defer eWith.Close()
+	metas = eWith.ExtractMetadata("./testdata/binary.mp3")
+	assert.Equal(t, 1, len(metas))
+	assert.Nil(t, metas[0].Err)
+	_, err = metas[0].GetString("Picture")
+	assert.Equal(t, ErrKeyNotFound, err)
+	_, err = metas[0].GetString("FileName")
+	assert.Nil(t, err)
+}
+
+func TestIsBinaryPlaceholder(t *testing.T) {
+	var tcs = []struct {
+		tcID  string
+		in    string
+		expOk bool
+	}{
+		{"placeholder", "(Binary data 37 bytes, use -b option to extract)", true},
+		{"base64", "base64:aGVsbG8=", false},
+		{"regular", "Binary data", false},
+		{"empty", "", false},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			assert.Equal(t, tc.expOk, isBinaryPlaceholder(tc.in))
+		})
+	}
+}
+
+func TestFieldOrder(t *testing.T) {
+	var tcs = []struct {
+		tcID    string
+		in      string
+		expOk   bool
+		expKeys []string
+	}{
+		{"nominal", `[{"SourceFile": "a.jpg", "ZKey": {"b": 1, "a": [1, 2]}, "AKey": "v"}]`, true, []string{"SourceFile", "ZKey", "AKey"}},
+		{"empty", `[{}]`, true, nil},
+		{"notArray", `{"a": 1}`, false, nil},
+		{"truncated", `[{"a": 1, "b"`, false, nil},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			keys, err := fieldOrder([]byte(tc.in))
+			assert.Equal(t, tc.expOk, err == nil)
+			if tc.expOk {
+				assert.Equal(t, tc.expKeys, keys)
+			}
+		})
+	}
+}
+
+func TestPreserveFieldOrder(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool(PreserveFieldOrder())
+	require.Nil(t, err)
+	defer e.Close()
+	metas := e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	require.Len(t, metas, 1)
+	require.Nil(t, metas[0].Err)
+	fields := metas[0].OrderedFields()
+	require.Len(t, fields, len(metas[0].Fields))
+	assert.Equal(t, "SourceFile", fields[0].Key)
+}
+
+func TestDecoder(t *testing.T) {
+	t.Parallel()
+
+	type fileInfo struct {
+		FileName string
+	}
+	var infos []fileInfo
+	e, err := NewExiftool(Decoder(func(payload []byte, fm *FileMetadata) error {
+		var fi fileInfo
+		if err := json.Unmarshal(payload, &fi); err != nil {
+			return err
+		}
+		infos = append(infos, fi)
+		return nil
+	}))
+	require.Nil(t, err)
+	defer e.Close()
+	metas := e.ExtractMetadata("./testdata/20190404_131804.jpg", "./testdata/gps.jpg")
+	require.Len(t, metas, 2)
+	assert.Nil(t, metas[0].Err)
+	assert.Nil(t, metas[1].Err)
+	assert.Empty(t, metas[0].Fields)
+	assert.Equal(t, []fileInfo{{"20190404_131804.jpg"}, {"gps.jpg"}}, infos)
+}
+
+func TestDecoderError(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool(Decoder(func(payload []byte, fm *FileMetadata) error {
+		return fmt.Errorf("error")
+	}))
+	require.Nil(t, err)
+	defer e.Close()
+	metas := e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	require.Len(t, metas, 1)
+	assert.NotNil(t, metas[0].Err)
+}
+
 func TestDateFormat(t *testing.T) {
 	t.Parallel()
 
@@ -508,6 +648,8 @@ func TestWriteMetadataSuccessTokenHandling(t *testing.T) {
 		{name: "token at resp beginning", testResp: writeMetadataSuccessToken + "suffix text",
 			expectErr: true},
 		{name: "no token", testResp: "some error message", expectErr: true},
+		{name: "created token at resp end", testResp: "prefix text" + writeMetadataCreatedToken,
+			expectErr: false},
 	}
 
 	for _, tc := range testCases {
@@ -524,6 +666,142 @@ func TestWriteMetadataSuccessTokenHandling(t *testing.T) {
 	}
 }
 
+func TestParseWriteResult(t *testing.T) {
+	var tcs = []struct {
+		tcID   string
+		inResp string
+		exp    WriteResult
+	}{
+		{"updated", "    1 image files updated\n", WriteResult{Updated: 1}},
+		{"unchanged", "    1 image files unchanged\n", WriteResult{Unchanged: 1}},
+		{"created", "    1 image files created\n", WriteResult{Created: 1}},
+		{"mixed", "    2 image files updated\n    1 image files unchanged\n", WriteResult{Updated: 2, Unchanged: 1}},
+		{"warning", "Warning: [minor] Ignored empty rational value - a.jpg\n    1 image files updated\n",
+			WriteResult{Updated: 1, Warnings: []string{"[minor] Ignored empty rational value - a.jpg"}}},
+		{"error", "Error: Not a valid JPG - a.jpg\n    0 image files updated\n    1 files weren't updated due to errors\n",
+			WriteResult{Failed: 1, Errors: []string{"Not a valid JPG - a.jpg"}}},
+		{"windows", "    1 image files updated\r\n", WriteResult{Updated: 1}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			tc.exp.Raw = tc.inResp
+			assert.Equal(t, tc.exp, parseWriteResult(tc.inResp))
+		})
+	}
+}
+
+func TestWriteMetadataWriteResult(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	mds := []FileMetadata{EmptyFileMetadata()}
+	mds[0].File = testFile
+	mds[0].SetString("Artist", "a")
+	e.WriteMetadata(mds)
+	require.Nil(t, mds[0].Err)
+	require.NotNil(t, mds[0].WriteResult)
+	assert.Equal(t, 1, mds[0].WriteResult.Updated)
+
+	e.WriteMetadata(mds)
+	assert.NotNil(t, mds[0].Err)
+	require.NotNil(t, mds[0].WriteResult)
+	assert.Equal(t, 0, mds[0].WriteResult.Updated)
+	assert.Equal(t, 1, mds[0].WriteResult.Unchanged)
+}
+
+func TestExtractMetadataGetDateTime(t *testing.T) {
+	t.Parallel()
+
+	var tcs = []struct {
+		tcID   string
+		inOpts []func(*Exiftool) error
+	}{
+		{"default", nil},
+		{"dateFormat", []func(*Exiftool) error{DateFormant("%Y%m%d-%H%M%S")}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			e, err := NewExiftool(tc.inOpts...)
+			require.Nil(t, err)
+			defer e.Close()
+
+			mds := e.ExtractMetadata("./testdata/20190404_131804.jpg")
+			require.Len(t, mds, 1)
+			require.Nil(t, mds[0].Err)
+			got, err := mds[0].GetDateTime("DateTimeOriginal")
+			require.Nil(t, err)
+			assert.Equal(t, time.Date(2019, time.April, 4, 13, 18, 3, 0, time.UTC), got)
+		})
+	}
+}
+
+func TestExtractMetadataLenientKeys(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool(LenientKeys(), PrintGroupNames("0"))
+	require.Nil(t, err)
+	defer e.Close()
+
+	mds := e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	got, err := mds[0].GetString("filename")
+	require.Nil(t, err)
+	assert.Equal(t, "20190404_131804.jpg", got)
+}
+
+func TestWriteMetadataContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mds := []FileMetadata{EmptyFileMetadata(), EmptyFileMetadata()}
+	for i := range mds {
+		mds[i].File = "./testdata/20190404_131804.jpg"
+		mds[i].SetString("Artist", "a")
+	}
+	e.WriteMetadataContext(ctx, mds)
+	for _, md := range mds {
+		assert.Equal(t, context.Canceled, md.Err)
+		assert.Nil(t, md.WriteResult)
+	}
+}
+
+func TestExtractMetadataContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mds := e.ExtractMetadataContext(ctx, "./testdata/20190404_131804.jpg")
+	require.Len(t, mds, 1)
+	assert.Equal(t, "./testdata/20190404_131804.jpg", mds[0].File)
+	assert.Equal(t, context.Canceled, mds[0].Err)
+
+	mds = e.ExtractMetadataContext(context.Background(), "./testdata/20190404_131804.jpg")
+	require.Len(t, mds, 1)
+	assert.Nil(t, mds[0].Err)
+}
+
 func TestWriteMetadataFails(t *testing.T) {
 	t.Parallel()
 
@@ -611,7 +889,7 @@ func TestWriteMetadataNominal(t *testing.T) {
 	require.Equal(t, ErrKeyNotFound, err)
 }
 
-func TestWriteMetadataInvalidField(t *testing.T) {
+func TestWriteMetadataAddToList(t *testing.T) {
 	t.Parallel()
 
 	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
@@ -622,91 +900,316 @@ func TestWriteMetadataInvalidField(t *testing.T) {
 	defer e.Close()
 
 	mds := []FileMetadata{EmptyFileMetadata()}
-	mds[0].SetString("InvalidField", "ValueDoesNotMatter")
+	mds[0].File = testFile
+	mds[0].SetStrings("Keywords", []string{"kw1", "kw2"})
 	e.WriteMetadata(mds)
-	require.NotNil(t, mds[0].Err)
+	require.Nil(t, mds[0].Err)
+
+	mds = []FileMetadata{EmptyFileMetadata()}
+	mds[0].File = testFile
+	mds[0].AddToList("Keywords", "kw3")
+	e.WriteMetadata(mds)
+	require.Nil(t, mds[0].Err)
+
+	mds = e.ExtractMetadata(testFile)
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	got, err := mds[0].GetStrings("Keywords")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"kw1", "kw2", "kw3"}, got)
 }
 
-func TestWriteMetadataClearExistingFields(t *testing.T) {
+func TestWriteMetadataRemoveFromList(t *testing.T) {
 	t.Parallel()
 
-	tcs := []struct {
-		tcID      string
-		inEnabled bool
-		expExists bool
-	}{
-		{"disabled", false, true},
-		{"enabled", true, false},
-	}
-	for _, tc := range tcs {
-		tc := tc
-		t.Run(tc.tcID, func(t *testing.T) {
-			testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
-			require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
 
-			var e *Exiftool
-			var err error
-			if tc.inEnabled {
-				e, err = NewExiftool(ClearFieldsBeforeWriting())
-			} else {
-				e, err = NewExiftool()
-			}
-			require.Nil(t, err)
-			defer e.Close()
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
 
-			mds := e.ExtractMetadata(testFile)
-			require.Len(t, mds, 1)
-			require.Nil(t, mds[0].Err)
-			_, err = mds[0].GetString("ImageUniqueID")
-			require.Nil(t, err)
+	mds := []FileMetadata{EmptyFileMetadata()}
+	mds[0].File = testFile
+	mds[0].SetStrings("Keywords", []string{"kw1", "kw2", "kw3"})
+	e.WriteMetadata(mds)
+	require.Nil(t, mds[0].Err)
 
-			mds2 := []FileMetadata{EmptyFileMetadata()}
-			mds2[0].File = mds[0].File
-			mds2[0].SetString("Title", "fakeTitle")
-			e.WriteMetadata(mds2)
-			require.Nil(t, mds2[0].Err)
+	mds = []FileMetadata{EmptyFileMetadata()}
+	mds[0].File = testFile
+	mds[0].RemoveFromList("Keywords", "kw2")
+	e.WriteMetadata(mds)
+	require.Nil(t, mds[0].Err)
 
-			mds3 := e.ExtractMetadata(testFile)
-			require.Len(t, mds3, 1)
-			require.Nil(t, mds3[0].Err)
-			_, err = mds3[0].GetString("ImageUniqueID")
-			if tc.expExists {
-				require.Nil(t, err)
-			} else {
-				require.Equal(t, ErrKeyNotFound, err)
-			}
-		})
-	}
+	mds = e.ExtractMetadata(testFile)
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	got, err := mds[0].GetStrings("Keywords")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"kw1", "kw3"}, got)
 }
 
-func TestWriteMetadataBackupOriginal(t *testing.T) {
+func TestWriteMetadataBinaryFromFile(t *testing.T) {
 	t.Parallel()
 
-	tcs := []struct {
-		tcID      string
-		inEnabled bool
-	}{
-		{"disabled", false},
-		{"enabled", true},
-	}
+	testFile := filepath.Join(t.TempDir(), "binary.mp3")
+	require.Nil(t, copyFile("testdata/binary.mp3", testFile))
+	cover, err := ioutil.ReadFile("testdata/thumbnail.jpg")
+	require.Nil(t, err)
 
-	for _, tc := range tcs {
-		tc := tc
-		t.Run(tc.tcID, func(t *testing.T) {
+	e, err := NewExiftool(ExtractAllBinaryMetadata())
+	require.Nil(t, err)
+	defer e.Close()
 
-			filename := fmt.Sprintf("20190404_131804_%v.jpg", tc.tcID)
-			testFile := filepath.Join(t.TempDir(), filename)
-			require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+	mds := []FileMetadata{EmptyFileMetadata()}
+	mds[0].File = testFile
+	mds[0].SetBinaryFromFile("Picture", "testdata/thumbnail.jpg")
+	e.WriteMetadata(mds)
+	require.Nil(t, mds[0].Err)
 
-			var e *Exiftool
-			var err error
-			if tc.inEnabled {
-				e, err = NewExiftool(BackupOriginal())
-			} else {
-				e, err = NewExiftool()
-			}
-			require.Nil(t, err)
-			defer e.Close()
+	mds = e.ExtractMetadata(testFile)
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	got, err := mds[0].GetBinary("Picture")
+	require.Nil(t, err)
+	assert.Equal(t, cover, got)
+}
+
+func TestWriteMetadataKeywords(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+	e, err := NewExiftool(PrintGroupNames("0"))
+	require.Nil(t, err)
+	defer e.Close()
+
+	mds := []FileMetadata{EmptyFileMetadata()}
+	mds[0].File = testFile
+	mds[0].SetHierarchicalKeywords("Places|Paris")
+	e.WriteMetadata(mds)
+	require.Nil(t, mds[0].Err)
+
+	mds = e.ExtractMetadata(testFile)
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	for _, k := range []string{"IPTC:Keywords", "XMP:Subject"} {
+		got, err := mds[0].GetStrings(k)
+		require.Nil(t, err)
+		assert.Equal(t, []string{"Places", "Paris"}, got)
+	}
+	got, err := mds[0].GetStrings("XMP:HierarchicalSubject")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"Places|Paris"}, got)
+}
+
+func TestWriteMetadataStruct(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	mds := []FileMetadata{EmptyFileMetadata()}
+	mds[0].File = testFile
+	mds[0].SetStruct("CreatorContactInfo", map[string]interface{}{
+		"CiAdrCity":   "Paris, France",
+		"CiEmailWork": "a@b.c",
+	})
+	e.WriteMetadata(mds)
+	require.Nil(t, mds[0].Err)
+
+	mds = e.ExtractMetadata(testFile)
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	got, err := mds[0].GetString("CreatorCity")
+	require.Nil(t, err)
+	assert.Equal(t, "Paris, France", got)
+	got, err = mds[0].GetString("CreatorWorkEmail")
+	require.Nil(t, err)
+	assert.Equal(t, "a@b.c", got)
+}
+
+func TestWriteMetadataEmptyString(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	mds := []FileMetadata{EmptyFileMetadata()}
+	mds[0].File = testFile
+	mds[0].SetString("XMP:Title", "")
+	e.WriteMetadata(mds)
+	require.Nil(t, mds[0].Err)
+
+	mds = e.ExtractMetadata(testFile)
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	got, err := mds[0].GetString("Title")
+	require.Nil(t, err)
+	assert.Equal(t, "", got)
+}
+
+func TestWriteMetadataInt(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+	e, err := NewExiftool(NoPrintConversion())
+	require.Nil(t, err)
+	defer e.Close()
+
+	mds := []FileMetadata{EmptyFileMetadata()}
+	mds[0].File = testFile
+	mds[0].SetInt("Rating", 4)
+	mds[0].SetInt("Orientation#", 3)
+	e.WriteMetadata(mds)
+	require.Nil(t, mds[0].Err)
+
+	mds = e.ExtractMetadata(testFile)
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	rating, err := mds[0].GetInt("Rating")
+	require.Nil(t, err)
+	assert.Equal(t, int64(4), rating)
+	orientation, err := mds[0].GetInt("Orientation")
+	require.Nil(t, err)
+	assert.Equal(t, int64(3), orientation)
+}
+
+func TestWriteMetadataGroupQualified(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+	e, err := NewExiftool(PrintGroupNames("1"))
+	require.Nil(t, err)
+	defer e.Close()
+
+	mds := []FileMetadata{EmptyFileMetadata()}
+	mds[0].File = testFile
+	mds[0].SetString(TagKey("XMP-dc", "Title"), "xmpTitle")
+	mds[0].SetString(TagKey("IPTC", "ObjectName"), "iptcTitle")
+	e.WriteMetadata(mds)
+	require.Nil(t, mds[0].Err)
+
+	mds = e.ExtractMetadata(testFile)
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	got, err := mds[0].GetString("XMP-dc:Title")
+	require.Nil(t, err)
+	assert.Equal(t, "xmpTitle", got)
+	got, err = mds[0].GetString("IPTC:ObjectName")
+	require.Nil(t, err)
+	assert.Equal(t, "iptcTitle", got)
+}
+
+func TestWriteMetadataInvalidField(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	mds := []FileMetadata{EmptyFileMetadata()}
+	mds[0].SetString("InvalidField", "ValueDoesNotMatter")
+	e.WriteMetadata(mds)
+	require.NotNil(t, mds[0].Err)
+}
+
+func TestWriteMetadataClearExistingFields(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		tcID      string
+		inEnabled bool
+		expExists bool
+	}{
+		{"disabled", false, true},
+		{"enabled", true, false},
+	}
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.tcID, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+			require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+			var e *Exiftool
+			var err error
+			if tc.inEnabled {
+				e, err = NewExiftool(ClearFieldsBeforeWriting())
+			} else {
+				e, err = NewExiftool()
+			}
+			require.Nil(t, err)
+			defer e.Close()
+
+			mds := e.ExtractMetadata(testFile)
+			require.Len(t, mds, 1)
+			require.Nil(t, mds[0].Err)
+			_, err = mds[0].GetString("ImageUniqueID")
+			require.Nil(t, err)
+
+			mds2 := []FileMetadata{EmptyFileMetadata()}
+			mds2[0].File = mds[0].File
+			mds2[0].SetString("Title", "fakeTitle")
+			e.WriteMetadata(mds2)
+			require.Nil(t, mds2[0].Err)
+
+			mds3 := e.ExtractMetadata(testFile)
+			require.Len(t, mds3, 1)
+			require.Nil(t, mds3[0].Err)
+			_, err = mds3[0].GetString("ImageUniqueID")
+			if tc.expExists {
+				require.Nil(t, err)
+			} else {
+				require.Equal(t, ErrKeyNotFound, err)
+			}
+		})
+	}
+}
+
+func TestWriteMetadataBackupOriginal(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		tcID      string
+		inEnabled bool
+	}{
+		{"disabled", false},
+		{"enabled", true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.tcID, func(t *testing.T) {
+
+			filename := fmt.Sprintf("20190404_131804_%v.jpg", tc.tcID)
+			testFile := filepath.Join(t.TempDir(), filename)
+			require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+			var e *Exiftool
+			var err error
+			if tc.inEnabled {
+				e, err = NewExiftool(BackupOriginal())
+			} else {
+				e, err = NewExiftool()
+			}
+			require.Nil(t, err)
+			defer e.Close()
 
 			mds := []FileMetadata{EmptyFileMetadata()}
 			mds[0].File = testFile
@@ -725,6 +1228,633 @@ func TestWriteMetadataBackupOriginal(t *testing.T) {
 	}
 }
 
+func TestWriteMetadataBatch(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	var files []string
+	for _, name := range []string{"a.jpg", "b.jpg"} {
+		f := filepath.Join(tmpDir, name)
+		require.Nil(t, copyFile("testdata/20190404_131804.jpg", f))
+		files = append(files, f)
+	}
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	md := EmptyFileMetadata()
+	md.SetString("Copyright", "fakeCopyright")
+	require.Nil(t, e.WriteMetadataBatch(md, files...))
+
+	mds := e.ExtractMetadata(files...)
+	require.Len(t, mds, 2)
+	for _, got := range mds {
+		require.Nil(t, got.Err)
+		copyright, err := got.GetString("Copyright")
+		require.Nil(t, err)
+		assert.Equal(t, "fakeCopyright", copyright)
+	}
+
+	err = e.WriteMetadataBatch(md, files[0], filepath.Join(tmpDir, "nonExisting.jpg"))
+	assert.True(t, errors.Is(err, ErrNotExist))
+}
+
+func TestWriteArgs(t *testing.T) {
+	md := EmptyFileMetadata()
+	md.SetString("Title", "fakeTitle")
+	md.SetString("Artist", "")
+	md.SetStrings("Keywords", []string{"kw1", "kw2"})
+	md.SetInt("Rating", 3)
+	md.AddToList("Subject", "s1", "s2")
+	md.RemoveFromList("Subject", "s1")
+	md.Clear("Flash")
+	md.SetBinaryFromFile("ThumbnailImage", "thumb.jpg")
+
+	var tcs = []struct {
+		tcID    string
+		inE     *Exiftool
+		expArgs []string
+	}{
+		{"default", &Exiftool{}, []string{"-overwrite_original", "-Artist^=", "-Flash=", "-Keywords=kw1", "-Keywords=kw2", "-Rating=3", "-Subject-=s1", "-Subject+=s1", "-Subject+=s2", "-ThumbnailImage<=thumb.jpg", "-Title=fakeTitle"}},
+		{"backupOriginal", &Exiftool{backupOriginal: true}, []string{"-Artist^=", "-Flash=", "-Keywords=kw1", "-Keywords=kw2", "-Rating=3", "-Subject-=s1", "-Subject+=s1", "-Subject+=s2", "-ThumbnailImage<=thumb.jpg", "-Title=fakeTitle"}},
+		{"clearFieldsBeforeWriting", &Exiftool{clearFieldsBeforeWriting: true}, []string{"-overwrite_original", "-All=", "-Artist^=", "-Flash=", "-Keywords=kw1", "-Keywords=kw2", "-Rating=3", "-Subject-=s1", "-Subject+=s1", "-Subject+=s2", "-ThumbnailImage<=thumb.jpg", "-Title=fakeTitle"}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			args, err := tc.inE.writeArgs(md)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expArgs, args)
+		})
+	}
+}
+
+func TestWriteArgsOptions(t *testing.T) {
+	md := EmptyFileMetadata()
+	md.SetString("Title", "fakeTitle")
+	mdWithOpts := EmptyFileMetadata()
+	mdWithOpts.SetString("Title", "fakeTitle")
+	mdWithOpts.WriteOptions = []WriteOption{WriteBackupOriginal(false)}
+
+	var tcs = []struct {
+		tcID    string
+		inE     *Exiftool
+		inMd    FileMetadata
+		inOpts  []WriteOption
+		expArgs []string
+	}{
+		{"instance", &Exiftool{backupOriginal: true}, md, nil, []string{"-Title=fakeTitle"}},
+		{"call", &Exiftool{backupOriginal: true}, md, []WriteOption{WriteBackupOriginal(false), WriteClearFieldsBeforeWriting(true)}, []string{"-overwrite_original", "-All=", "-Title=fakeTitle"}},
+		{"preserveModTime", &Exiftool{}, md, []WriteOption{WritePreserveModTime(true)}, []string{"-overwrite_original", "-P", "-Title=fakeTitle"}},
+		{"file", &Exiftool{}, mdWithOpts, []WriteOption{WriteBackupOriginal(true)}, []string{"-overwrite_original", "-Title=fakeTitle"}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			args, err := tc.inE.writeArgs(tc.inMd, tc.inOpts...)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expArgs, args)
+		})
+	}
+}
+
+func TestWriteArgsChangedOnly(t *testing.T) {
+	extracted := EmptyFileMetadata()
+	extracted.Fields = map[string]interface{}{"Title": "title", "Artist": "artist"}
+	extracted.SetString("Title", "newTitle")
+	extracted.Clear("Comment")
+	untracked := FileMetadata{Fields: map[string]interface{}{"Title": "title", "Artist": "artist"}}
+
+	var tcs = []struct {
+		tcID    string
+		inE     *Exiftool
+		inMd    FileMetadata
+		inOpts  []WriteOption
+		expArgs []string
+	}{
+		{"disabled", &Exiftool{}, extracted, nil, []string{"-overwrite_original", "-Artist=artist", "-Comment=", "-Title=newTitle"}},
+		{"instance", &Exiftool{writeChangedOnly: true}, extracted, nil, []string{"-overwrite_original", "-Comment=", "-Title=newTitle"}},
+		{"call", &Exiftool{}, extracted, []WriteOption{WriteChangedOnly(true)}, []string{"-overwrite_original", "-Comment=", "-Title=newTitle"}},
+		{"untracked", &Exiftool{writeChangedOnly: true}, untracked, nil, []string{"-overwrite_original", "-Artist=artist", "-Title=title"}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			args, err := tc.inE.writeArgs(tc.inMd, tc.inOpts...)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expArgs, args)
+		})
+	}
+
+	unmodified := EmptyFileMetadata()
+	unmodified.Fields = map[string]interface{}{"Title": "title"}
+	_, err := (&Exiftool{writeChangedOnly: true}).writeArgs(unmodified)
+	assert.Equal(t, errNothingToWrite, err)
+	args, err := (&Exiftool{writeChangedOnly: true}).writeArgs(unmodified, WriteClearFieldsBeforeWriting(true))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"-overwrite_original", "-All="}, args)
+}
+
+func TestWriteMetadataChangedOnlyUnmodified(t *testing.T) {
+	t.Parallel()
+
+	var dryRun bytes.Buffer
+	e, err := NewExiftool(WriteChangedFieldsOnly(), DryRun(&dryRun))
+	require.Nil(t, err)
+	defer e.Close()
+
+	mds := e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	require.Nil(t, mds[0].Err)
+	e.WriteMetadata(mds)
+	assert.Nil(t, mds[0].Err)
+	assert.Nil(t, mds[0].WriteResult)
+	assert.Empty(t, dryRun.String())
+	assert.Nil(t, e.WriteMetadataBatch(mds[0], "./testdata/20190404_131804.jpg"))
+}
+
+func TestWriteChangedFieldsOnly(t *testing.T) {
+	e, err := NewExiftool(WriteChangedFieldsOnly())
+	require.Nil(t, err)
+	defer e.Close()
+	assert.True(t, e.writeChangedOnly)
+}
+
+func TestExtractMetadataTracksChanges(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	mds := e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	assert.Empty(t, mds[0].Changed())
+	mds[0].SetString("Title", "title")
+	assert.Equal(t, []string{"Title"}, mds[0].Changed())
+}
+
+func TestDateLayouts(t *testing.T) {
+	e, err := NewExiftool(DateLayouts("2006/01/02"), DateLayouts("02.01.2006"))
+	require.Nil(t, err)
+	defer e.Close()
+	assert.Equal(t, []string{"2006/01/02", "02.01.2006"}, e.dateLayouts)
+
+	_, err = NewExiftool(DateLayouts(""))
+	assert.NotNil(t, err)
+}
+
+func TestConfigFile(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool(ConfigFile("./testdata/custom.config"))
+	require.Nil(t, err)
+	defer e.Close()
+	assert.Equal(t, []string{"-config", "./testdata/custom.config", "-stay_open"}, e.cmd.Args[1:4])
+
+	eNoConfig, err := NewExiftool(ConfigFile(""))
+	require.Nil(t, err)
+	defer eNoConfig.Close()
+	assert.Equal(t, []string{"-config", "", "-stay_open"}, eNoConfig.cmd.Args[1:4])
+
+	_, err = NewExiftool(ConfigFile("./testdata/missing.config"))
+	assert.NotNil(t, err)
+}
+
+func TestUserDefinedTags(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+	e, err := NewExiftool(ConfigFile("./testdata/custom.config"), PrintGroupNames("1"))
+	require.Nil(t, err)
+	defer e.Close()
+
+	md := NewFileMetadata(testFile).
+		WithString("XMP-custom:ProjectID", "P-42").
+		WithStrings("XMP-custom:Reviewers", "alice", "bob")
+	mds := []FileMetadata{md}
+	e.WriteMetadata(mds)
+	require.Nil(t, mds[0].Err)
+
+	mds = e.ExtractMetadata(testFile)
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	id, err := mds[0].GetString("XMP-custom:ProjectID")
+	assert.Nil(t, err)
+	assert.Equal(t, "P-42", id)
+	reviewers, err := mds[0].GetStrings("XMP-custom:Reviewers")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, reviewers)
+}
+
+func TestSetDefaultOptions(t *testing.T) {
+	// not parallel: default options apply to every NewExiftool
+	SetDefaultOptions(Charset("filename=utf8"), Api("largefilesupport=1"))
+	defer SetDefaultOptions()
+
+	e, err := NewExiftool(Api("QuickTimeUTC=1"))
+	require.Nil(t, err)
+	defer e.Close()
+	assert.Equal(t, []string{"-charset", "filename=utf8", "-api", "largefilesupport=1", "-api", "QuickTimeUTC=1"}, e.extraInitArgs)
+
+	SetDefaultOptions(func(*Exiftool) error {
+		return errors.New("error")
+	})
+	_, err = NewExiftool()
+	assert.NotNil(t, err)
+
+	SetDefaultOptions()
+	eWithout, err := NewExiftool()
+	require.Nil(t, err)
+	defer eWithout.Close()
+	assert.Empty(t, eWithout.extraInitArgs)
+}
+
+func TestCommonArgs(t *testing.T) {
+	var tcs = []struct {
+		tcID   string
+		inArgs []string
+		expErr bool
+	}{
+		{"valid", []string{"-struct", "-lang", "fr"}, false},
+		{"stayOpen", []string{"-stay_open", "False"}, true},
+		{"stayOpenCase", []string{"-STAY_OPEN"}, true},
+		{"argFile", []string{"-@", "args.txt"}, true},
+		{"execute", []string{"-execute"}, true},
+		{"executeNumbered", []string{"-execute42"}, true},
+		{"commonArgs", []string{"-common_args"}, true},
+		{"newLine", []string{"-lang", "fr\n-execute"}, true},
+		{"carriageReturn", []string{"-lang\r"}, true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			e := Exiftool{}
+			err := CommonArgs(tc.inArgs...)(&e)
+			assert.Equal(t, tc.expErr, err != nil)
+			if tc.expErr {
+				assert.True(t, errors.Is(err, ErrInvalidArgument))
+				assert.Empty(t, e.extraInitArgs)
+			} else {
+				assert.Equal(t, tc.inArgs, e.extraInitArgs)
+			}
+		})
+	}
+}
+
+func TestWriteMetadataOutput(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+	outFile := filepath.Join(dir, "out.jpg")
+	outDir := filepath.Join(dir, "sidecars")
+	require.Nil(t, os.Mkdir(outDir, 0755))
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	mds := []FileMetadata{EmptyFileMetadata(), EmptyFileMetadata()}
+	mds[0].File = testFile
+	mds[0].SetString("Artist", "out")
+	mds[1].File = testFile
+	mds[1].SetString("Artist", "sidecar")
+	mds[1].WriteOptions = []WriteOption{WriteOutput(filepath.Join(outDir, "%f.xmp"))}
+	e.WriteMetadata(mds, WriteOutput(outFile))
+	require.Nil(t, mds[0].Err)
+	require.Nil(t, mds[1].Err)
+
+	for f, exp := range map[string]string{outFile: "out", filepath.Join(outDir, "20190404_131804.xmp"): "sidecar"} {
+		got := e.ExtractMetadata(f)
+		require.Len(t, got, 1)
+		require.Nil(t, got[0].Err)
+		artist, err := got[0].GetString("Artist")
+		require.Nil(t, err)
+		assert.Equal(t, exp, artist)
+	}
+
+	got := e.ExtractMetadata(testFile)
+	require.Len(t, got, 1)
+	_, err = got[0].GetString("Artist")
+	assert.Equal(t, ErrKeyNotFound, err)
+}
+
+func TestWriteOutputEmpty(t *testing.T) {
+	assert.NotNil(t, WriteOutput("")(&writeConfig{}))
+}
+
+func TestWriteArgsOptionError(t *testing.T) {
+	md := EmptyFileMetadata()
+	md.WriteOptions = []WriteOption{func(*writeConfig) error { return errors.New("opt error") }}
+	_, err := (&Exiftool{}).writeArgs(md)
+	assert.NotNil(t, err)
+}
+
+func TestWriteMetadataPreserveModTime(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+	modTime := time.Date(2019, time.April, 4, 13, 18, 4, 0, time.Local)
+	require.Nil(t, os.Chtimes(testFile, modTime, modTime))
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	mds := []FileMetadata{EmptyFileMetadata()}
+	mds[0].File = testFile
+	mds[0].SetString("Artist", "a")
+	e.WriteMetadata(mds, WritePreserveModTime(true))
+	require.Nil(t, mds[0].Err)
+
+	info, err := os.Stat(testFile)
+	require.Nil(t, err)
+	assert.True(t, modTime.Equal(info.ModTime()))
+}
+
+func TestWriteArgsValidation(t *testing.T) {
+	var tcs = []struct {
+		tcID    string
+		inKey   string
+		inValue interface{}
+		expArgs []string
+		expErr  error
+	}{
+		{"groupQualified", "XMP-dc:Subject+", "a", []string{"-overwrite_original", "-XMP-dc:Subject+=a"}, nil},
+		{"multiline", "Comment", "l1\nl2\\", []string{"-overwrite_original", "-ec", `-Comment=l1\nl2\\`}, nil},
+		{"carriageReturn", "Comment", "l1\r\nl2", []string{"-overwrite_original", "-ec", `-Comment=l1\r\nl2`}, nil},
+		{"struct", "RegionInfo", map[string]interface{}{"RegionList": []interface{}{map[string]interface{}{"Name": "a,b"}}}, []string{"-overwrite_original", "-RegionInfo={RegionList=[{Name=a|,b}]}"}, nil},
+		{"backslashOnly", "Comment", `a\b`, []string{"-overwrite_original", `-Comment=a\b`}, nil},
+		{"keyInjection", "Comment\n-o", "a", nil, ErrInvalidTagKey},
+		{"keyDash", "-Comment", "a", nil, ErrInvalidTagKey},
+		{"keyAssignment", "Comment=a", "a", nil, ErrInvalidTagKey},
+		{"nulValue", "Comment", "a\x00b", nil, ErrInvalidTagValue},
+		{"multilinePath", "ThumbnailImage<", "a\n-o", nil, ErrInvalidTagValue},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			md := EmptyFileMetadata()
+			md.Fields[tc.inKey] = tc.inValue
+			args, err := (&Exiftool{}).writeArgs(md)
+			assert.True(t, errors.Is(err, tc.expErr))
+			assert.Equal(t, tc.expArgs, args)
+		})
+	}
+}
+
+func TestWriteAllowed(t *testing.T) {
+	var tcs = []struct {
+		tcID      string
+		inOpts    []func(*Exiftool) error
+		inKey     string
+		expResult bool
+	}{
+		{"noPolicy", nil, "FileName", true},
+		{"allowed", []func(*Exiftool) error{AllowWriteTags("Artist", "Key*")}, "Keywords+", true},
+		{"allowedCaseInsensitive", []func(*Exiftool) error{AllowWriteTags("artist")}, "EXIF:Artist", true},
+		{"notAllowed", []func(*Exiftool) error{AllowWriteTags("Artist")}, "FileName", false},
+		{"allowedGroup", []func(*Exiftool) error{AllowWriteTags("XMP-dc:*")}, "XMP-dc:Subject", true},
+		{"notAllowedGroup", []func(*Exiftool) error{AllowWriteTags("XMP-dc:*")}, "Subject", false},
+		{"denied", []func(*Exiftool) error{DenyWriteTags("File*")}, "System:FileName", false},
+		{"deniedSuffix", []func(*Exiftool) error{DenyWriteTags("Orientation")}, "Orientation#", false},
+		{"notDenied", []func(*Exiftool) error{DenyWriteTags("File*")}, "Artist", true},
+		{"deniedPrecedence", []func(*Exiftool) error{AllowWriteTags("*"), DenyWriteTags("Directory")}, "Directory", false},
+		{"noPolicyWildcard", nil, "XMP:*", true},
+		{"wildcardAllowed", []func(*Exiftool) error{AllowWriteTags("*")}, "Title?", false},
+		{"wildcardDenied", []func(*Exiftool) error{DenyWriteTags("FileName")}, "FileNam?", false},
+		{"wildcardGroupDenied", []func(*Exiftool) error{DenyWriteTags("System:*")}, "Sys*:File*", false},
+		{"noPolicyFromFile", nil, "Title<", true},
+		{"fromFileAllowedTag", []func(*Exiftool) error{AllowWriteTags("Title")}, "Title<", false},
+		{"fromFileNotDenied", []func(*Exiftool) error{DenyWriteTags("FileName")}, "Picture<", false},
+		{"fromFileAllowed", []func(*Exiftool) error{AllowWriteTags("Picture"), AllowWriteFromFiles()}, "Picture<", true},
+		{"fromFileAllowedOtherTag", []func(*Exiftool) error{AllowWriteTags("Picture"), AllowWriteFromFiles()}, "Title<", false},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			e := &Exiftool{}
+			for _, opt := range tc.inOpts {
+				require.Nil(t, opt(e))
+			}
+			assert.Equal(t, tc.expResult, e.writeAllowed(tc.inKey))
+		})
+	}
+}
+
+func TestWriteArgsNotAllowed(t *testing.T) {
+	e := &Exiftool{}
+	require.Nil(t, DenyWriteTags("FileName")(e))
+
+	md := EmptyFileMetadata()
+	md.SetString("Artist", "a")
+	md.SetString("FileName", "../../etc/passwd")
+	_, err := e.writeArgs(md)
+	assert.True(t, errors.Is(err, ErrTagNotAllowed))
+}
+
+func TestWriteArgsFromFileNotAllowed(t *testing.T) {
+	e := &Exiftool{}
+	require.Nil(t, AllowWriteTags("Title")(e))
+
+	md := EmptyFileMetadata()
+	md.SetBinaryFromFile("Title", "/etc/passwd")
+	_, err := e.writeArgs(md)
+	assert.True(t, errors.Is(err, ErrTagNotAllowed))
+
+	require.Nil(t, AllowWriteFromFiles()(e))
+	args, err := e.writeArgs(md)
+	require.Nil(t, err)
+	assert.Contains(t, args, "-Title<=/etc/passwd")
+}
+
+func TestWriteTagsInvalidPattern(t *testing.T) {
+	assert.NotNil(t, AllowWriteTags("[")(&Exiftool{}))
+	assert.NotNil(t, DenyWriteTags("[")(&Exiftool{}))
+}
+
+func TestSendCommandInvalidArgument(t *testing.T) {
+	_, err := (&Exiftool{}).sendCommand(OpExtract, []string{"-j", "a\nb.jpg"})
+	assert.True(t, errors.Is(err, ErrInvalidArgument))
+}
+
+func TestWriteMetadataMultiline(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	mds := []FileMetadata{EmptyFileMetadata()}
+	mds[0].File = testFile
+	mds[0].SetString("Comment", "line1\nline2 \\ -o")
+	e.WriteMetadata(mds)
+	require.Nil(t, mds[0].Err)
+
+	mds = e.ExtractMetadata(testFile)
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	got, err := mds[0].GetString("Comment")
+	require.Nil(t, err)
+	assert.Equal(t, "line1\nline2 \\ -o", got)
+}
+
+func TestWriteArgsDate(t *testing.T) {
+	md := EmptyFileMetadata()
+	md.SetDate("DateTimeOriginal", time.Date(2019, time.April, 4, 13, 18, 4, 0, time.UTC))
+
+	args, err := (&Exiftool{}).writeArgs(md)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"-overwrite_original", "-DateTimeOriginal=2019:04:04 13:18:04"}, args)
+
+	e := &Exiftool{}
+	require.Nil(t, DateFormant("%Y%m%d-%H%M%S")(e))
+	args, err = e.writeArgs(md)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"-overwrite_original", "-DateTimeOriginal=20190404-131804"}, args)
+
+	md = EmptyFileMetadata()
+	require.Nil(t, md.SetDateString("DateTimeOriginal", "2019:04:04 13:18:04.123+02:00"))
+	args, err = e.writeArgs(md)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"-overwrite_original", "-DateTimeOriginal=2019:04:04 13:18:04.123+02:00"}, args)
+}
+
+func TestWriteMetadataXMPSidecar(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+	sidecar := strings.TrimSuffix(testFile, ".jpg") + ".xmp"
+
+	e, err := NewExiftool(XMPSidecar())
+	require.Nil(t, err)
+	defer e.Close()
+
+	for _, title := range []string{"creation", "update"} {
+		mds := []FileMetadata{EmptyFileMetadata()}
+		mds[0].File = testFile
+		mds[0].SetString("Title", title)
+		e.WriteMetadata(mds)
+		require.Nil(t, mds[0].Err)
+
+		mds = e.ExtractMetadata(sidecar)
+		require.Len(t, mds, 1)
+		require.Nil(t, mds[0].Err)
+		got, err := mds[0].GetString("Title")
+		require.Nil(t, err)
+		assert.Equal(t, title, got)
+	}
+
+	mds := e.ExtractMetadata(testFile)
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	_, err = mds[0].GetString("Title")
+	assert.Equal(t, ErrKeyNotFound, err)
+}
+
+func TestWriteMetadataDryRun(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+
+	buf := bytes.Buffer{}
+	e, err := NewExiftool(DryRun(&buf))
+	require.Nil(t, err)
+	defer e.Close()
+
+	mds := []FileMetadata{EmptyFileMetadata(), EmptyFileMetadata()}
+	mds[0].File = testFile
+	mds[0].SetString("Artist", "dry run")
+	mds[1].File = testFile
+	mds[1].SetString("NotAnExifTag", "a")
+	e.WriteMetadata(mds)
+	assert.Nil(t, mds[0].Err)
+	assert.NotNil(t, mds[1].Err)
+	assert.Equal(t, testFile+` ["-overwrite_original" "-Artist=dry run"]`+"\n"+
+		testFile+` ["-overwrite_original" "-NotAnExifTag=a"]`+"\n", buf.String())
+
+	got := e.ExtractMetadata(testFile)
+	require.Len(t, got, 1)
+	_, err = got[0].GetString("Artist")
+	assert.Equal(t, ErrKeyNotFound, err)
+}
+
+func TestDryRunCommands(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join(t.TempDir(), "20190404_131804.jpg")
+	require.Nil(t, copyFile("testdata/20190404_131804.jpg", testFile))
+	orig, err := ioutil.ReadFile(testFile)
+	require.Nil(t, err)
+	thumbnail, err := ioutil.ReadFile("testdata/thumbnail.jpg")
+	require.Nil(t, err)
+
+	var buf bytes.Buffer
+	e, err := NewExiftool(DryRun(&buf))
+	require.Nil(t, err)
+	defer e.Close()
+
+	md := EmptyFileMetadata()
+	md.SetString("Artist", "dry")
+	jsonMd := NewFileMetadata(testFile).WithString("Artist", "dry")
+	var tcs = []struct {
+		tcID      string
+		inFn      func() error
+		expPrefix string
+	}{
+		{"writeMetadataBatch", func() error { return e.WriteMetadataBatch(md, testFile) }, `["-overwrite_original" "-Artist=dry"]`},
+		{"writeMetadataJSON", func() error { return e.WriteMetadataJSON([]FileMetadata{jsonMd}) }, `["-overwrite_original" "-json=`},
+		{"shiftDates", func() error { return e.ShiftDates(testFile, time.Hour) }, `["-overwrite_original" "-AllDates+=1:00:0"]`},
+		{"syncDates", func() error { return e.SyncDates(testFile, "DateTimeOriginal") }, `["-overwrite_original" "-CreateDate<DateTimeOriginal"`},
+		{"setThumbnail", func() error { return e.SetThumbnail(testFile, thumbnail) }, `["-overwrite_original" "-ThumbnailImage<=`},
+		{"deleteOriginals", func() error { return e.DeleteOriginals(testFile) }, `["-delete_original!"]`},
+		{"renameByTemplate", func() error {
+			_, err := e.RenameByTemplate([]string{testFile}, "%Y%m%d.%%e")
+			return err
+		}, `["-d" "%Y%m%d.%%e" "-TestName<DateTimeOriginal"]`},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			buf.Reset()
+			require.Nil(t, tc.inFn())
+			assert.True(t, strings.HasPrefix(buf.String(), testFile+" "+tc.expPrefix), buf.String())
+
+			got, err := ioutil.ReadFile(testFile)
+			require.Nil(t, err)
+			assert.Equal(t, orig, got)
+		})
+	}
+}
+
+func TestDryRunNilWriter(t *testing.T) {
+	_, err := NewExiftool(DryRun(nil))
+	assert.NotNil(t, err)
+}
+
+func TestSidecarPath(t *testing.T) {
+	assert.Equal(t, "a/b/IMG_0001.xmp", sidecarPath("a/b/IMG_0001.CR2"))
+	assert.Equal(t, "a.b/IMG_0001.xmp", sidecarPath("a.b/IMG_0001"))
+}
+
 func copyFile(src, dest string) (err error) {
 	s, err := os.Open(src)
 	if err != nil {
@@ -765,3 +1895,58 @@ func TestBufferTooSmallError(t *testing.T) {
 	assert.Len(t, fms, 1)
 	assert.Equal(t, ErrBufferTooSmall, fms[0].Err)
 }
+
+func TestChecksums(t *testing.T) {
+	t.Parallel()
+
+	eWithout, err := NewExiftool()
+	require.Nil(t, err)
+	defer eWithout.Close()
+	fms := eWithout.ExtractMetadata("./testdata/20190404_131804.jpg")
+	require.Len(t, fms, 1)
+	require.Nil(t, fms[0].Err)
+	assert.Nil(t, fms[0].Checksums)
+
+	eWith, err := NewExiftool(Checksums(crypto.MD5, crypto.SHA1, crypto.SHA256))
+	require.Nil(t, err)
+	defer eWith.Close()
+	fms = eWith.ExtractMetadata("./testdata/20190404_131804.jpg", "./testdata/nonExisting")
+	require.Len(t, fms, 2)
+	require.Nil(t, fms[0].Err)
+	assert.Equal(t, map[crypto.Hash]string{
+		crypto.MD5:    "5fe4911d10198fb61845d44ea28dfc11",
+		crypto.SHA1:   "e31450f57d55cbabbb7e4867b7fe45c4da1582f8",
+		crypto.SHA256: "ec2a1a958846fa348bf8484f88ce0b6d697c3bc1892ca289f169bc5a0daba4cd",
+	}, fms[0].Checksums)
+	assert.Equal(t, ErrNotExist, fms[1].Err)
+	assert.Nil(t, fms[1].Checksums)
+}
+
+func TestChecksumsUnavailableHash(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewExiftool(Checksums(crypto.MD4))
+	assert.NotNil(t, err)
+}
+
+func TestWithDefaultCharset(t *testing.T) {
+	var tcs = []struct {
+		tcID      string
+		inArgs    []string
+		inCharset string
+		expArgs   []string
+	}{
+		{"noDefault", []string{"-G0"}, "", []string{"-G0"}},
+		{"added", []string{"-G0"}, "utf8", []string{"-G0", "-charset", "filename=utf8"}},
+		{"noArgs", nil, "utf8", []string{"-charset", "filename=utf8"}},
+		{"overridden", []string{"-CHARSET", "FileName=cp1252"}, "utf8", []string{"-CHARSET", "FileName=cp1252"}},
+		{"otherCharset", []string{"-charset", "exif=utf8"}, "utf8", []string{"-charset", "exif=utf8", "-charset", "filename=utf8"}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			assert.Equal(t, tc.expArgs, withDefaultCharset(tc.inArgs, tc.inCharset))
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package exiftooltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/barasher/go-exiftool"
+)
+
+// GoldenCommand is a command sent to exiftool (its arguments, without -execute) and its raw response
+// (without the {ready} token)
+type GoldenCommand struct {
+	Args     []string `json:"args"`
+	Response string   `json:"response"`
+}
+
+// Recorder is an exiftool.Transport recording the commands sent through another transport (e.g.
+// exiftool.NewProcessTransport) and their responses, so that they can be saved as a golden file
+// (see Save) and replayed later without exiftool (see LoadGolden).
+// Sample :
+//   var tr exiftool.Transport
+//   if *update {
+//     rec := exiftooltest.NewRecorder(exiftool.NewProcessTransport("exiftool"))
+//     defer rec.Save("testdata/extract.golden.json")
+//     tr = rec
+//   } else {
+//     tr, err = exiftooltest.LoadGolden("testdata/extract.golden.json")
+//   }
+//   e, err := exiftool.NewExiftool(exiftool.UseTransport(tr))
+type Recorder struct {
+	inner    exiftool.Transport
+	lock     sync.Mutex
+	in       bytes.Buffer
+	out      bytes.Buffer
+	cmd      []string
+	pending  [][]string
+	commands []GoldenCommand
+}
+
+// NewRecorder creates a Recorder wrapping the given transport
+func NewRecorder(inner exiftool.Transport) *Recorder {
+	return &Recorder{inner: inner}
+}
+
+// Start implements exiftool.Transport
+func (r *Recorder) Start(args []string) (io.WriteCloser, io.ReadCloser, error) {
+	stdin, stdout, err := r.inner.Start(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return recordedStdin{WriteCloser: stdin, r: r}, recordedStdout{ReadCloser: stdout, r: r}, nil
+}
+
+// Wait implements exiftool.Transport
+func (r *Recorder) Wait() error {
+	return r.inner.Wait()
+}
+
+// Commands returns the recorded commands
+func (r *Recorder) Commands() []GoldenCommand {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]GoldenCommand(nil), r.commands...)
+}
+
+// Save writes the recorded commands to a golden file
+func (r *Recorder) Save(path string) error {
+	b, err := json.MarshalIndent(r.Commands(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error while marshaling golden commands: %w", err)
+	}
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		return fmt.Errorf("error while writing golden file %v: %w", path, err)
+	}
+	return nil
+}
+
+type recordedStdin struct {
+	io.WriteCloser
+	r *Recorder
+}
+
+// Write records the commands sent to exiftool
+func (s recordedStdin) Write(p []byte) (int, error) {
+	n, err := s.WriteCloser.Write(p)
+	s.r.lock.Lock()
+	defer s.r.lock.Unlock()
+	s.r.in.Write(p[:n])
+	for {
+		line, err := s.r.in.ReadString('\n')
+		if err != nil {
+			// incomplete line, kept for the next write
+			s.r.in.WriteString(line)
+			break
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if !executeRegexp.MatchString(line) {
+			s.r.cmd = append(s.r.cmd, line)
+			continue
+		}
+		s.r.pending = append(s.r.pending, s.r.cmd)
+		s.r.cmd = nil
+	}
+	return n, err
+}
+
+type recordedStdout struct {
+	io.ReadCloser
+	r *Recorder
+}
+
+// Read records the responses of exiftool, matching them with the pending commands
+func (s recordedStdout) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	s.r.lock.Lock()
+	defer s.r.lock.Unlock()
+	s.r.out.Write(p[:n])
+	for {
+		b := s.r.out.Bytes()
+		i := bytes.Index(b, readyToken)
+		if i < 0 {
+			break
+		}
+		end := i + len(readyToken)
+		if end < len(b) && b[end] == '\r' {
+			end++
+		}
+		if end >= len(b) || b[end] != '\n' {
+			// the end of the token hasn't been read yet
+			break
+		}
+		resp := string(b[:i])
+		s.r.out.Next(end + 1)
+		if len(s.r.pending) == 0 {
+			continue
+		}
+		s.r.commands = append(s.r.commands, GoldenCommand{Args: s.r.pending[0], Response: resp})
+		s.r.pending = s.r.pending[1:]
+	}
+	return n, err
+}
+
+var readyToken = []byte("{ready}")
+
+// LoadGolden returns a FakeTransport replaying the commands of a golden file (see Recorder):
+// commands are matched by their arguments, identical commands getting the recorded responses in
+// order (the last one being repeated). Unknown commands get an exiftool error output.
+func LoadGolden(path string) (*FakeTransport, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading golden file %v: %w", path, err)
+	}
+	var cmds []GoldenCommand
+	if err := json.Unmarshal(b, &cmds); err != nil {
+		return nil, fmt.Errorf("error while unmarshaling golden file %v: %w", path, err)
+	}
+
+	var lock sync.Mutex
+	responses := map[string][]string{}
+	for _, c := range cmds {
+		k := goldenKey(c.Args)
+		responses[k] = append(responses[k], c.Response)
+	}
+	return NewFakeTransport(func(args []string) (string, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		k := goldenKey(args)
+		resps := responses[k]
+		if len(resps) == 0 {
+			return fmt.Sprintf("Error: no golden response for %q\n", args), nil
+		}
+		if len(resps) > 1 {
+			responses[k] = resps[1:]
+		}
+		return resps[0], nil
+	}), nil
+}
+
+func goldenKey(args []string) string {
+	return strings.Join(args, "\n")
+}
@@ -0,0 +1,43 @@
+package exiftooltest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/barasher/go-exiftool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	const file = "../testdata/20190404_131804.jpg"
+	golden := filepath.Join(t.TempDir(), "extract.golden.json")
+
+	rec := NewRecorder(exiftool.NewProcessTransport("exiftool"))
+	e, err := exiftool.NewExiftool(exiftool.UseTransport(rec))
+	require.Nil(t, err)
+	recorded := e.ExtractMetadata(file, file)
+	require.Nil(t, recorded[0].Err)
+	require.Nil(t, e.Close())
+	require.Len(t, rec.Commands(), 2)
+	require.Nil(t, rec.Save(golden))
+
+	tr, err := LoadGolden(golden)
+	require.Nil(t, err)
+	e, err = exiftool.NewExiftool(exiftool.UseTransport(tr))
+	require.Nil(t, err)
+	defer e.Close()
+	replayed := e.ExtractMetadata(file, file, file, "../testdata/gps.jpg")
+	for i := 0; i < 3; i++ {
+		require.Nil(t, replayed[i].Err)
+		assert.Equal(t, recorded[0].Fields, replayed[i].Fields)
+	}
+	assert.NotNil(t, replayed[3].Err)
+}
+
+func TestLoadGoldenErrors(t *testing.T) {
+	_, err := LoadGolden("./doesNotExist.json")
+	assert.NotNil(t, err)
+	_, err = LoadGolden("../testdata/20190404_131804.jpg")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,121 @@
+// Package exiftooltest provides test doubles of go-exiftool, so that code depending on it can be
+// unit tested without exiftool.
+package exiftooltest
+
+import (
+	"sync"
+
+	"github.com/barasher/go-exiftool"
+)
+
+// Mock is an in-memory exiftool.MetadataReadWriter serving canned FileMetadata. It is safe for
+// concurrent use.
+// Sample :
+//   m := exiftooltest.NewMock(exiftool.NewFileMetadata("a.jpg").WithString("Make", "Canon"))
+//   m.SetError("b.jpg", exiftool.ErrNotFile)
+//   myCode(m) // myCode(r exiftool.MetadataReader)
+type Mock struct {
+	lock   sync.Mutex
+	files  map[string]exiftool.FileMetadata
+	errs   map[string]error
+	writes []exiftool.FileMetadata
+}
+
+var _ exiftool.MetadataReadWriter = (*Mock)(nil)
+
+// NewMock creates a Mock serving the given metadata, indexed by File
+func NewMock(fms ...exiftool.FileMetadata) *Mock {
+	m := Mock{
+		files: map[string]exiftool.FileMetadata{},
+		errs:  map[string]error{},
+	}
+	for _, fm := range fms {
+		m.Add(fm)
+	}
+	return &m
+}
+
+// Add serves (a copy of) the given metadata for fm.File, replacing the previous one
+func (m *Mock) Add(fm exiftool.FileMetadata) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	c := fm.Clone()
+	if c.Fields == nil {
+		c.Fields = map[string]interface{}{}
+	}
+	m.files[fm.File] = c
+}
+
+// SetError makes both the extraction and the writing of the file fail with err, nil removing the
+// error
+func (m *Mock) SetError(file string, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if err == nil {
+		delete(m.errs, file)
+		return
+	}
+	m.errs[file] = err
+}
+
+// ExtractMetadata returns copies of the served metadata, files that are not served getting
+// exiftool.ErrNotExist
+func (m *Mock) ExtractMetadata(files ...string) []exiftool.FileMetadata {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	fms := make([]exiftool.FileMetadata, len(files))
+	for i, f := range files {
+		if err, ok := m.errs[f]; ok {
+			fms[i] = exiftool.FileMetadata{File: f, Err: err}
+			continue
+		}
+		fm, ok := m.files[f]
+		if !ok {
+			fms[i] = exiftool.FileMetadata{File: f, Err: exiftool.ErrNotExist}
+			continue
+		}
+		fms[i] = fm.Clone()
+		fms[i].ResetChanges()
+	}
+	return fms
+}
+
+// WriteMetadata records the written metadata (see Writes) and applies its fields to the served
+// metadata, nil values removing fields. Files that are not served get exiftool.ErrNotExist. Write
+// options are ignored.
+func (m *Mock) WriteMetadata(fileMetadata []exiftool.FileMetadata, opts ...exiftool.WriteOption) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for i, md := range fileMetadata {
+		fileMetadata[i].Err = nil
+		m.writes = append(m.writes, md.Clone())
+		if err, ok := m.errs[md.File]; ok {
+			fileMetadata[i].Err = err
+			continue
+		}
+		fm, ok := m.files[md.File]
+		if !ok {
+			fileMetadata[i].Err = exiftool.ErrNotExist
+			continue
+		}
+		fm.Merge(md, exiftool.MergeOverwrite)
+		for k, v := range md.Fields {
+			if v == nil {
+				fm.Remove(k)
+			}
+		}
+	}
+}
+
+// Writes returns copies of the metadata given to WriteMetadata, in order
+func (m *Mock) Writes() []exiftool.FileMetadata {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	writes := make([]exiftool.FileMetadata, len(m.writes))
+	for i, w := range m.writes {
+		writes[i] = w.Clone()
+	}
+	return writes
+}
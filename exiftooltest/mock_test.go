@@ -0,0 +1,64 @@
+package exiftooltest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/barasher/go-exiftool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockExtract(t *testing.T) {
+	m := NewMock(exiftool.NewFileMetadata("a.jpg").WithString("Make", "Canon"))
+	m.SetError("b.jpg", exiftool.ErrNotFile)
+
+	fms := m.ExtractMetadata("a.jpg", "b.jpg", "c.jpg")
+	require.Len(t, fms, 3)
+	assert.Nil(t, fms[0].Err)
+	assert.Equal(t, "Canon", fms[0].GetStringDefault("Make", ""))
+	assert.Empty(t, fms[0].Changed())
+	assert.Equal(t, exiftool.ErrNotFile, fms[1].Err)
+	assert.Equal(t, exiftool.ErrNotExist, fms[2].Err)
+
+	// results are copies
+	fms[0].SetString("Make", "Nikon")
+	assert.Equal(t, "Canon", m.ExtractMetadata("a.jpg")[0].GetStringDefault("Make", ""))
+
+	m.SetError("b.jpg", nil)
+	assert.Equal(t, exiftool.ErrNotExist, m.ExtractMetadata("b.jpg")[0].Err)
+}
+
+func TestMockWrite(t *testing.T) {
+	m := NewMock(
+		exiftool.NewFileMetadata("a.jpg").WithString("Make", "Canon").WithString("Model", "EOS"),
+		exiftool.FileMetadata{File: "b.jpg"},
+	)
+	m.SetError("c.jpg", errors.New("error"))
+
+	mds := []exiftool.FileMetadata{
+		exiftool.NewFileMetadata("a.jpg").WithString("Artist", "John").WithCleared("Model"),
+		exiftool.NewFileMetadata("b.jpg").WithInt("Rating", 5),
+		exiftool.NewFileMetadata("c.jpg"),
+		exiftool.NewFileMetadata("d.jpg"),
+	}
+	m.WriteMetadata(mds)
+	assert.Nil(t, mds[0].Err)
+	assert.Nil(t, mds[1].Err)
+	assert.NotNil(t, mds[2].Err)
+	assert.Equal(t, exiftool.ErrNotExist, mds[3].Err)
+
+	fms := m.ExtractMetadata("a.jpg", "b.jpg")
+	assert.Equal(t, map[string]interface{}{"Make": "Canon", "Artist": "John"}, fms[0].Fields)
+	assert.Equal(t, int64(5), fms[1].GetIntDefault("Rating", 0))
+
+	writes := m.Writes()
+	require.Len(t, writes, 4)
+	assert.Equal(t, "a.jpg", writes[0].File)
+	assert.Nil(t, writes[0].Fields["Model"])
+}
+
+func TestMockImplementsInterfaces(t *testing.T) {
+	var rw exiftool.MetadataReadWriter = NewMock()
+	assert.NotNil(t, rw)
+}
@@ -0,0 +1,139 @@
+package exiftooltest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ErrCrash can be returned by a Handler to simulate a crash of exiftool: its output is closed
+// without responding
+var ErrCrash = errors.New("simulated exiftool crash")
+
+// Handler returns the raw output of exiftool for a command (its arguments, without -execute), the
+// {ready} token being appended by the FakeTransport
+type Handler func(args []string) (string, error)
+
+// FakeTransport is an exiftool.Transport speaking the -stay_open protocol without exiftool,
+// responses being computed by a Handler. It can be started again once stopped, e.g. after a
+// simulated crash, to test restarts (see exiftool.AutoRestart).
+// Sample :
+//   t := exiftooltest.NewFakeTransport(exiftooltest.FileFixtures(map[string]string{
+//     "a.jpg": `[{"SourceFile": "a.jpg", "Make": "Canon"}]`,
+//   }))
+//   e, err := exiftool.NewExiftool(exiftool.UseTransport(t))
+type FakeTransport struct {
+	handler  Handler
+	lock     sync.Mutex
+	args     []string
+	commands [][]string
+	done     chan struct{}
+}
+
+// NewFakeTransport creates a FakeTransport responding with the handler
+func NewFakeTransport(h Handler) *FakeTransport {
+	return &FakeTransport{handler: h}
+}
+
+var executeRegexp = regexp.MustCompile(`^-execute\d*$`)
+
+// Start implements exiftool.Transport
+func (t *FakeTransport) Start(args []string) (io.WriteCloser, io.ReadCloser, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.done != nil {
+		select {
+		case <-t.done:
+		default:
+			return nil, nil, errors.New("fake transport already started")
+		}
+	}
+	t.args = append([]string(nil), args...)
+	t.done = make(chan struct{})
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	go t.serve(inR, outW)
+	return inW, outR, nil
+}
+
+func (t *FakeTransport) serve(in *io.PipeReader, out *io.PipeWriter) {
+	defer close(t.done)
+	defer in.Close()
+	defer out.Close()
+
+	var cmd []string
+	s := bufio.NewScanner(in)
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), "\r")
+		if !executeRegexp.MatchString(line) {
+			cmd = append(cmd, line)
+			continue
+		}
+		if len(cmd) == 2 && strings.EqualFold(cmd[0], "-stay_open") && strings.EqualFold(cmd[1], "False") {
+			return
+		}
+
+		t.lock.Lock()
+		t.commands = append(t.commands, cmd)
+		t.lock.Unlock()
+
+		resp, err := t.handler(cmd)
+		if err != nil {
+			return
+		}
+		if _, err := io.WriteString(out, resp+"{ready}\n"); err != nil {
+			return
+		}
+		cmd = nil
+	}
+}
+
+// Wait implements exiftool.Transport
+func (t *FakeTransport) Wait() error {
+	t.lock.Lock()
+	done := t.done
+	t.lock.Unlock()
+	if done == nil {
+		return errors.New("fake transport not started")
+	}
+	<-done
+	return nil
+}
+
+// Args returns the arguments the transport has been started with
+func (t *FakeTransport) Args() []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]string(nil), t.args...)
+}
+
+// Commands returns the commands received by the transport (without -execute), in order
+func (t *FakeTransport) Commands() [][]string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	cmds := make([][]string, len(t.commands))
+	for i, c := range t.commands {
+		cmds[i] = append([]string(nil), c...)
+	}
+	return cmds
+}
+
+// FileFixtures returns a Handler responding with the output recorded for the file, which is the
+// last argument of the command. Unknown files get exiftool's "File not found" error output.
+func FileFixtures(fixtures map[string]string) Handler {
+	return func(args []string) (string, error) {
+		if len(args) == 0 {
+			return "", nil
+		}
+		f := args[len(args)-1]
+		if resp, ok := fixtures[f]; ok {
+			return resp, nil
+		}
+		return fmt.Sprintf("Error: File not found - %v\n", f), nil
+	}
+}
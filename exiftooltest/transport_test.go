@@ -0,0 +1,89 @@
+package exiftooltest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/barasher/go-exiftool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeTransport(t *testing.T) {
+	// files are checked before being sent to exiftool
+	const a, b = "../testdata/20190404_131804.jpg", "../testdata/gps.jpg"
+	tr := NewFakeTransport(FileFixtures(map[string]string{
+		a: `[{"SourceFile": "a.jpg", "Make": "Canon"}]`,
+		b: `    1 image files updated` + "\n",
+	}))
+	e, err := exiftool.NewExiftool(exiftool.UseTransport(tr), exiftool.PrintGroupNames("0"))
+	require.Nil(t, err)
+
+	fms := e.ExtractMetadata(a)
+	require.Nil(t, fms[0].Err)
+	assert.Equal(t, "Canon", fms[0].GetStringDefault("Make", ""))
+
+	require.Nil(t, e.WriteMetadataBatch(exiftool.NewFileMetadata("").WithString("Artist", "John"), b))
+
+	require.Nil(t, e.Close())
+	assert.Equal(t, []string{"-stay_open", "True", "-@", "-", "-common_args", "-G0"}, tr.Args()[:6])
+	cmds := tr.Commands()
+	require.Len(t, cmds, 2)
+	assert.Equal(t, a, cmds[0][len(cmds[0])-1])
+	assert.Contains(t, cmds[1], "-Artist=John")
+}
+
+func TestFakeTransportCrash(t *testing.T) {
+	tr := NewFakeTransport(func(args []string) (string, error) {
+		return "", ErrCrash
+	})
+	var evs []exiftool.Event
+	e, err := exiftool.NewExiftool(exiftool.UseTransport(tr), exiftool.OnEvent(func(ev exiftool.Event, err error) {
+		evs = append(evs, ev)
+	}))
+	require.Nil(t, err)
+
+	fms := e.ExtractMetadata("../testdata/20190404_131804.jpg")
+	assert.NotNil(t, fms[0].Err)
+	e.Close()
+	assert.Equal(t, []exiftool.Event{exiftool.EventStarted, exiftool.EventCrashed, exiftool.EventClosed}, evs)
+}
+
+func TestFakeTransportRestart(t *testing.T) {
+	const a, b = "../testdata/20190404_131804.jpg", "../testdata/gps.jpg"
+	crashed := false
+	tr := NewFakeTransport(func(args []string) (string, error) {
+		switch {
+		case args[len(args)-1] == a && !crashed:
+			crashed = true
+			return "", ErrCrash
+		case args[len(args)-1] == b:
+			// bigger than the buffer
+			return `[{"SourceFile": "b.jpg", "Comment": "` + strings.Repeat("a", 300) + `"}]`, nil
+		default:
+			return `[{"SourceFile": "a.jpg", "Make": "Canon"}]`, nil
+		}
+	})
+	e, err := exiftool.NewExiftool(exiftool.UseTransport(tr), exiftool.AutoRestart(), exiftool.Buffer(make([]byte, 128), 256))
+	require.Nil(t, err)
+	defer e.Close()
+
+	fms := e.ExtractMetadata(a, a, b, a)
+	assert.NotNil(t, fms[0].Err)
+	assert.Nil(t, fms[1].Err)
+	assert.Equal(t, exiftool.ErrBufferTooSmall, fms[2].Err)
+	require.Nil(t, fms[3].Err)
+	assert.Equal(t, "Canon", fms[3].GetStringDefault("Make", ""))
+	assert.Equal(t, int64(2), e.Stats().Restarts)
+	assert.Len(t, tr.Commands(), 4)
+}
+
+func TestFakeTransportStartedTwice(t *testing.T) {
+	tr := NewFakeTransport(FileFixtures(nil))
+	assert.NotNil(t, tr.Wait())
+	e, err := exiftool.NewExiftool(exiftool.UseTransport(tr))
+	require.Nil(t, err)
+	defer e.Close()
+	_, err = exiftool.NewExiftool(exiftool.UseTransport(tr))
+	assert.NotNil(t, err)
+}
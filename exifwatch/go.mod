@@ -0,0 +1,17 @@
+module github.com/barasher/go-exiftool/exifwatch
+
+go 1.17
+
+require (
+	github.com/barasher/go-exiftool v0.0.0-00010101000000-000000000000
+	github.com/fsnotify/fsnotify v1.9.0
+	github.com/stretchr/testify v1.3.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+)
+
+replace github.com/barasher/go-exiftool => ../
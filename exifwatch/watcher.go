@@ -0,0 +1,287 @@
+// Package exifwatch monitors folders and extracts the metadata of the files that are created or
+// modified, e.g. to implement auto-import features.
+//
+// Folders, and their sub-folders, are watched with the notification API of the OS (through
+// fsnotify): a file is reported once it hasn't changed for an interval, so that files being copied
+// are only extracted once complete.
+//
+// exifwatch is a separate module, so that go-exiftool itself doesn't depend on fsnotify.
+package exifwatch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/barasher/go-exiftool"
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultInterval is the default interval a file has to stay unchanged to be reported
+const DefaultInterval = 2 * time.Second
+
+// Watcher monitors folders recursively and extracts the metadata of new or modified files
+type Watcher struct {
+	et         *exiftool.Exiftool
+	dirs       []string
+	interval   time.Duration
+	extensions map[string]bool
+	initial    bool
+}
+
+// fileState is the state of a file when it was last checked
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// pending is a file that has been created or modified, reported once stable
+type pending struct {
+	state   fileState
+	changed time.Time
+}
+
+// NewWatcher creates a watcher of the given folders, whose metadata is extracted with et
+// Sample :
+//   w, err := NewWatcher(et, []string{"/srv/inbox"}, Extensions(".jpg", ".mp4"))
+//   for fm := range w.Watch(ctx) {
+//     ...
+//   }
+func NewWatcher(et *exiftool.Exiftool, dirs []string, opts ...func(*Watcher) error) (*Watcher, error) {
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no folder to watch")
+	}
+	for _, d := range dirs {
+		s, err := os.Stat(d)
+		if err != nil {
+			return nil, fmt.Errorf("error while checking folder %v: %w", d, err)
+		}
+		if !s.IsDir() {
+			return nil, fmt.Errorf("%v is not a folder", d)
+		}
+	}
+	w := Watcher{
+		et:       et,
+		dirs:     dirs,
+		interval: DefaultInterval,
+	}
+	for _, opt := range opts {
+		if err := opt(&w); err != nil {
+			return nil, fmt.Errorf("error when configuring watcher: %w", err)
+		}
+	}
+	return &w, nil
+}
+
+// Interval defines the interval a file has to stay unchanged to be reported, DefaultInterval by
+// default
+func Interval(d time.Duration) func(*Watcher) error {
+	return func(w *Watcher) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid interval: %v", d)
+		}
+		w.interval = d
+		return nil
+	}
+}
+
+// Extensions restricts the watched files to the ones with the given extensions (case insensitive,
+// e.g. ".jpg")
+func Extensions(exts ...string) func(*Watcher) error {
+	return func(w *Watcher) error {
+		if w.extensions == nil {
+			w.extensions = make(map[string]bool)
+		}
+		for _, e := range exts {
+			w.extensions[strings.ToLower(e)] = true
+		}
+		return nil
+	}
+}
+
+// InitialScan reports the files already present when the watch starts, which are ignored otherwise
+func InitialScan() func(*Watcher) error {
+	return func(w *Watcher) error {
+		w.initial = true
+		return nil
+	}
+}
+
+// Watch watches the folders until the context is done, then closes the returned channel. The
+// metadata of each created or modified file is sent to the channel, as well as the errors that
+// occur while watching a folder (in FileMetadata.Err, File being the entry that can't be watched)
+// and the errors of the notification API (File being empty, e.g. when events have been lost
+// because of an overflow). The channel has to be consumed for the watch to go on.
+func (w *Watcher) Watch(ctx context.Context) <-chan exiftool.FileMetadata {
+	ch := make(chan exiftool.FileMetadata)
+
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		go func() {
+			defer close(ch)
+			send(ctx, ch, exiftool.FileMetadata{Err: fmt.Errorf("error while creating watcher: %w", err)})
+		}()
+		return ch
+	}
+
+	// folders are watched before Watch returns, so that no file created afterwards is missed
+	pendings := map[string]*pending{}
+	var errs []exiftool.FileMetadata
+	for _, d := range w.dirs {
+		errs = append(errs, w.addTree(fw, d, pendings, w.initial)...)
+	}
+
+	go func() {
+		defer close(ch)
+		defer fw.Close()
+
+		for _, fm := range errs {
+			if !send(ctx, ch, fm) {
+				return
+			}
+		}
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			var fms []exiftool.FileMetadata
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-fw.Events:
+				if !ok {
+					return
+				}
+				fms = w.handle(fw, ev, pendings)
+			case err, ok := <-fw.Errors:
+				if !ok {
+					return
+				}
+				fms = []exiftool.FileMetadata{{Err: err}}
+			case <-ticker.C:
+				if ready := w.ready(pendings); len(ready) > 0 {
+					fms = w.et.ExtractMetadataContext(ctx, ready...)
+				}
+			}
+			for _, fm := range fms {
+				if !send(ctx, ch, fm) {
+					return
+				}
+			}
+		}
+	}()
+	return ch
+}
+
+func send(ctx context.Context, ch chan<- exiftool.FileMetadata, fm exiftool.FileMetadata) bool {
+	select {
+	case ch <- fm:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// addTree watches a folder and its sub-folders. If report is set, their files are added to the
+// pending files. Entries that can't be read or watched are skipped, so that the other ones are
+// still watched, and returned as errors.
+func (w *Watcher) addTree(fw *fsnotify.Watcher, root string, pendings map[string]*pending, report bool) []exiftool.FileMetadata {
+	var errs []exiftool.FileMetadata
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if !os.IsNotExist(err) {
+				errs = append(errs, exiftool.FileMetadata{File: path, Err: err})
+			}
+			if info != nil && info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			if err := fw.Add(path); err != nil {
+				errs = append(errs, exiftool.FileMetadata{File: path, Err: fmt.Errorf("error while watching: %w", err)})
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if report && w.watched(path, info) {
+			pendings[path] = &pending{state: stateOf(info), changed: time.Now()}
+		}
+		return nil
+	})
+	return errs
+}
+
+// handle updates the pending files with an event, returning the errors to report
+func (w *Watcher) handle(fw *fsnotify.Watcher, ev fsnotify.Event, pendings map[string]*pending) []exiftool.FileMetadata {
+	if ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename) {
+		// the new name of a renamed file gets its own event
+		prefix := ev.Name + string(filepath.Separator)
+		for f := range pendings {
+			if f == ev.Name || strings.HasPrefix(f, prefix) {
+				delete(pendings, f)
+			}
+		}
+	}
+	if !ev.Has(fsnotify.Create) && !ev.Has(fsnotify.Write) && !ev.Has(fsnotify.Chmod) {
+		return nil
+	}
+
+	info, err := os.Lstat(ev.Name)
+	if err != nil {
+		// removed since the event
+		return nil
+	}
+	if info.IsDir() {
+		if ev.Has(fsnotify.Create) {
+			// files may have been created before the folder was watched
+			return w.addTree(fw, ev.Name, pendings, true)
+		}
+		return nil
+	}
+	if w.watched(ev.Name, info) {
+		pendings[ev.Name] = &pending{state: stateOf(info), changed: time.Now()}
+	}
+	return nil
+}
+
+// ready returns the pending files that haven't changed for the interval, sorted
+func (w *Watcher) ready(pendings map[string]*pending) []string {
+	var ready []string
+	now := time.Now()
+	for f, p := range pendings {
+		if now.Sub(p.changed) < w.interval {
+			continue
+		}
+		info, err := os.Lstat(f)
+		if err != nil {
+			delete(pendings, f)
+			continue
+		}
+		if s := stateOf(info); s != p.state {
+			p.state = s
+			p.changed = now
+			continue
+		}
+		ready = append(ready, f)
+		delete(pendings, f)
+	}
+	sort.Strings(ready)
+	return ready
+}
+
+// watched tells if a file has to be reported
+func (w *Watcher) watched(path string, info os.FileInfo) bool {
+	if !info.Mode().IsRegular() {
+		return false
+	}
+	return w.extensions == nil || w.extensions[strings.ToLower(filepath.Ext(path))]
+}
+
+func stateOf(info os.FileInfo) fileState {
+	return fileState{size: info.Size(), modTime: info.ModTime()}
+}
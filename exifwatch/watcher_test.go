@@ -0,0 +1,162 @@
+package exifwatch
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/barasher/go-exiftool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFile = "../testdata/20190404_131804.jpg"
+
+func copyTestFile(t *testing.T, dst string) {
+	content, err := ioutil.ReadFile(testFile)
+	require.Nil(t, err)
+	require.Nil(t, ioutil.WriteFile(dst, content, 0644))
+}
+
+func next(t *testing.T, ch <-chan exiftool.FileMetadata) exiftool.FileMetadata {
+	select {
+	case fm := <-ch:
+		return fm
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no event")
+	}
+	return exiftool.FileMetadata{}
+}
+
+func TestNewWatcherErrors(t *testing.T) {
+	var tcs = []struct {
+		tcID   string
+		inDirs []string
+		inOpts []func(*Watcher) error
+	}{
+		{"noFolder", nil, nil},
+		{"missingFolder", []string{"./doesNotExist"}, nil},
+		{"file", []string{testFile}, nil},
+		{"invalidInterval", []string{"."}, []func(*Watcher) error{Interval(0)}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			_, err := NewWatcher(nil, tc.inDirs, tc.inOpts...)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.jpg")
+	copyTestFile(t, existing)
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "ignored.txt"), []byte("a"), 0644))
+
+	et, err := exiftool.NewExiftool()
+	require.Nil(t, err)
+	defer et.Close()
+
+	w, err := NewWatcher(et, []string{dir}, Interval(20*time.Millisecond), Extensions(".JPG"))
+	require.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := w.Watch(ctx)
+
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	created := filepath.Join(dir, "sub", "created.jpg")
+	copyTestFile(t, created)
+	fm := next(t, ch)
+	assert.Equal(t, created, fm.File)
+	assert.Nil(t, fm.Err)
+	assert.NotEmpty(t, fm.Fields)
+
+	later := time.Now().Add(time.Hour)
+	require.Nil(t, os.Chtimes(existing, later, later))
+	fm = next(t, ch)
+	assert.Equal(t, existing, fm.File)
+
+	cancel()
+	for range ch {
+	}
+}
+
+func TestWatchInitialScan(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.jpg")
+	copyTestFile(t, existing)
+
+	et, err := exiftool.NewExiftool()
+	require.Nil(t, err)
+	defer et.Close()
+
+	w, err := NewWatcher(et, []string{dir}, Interval(20*time.Millisecond), InitialScan())
+	require.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fm := next(t, w.Watch(ctx))
+	assert.Equal(t, existing, fm.File)
+}
+
+func TestWatchUnreadableFolder(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced")
+	}
+	dir := t.TempDir()
+	locked := filepath.Join(dir, "a")
+	require.Nil(t, os.Mkdir(locked, 0755))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "b.jpg"), nil, 0644))
+	require.Nil(t, os.Chmod(locked, 0))
+	defer os.Chmod(locked, 0755)
+
+	et, err := exiftool.NewExiftool()
+	require.Nil(t, err)
+	defer et.Close()
+
+	w, err := NewWatcher(et, []string{dir}, Interval(20*time.Millisecond), InitialScan())
+	require.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := w.Watch(ctx)
+
+	fm := next(t, ch)
+	assert.Equal(t, locked, fm.File)
+	assert.NotNil(t, fm.Err)
+	fm = next(t, ch)
+	assert.Equal(t, filepath.Join(dir, "b.jpg"), fm.File)
+}
+
+func TestReady(t *testing.T) {
+	dir := t.TempDir()
+	stable := filepath.Join(dir, "stable.jpg")
+	growing := filepath.Join(dir, "growing.jpg")
+	recent := filepath.Join(dir, "recent.jpg")
+	for _, f := range []string{stable, growing, recent} {
+		require.Nil(t, ioutil.WriteFile(f, []byte("a"), 0644))
+	}
+	state := func(f string) fileState {
+		info, err := os.Lstat(f)
+		require.Nil(t, err)
+		return stateOf(info)
+	}
+
+	w, err := NewWatcher(nil, []string{dir}, Interval(time.Minute))
+	require.Nil(t, err)
+	old := time.Now().Add(-2 * time.Minute)
+	pendings := map[string]*pending{
+		stable:                            {state: state(stable), changed: old},
+		growing:                           {state: fileState{size: 0}, changed: old},
+		recent:                            {state: state(recent), changed: time.Now()},
+		filepath.Join(dir, "removed.jpg"): {changed: old},
+	}
+	assert.Equal(t, []string{stable}, w.ready(pendings))
+	assert.Len(t, pendings, 2)
+	assert.Contains(t, pendings, growing)
+	assert.Contains(t, pendings, recent)
+}
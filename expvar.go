@@ -0,0 +1,47 @@
+package exiftool
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+)
+
+// PublishExpvar publishes the statistics of the instance (see Stats), including its queue depth
+// and restarts, as an expvar variable with the given name, so that they are exposed by /debug/vars. expvar variables can't be removed: the name
+// has to be unique in the process and the statistics of a closed instance remain published.
+// Sample :
+//   e, err := NewExiftool(PublishExpvar("exiftool.thumbnails"))
+func PublishExpvar(name string) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		if name == "" {
+			return errors.New("expvar name can't be empty")
+		}
+		if expvar.Get(name) != nil {
+			return fmt.Errorf("expvar %q is already published", name)
+		}
+		expvar.Publish(name, expvar.Func(e.expvarValue))
+		e.expvarName = name
+		return nil
+	}
+}
+
+// expvarValue is the value of the published expvar variable
+func (e *Exiftool) expvarValue() interface{} {
+	s := e.Stats()
+	lastError := ""
+	if s.LastError != nil {
+		lastError = s.LastError.Error()
+	}
+	return map[string]interface{}{
+		"calls":            s.Calls,
+		"commands":         s.Commands,
+		"files":            s.Files,
+		"failedFiles":      s.FailedFiles,
+		"bytesRead":        s.BytesRead,
+		"averageLatencyMs": s.AverageLatency.Milliseconds(),
+		"uptimeSeconds":    int64(s.Uptime.Seconds()),
+		"restarts":         s.Restarts,
+		"queueDepth":       s.Waiting,
+		"lastError":        lastError,
+	}
+}
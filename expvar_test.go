@@ -0,0 +1,36 @@
+package exiftool
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool(PublishExpvar("go-exiftool.test"))
+	require.Nil(t, err)
+	defer e.Close()
+
+	e.ExtractMetadata("./testdata/20190404_131804.jpg", "./testdata/nonExisting.jpg")
+
+	v := expvar.Get("go-exiftool.test")
+	require.NotNil(t, v)
+	var got map[string]interface{}
+	require.Nil(t, json.Unmarshal([]byte(v.String()), &got))
+	assert.Equal(t, float64(1), got["calls"])
+	assert.Equal(t, float64(2), got["files"])
+	assert.Equal(t, float64(1), got["failedFiles"])
+	assert.Equal(t, ErrNotExist.Error(), got["lastError"])
+	assert.Equal(t, float64(0), got["restarts"])
+	assert.Equal(t, float64(0), got["queueDepth"])
+
+	_, err = NewExiftool(PublishExpvar("go-exiftool.test"))
+	assert.NotNil(t, err)
+	_, err = NewExiftool(PublishExpvar(""))
+	assert.NotNil(t, err)
+}
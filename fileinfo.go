@@ -0,0 +1,51 @@
+package exiftool
+
+import (
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// FileType returns the type of the file as detected by exiftool (e.g. "JPEG", "MP4"), falling
+// back on the upper cased extension of the file name. "" is returned if it can't be determined.
+func (fm FileMetadata) FileType() string {
+	fm.lenientKeys = true
+	if t := fm.firstString("File:FileType"); t != "" {
+		return t
+	}
+	return strings.ToUpper(fm.fileExtension())
+}
+
+// MIMEType returns the MIME type of the file as detected by exiftool (e.g. "image/jpeg"), falling
+// back on the type associated to the extension of the file name. "" is returned if it can't be
+// determined.
+func (fm FileMetadata) MIMEType() string {
+	fm.lenientKeys = true
+	if t := fm.firstString("File:MIMEType"); t != "" {
+		return t
+	}
+	if ext := fm.Extension(); ext != "" {
+		t := mime.TypeByExtension("." + ext)
+		if idx := strings.Index(t, ";"); idx != -1 {
+			t = t[:idx]
+		}
+		return t
+	}
+	return ""
+}
+
+// Extension returns the lower cased extension, without leading dot, matching the type of the file
+// as detected by exiftool (e.g. "jpg", which may differ from the actual extension of a misnamed
+// file), falling back on the extension of the file name. "" is returned if it can't be determined.
+func (fm FileMetadata) Extension() string {
+	fm.lenientKeys = true
+	if ext := fm.firstString("File:FileTypeExtension"); ext != "" {
+		return strings.ToLower(ext)
+	}
+	return strings.ToLower(fm.fileExtension())
+}
+
+// fileExtension returns the extension of the file name, without leading dot
+func (fm FileMetadata) fileExtension() string {
+	return strings.TrimPrefix(filepath.Ext(fm.File), ".")
+}
@@ -0,0 +1,36 @@
+package exiftool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileInfo(t *testing.T) {
+	var tcs = []struct {
+		tcID     string
+		inFile   string
+		inFields map[string]interface{}
+		expType  string
+		expMIME  string
+		expExt   string
+	}{
+		{"extracted", "a.jpeg", map[string]interface{}{"FileType": "JPEG", "MIMEType": "image/jpeg", "FileTypeExtension": "jpg"}, "JPEG", "image/jpeg", "jpg"},
+		{"grouped", "a.mov", map[string]interface{}{"File:FileType": "MP4", "File:MIMEType": "video/mp4", "File:FileTypeExtension": "MP4"}, "MP4", "video/mp4", "mp4"},
+		{"fallback", "dir/a.PNG", map[string]interface{}{}, "PNG", "image/png", "png"},
+		{"unknownExtension", "a.zzz", map[string]interface{}{}, "ZZZ", "", "zzz"},
+		{"noExtension", "a", map[string]interface{}{}, "", "", ""},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			fm := EmptyFileMetadata()
+			fm.File = tc.inFile
+			fm.Fields = tc.inFields
+			assert.Equal(t, tc.expType, fm.FileType())
+			assert.Equal(t, tc.expMIME, fm.MIMEType())
+			assert.Equal(t, tc.expExt, fm.Extension())
+		})
+	}
+}
@@ -1,33 +1,157 @@
 package exiftool
 
 import (
+	"crypto"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
 const (
 	defaultString = ""
 	defaultFloat  = float64(0)
 	defaultInt    = int64(0)
+	base64Prefix  = "base64:"
 )
 
+// exifDateLayout is the layout exiftool uses for date/time values when no date format is configured
+const exifDateLayout = "2006:01:02 15:04:05"
+
+// exifDateStringLayouts are the layouts accepted by SetDateString
+var exifDateStringLayouts = []string{
+	"2006:01:02 15:04:05",
+	"2006:01:02 15:04:05Z07:00",
+	"2006:01:02 15:04:05.999999999",
+	"2006:01:02 15:04:05.999999999Z07:00",
+}
+
+// exifDateParseLayouts are the layouts of the dates output by exiftool, accepted by GetDateTime
+var exifDateParseLayouts = append([]string{"2006:01:02"}, exifDateStringLayouts...)
+
 // ErrKeyNotFound is a sentinel error used when a queried key does not exist
 var ErrKeyNotFound = errors.New("key not found")
 
+// ErrNotBinary is a sentinel error used when a queried field does not contain binary data
+var ErrNotBinary = errors.New("field does not contain binary data")
+
+// ErrNotDate is a sentinel error used when a queried field does not contain a date
+var ErrNotDate = errors.New("field does not contain a date")
+
 // FileMetadata is a structure that represents an exiftool extraction. File contains the
 // filename that had to be extracted. If anything went wrong, Err will not be nil. Fields
-// stores extracted fields.
+// stores extracted fields. Checksums stores the hex encoded file hashes when the
+// Checksums option is enabled. WriteResult stores the outcome of WriteMetadata, which tells
+// updated files from unchanged ones (Err is not nil for the latter). WriteOptions override, for
+// this file only, the way it is written by WriteMetadata.
 type FileMetadata struct {
-	File   string
-	Fields map[string]interface{}
-	Err    error
+	File         string
+	Fields       map[string]interface{}
+	Checksums    map[crypto.Hash]string
+	Err          error
+	WriteResult  *WriteResult
+	WriteOptions []WriteOption
+	order        []string
+	dateFormat   string
+	dateLayouts  []string
+	lenientKeys  bool
+	changed      map[string]bool
+}
+
+// Field is a key / value pair of a FileMetadata
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// TagKey returns the group qualified key of a tag (e.g. TagKey("XMP-dc", "Title") returns "XMP-dc:Title").
+// Group qualified keys can be used with any setter to write a tag in a specific group, which is
+// needed when the same tag exists in several groups (IPTC:Keywords and XMP-dc:Subject, ...).
+func TagKey(group string, tag string) string {
+	if group == "" {
+		return tag
+	}
+	return group + ":" + tag
+}
+
+// SplitTagKey splits a possibly group qualified key (as produced by the PrintGroupNames option)
+// into its group(s) and tag name (e.g. "File:Image:ImageWidth" gives "File:Image" and "ImageWidth").
+func SplitTagKey(k string) (group string, tag string) {
+	idx := strings.LastIndex(k, ":")
+	if idx == -1 {
+		return "", k
+	}
+	return k[:idx], k[idx+1:]
+}
+
+// ResolveKey returns the key of Fields matching k: k itself if it exists, otherwise the key
+// matching case insensitively, ignoring the group of the keys (e.g. "createdate" resolves to
+// "QuickTime:CreateDate" when group names are printed), or ignoring the group of k (e.g.
+// "EXIF:CreateDate" resolves to "CreateDate" when they are not). When several keys match,
+// the first one in alphabetical order is returned.
+func (fm FileMetadata) ResolveKey(k string) (string, bool) {
+	if _, found := fm.Fields[k]; found {
+		return k, true
+	}
+
+	keys := make([]string, 0, len(fm.Fields))
+	for key := range fm.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	_, tag := SplitTagKey(k)
+	matchers := []func(key string) bool{
+		// same key with a different case
+		func(key string) bool {
+			return strings.EqualFold(key, k)
+		},
+		// group qualified key matching an unqualified k
+		func(key string) bool {
+			_, keyTag := SplitTagKey(key)
+			return !strings.Contains(k, ":") && strings.EqualFold(keyTag, k)
+		},
+		// unqualified key matching a group qualified k
+		func(key string) bool {
+			return !strings.Contains(key, ":") && strings.EqualFold(key, tag)
+		},
+	}
+	for _, matches := range matchers {
+		for _, key := range keys {
+			if matches(key) {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Lookup returns the value of the field matching k (see ResolveKey)
+func (fm FileMetadata) Lookup(k string) (interface{}, bool) {
+	if key, found := fm.ResolveKey(k); found {
+		return fm.Fields[key], true
+	}
+	return nil, false
+}
+
+// field returns the value of a field, resolving its key when the LenientKeys option is enabled
+func (fm FileMetadata) field(k string) (interface{}, bool) {
+	if fm.lenientKeys {
+		return fm.Lookup(k)
+	}
+	v, found := fm.Fields[k]
+	return v, found
 }
 
 // GetString returns a field value as string and an error if one occurred.
 // KeyNotFoundError will be returned if the key can't be found
 func (fm FileMetadata) GetString(k string) (string, error) {
-	v, found := fm.Fields[k]
+	v, found := fm.field(k)
 	if !found || v == nil {
 		return defaultString, ErrKeyNotFound
 	}
@@ -43,19 +167,67 @@ func toString(v interface{}) string {
 		return strconv.FormatFloat(v, 'f', -1, 64)
 	case int64:
 		return strconv.FormatInt(v, 10)
+	case time.Time:
+		return v.Format(exifDateLayout)
 	default:
 		return fmt.Sprintf("%v", v)
 	}
 }
 
+// writeString converts a value to the string written by exiftool, structures (maps, nested in
+// lists or not) being serialized with exiftool's structure syntax (e.g. {Name=a,Type=Face})
+func writeString(v interface{}) string {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fields := make([]string, len(keys))
+		for i, k := range keys {
+			fields[i] = k + "=" + structValue(v[k])
+		}
+		return "{" + strings.Join(fields, ",") + "}"
+	default:
+		return toString(v)
+	}
+}
+
+// structValue serializes a value nested in a structure
+func structValue(v interface{}) string {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return writeString(v)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = structValue(item)
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	default:
+		str := structEscaper.Replace(toString(v))
+		if strings.HasPrefix(str, "{") || strings.HasPrefix(str, "[") {
+			str = "|" + str
+		}
+		return str
+	}
+}
+
+// structEscaper escapes the characters having a meaning in exiftool's structure syntax
+var structEscaper = strings.NewReplacer("|", "||", ",", "|,", "]", "|]", "}", "|}")
+
 // GetFloat returns a field value as float64 and an error if one occurred.
 // KeyNotFoundError will be returned if the key can't be found.
 func (fm FileMetadata) GetFloat(k string) (float64, error) {
-	v, found := fm.Fields[k]
+	v, found := fm.field(k)
 	if !found || v == nil {
 		return defaultFloat, ErrKeyNotFound
 	}
+	return toFloat(v)
+}
 
+func toFloat(v interface{}) (float64, error) {
 	switch v := v.(type) {
 	case string:
 		return toFloatFallback(v)
@@ -82,11 +254,14 @@ func toFloatFallback(str string) (float64, error) {
 // KeyNotFoundError will be returned if the key can't be found, ParseError if
 // a parsing error occurs.
 func (fm FileMetadata) GetInt(k string) (int64, error) {
-	v, found := fm.Fields[k]
+	v, found := fm.field(k)
 	if !found || v == nil {
 		return defaultInt, ErrKeyNotFound
 	}
+	return toInt(v)
+}
 
+func toInt(v interface{}) (int64, error) {
 	switch v := v.(type) {
 	case string:
 		return toIntFallback(v)
@@ -112,7 +287,7 @@ func toIntFallback(str string) (int64, error) {
 // GetStrings returns a field value as []string and an error if one occurred.
 // KeyNotFoundError will be returned if the key can't be found.
 func (fm FileMetadata) GetStrings(k string) ([]string, error) {
-	v, found := fm.Fields[k]
+	v, found := fm.field(k)
 	if !found || v == nil {
 		return []string{}, ErrKeyNotFound
 	}
@@ -132,16 +307,321 @@ func (fm FileMetadata) GetStrings(k string) ([]string, error) {
 	}
 }
 
+// GetBool returns a field value as bool and an error if one occurred. Exiftool's renderings of
+// booleans are supported (case insensitive): Yes / No, True / False, On / Off and 1 / 0.
+// KeyNotFoundError will be returned if the key can't be found, ParseError if
+// a parsing error occurs.
+func (fm FileMetadata) GetBool(k string) (bool, error) {
+	v, found := fm.field(k)
+	if !found || v == nil {
+		return false, ErrKeyNotFound
+	}
+	if b, ok := v.(bool); ok {
+		return b, nil
+	}
+
+	str := strings.TrimSpace(toString(v))
+	switch strings.ToLower(str) {
+	case "yes", "true", "on", "1":
+		return true, nil
+	case "no", "false", "off", "0":
+		return false, nil
+	default:
+		return false, fmt.Errorf("bool parsing error (%v)", str)
+	}
+}
+
+// GetRational returns a field value as a fraction and an error if one occurred. Fractions
+// ("1/250") are returned as is, integers ("72") and decimals ("0.004", e.g. with
+// NoPrintConversion) are converted to their exact reduced fraction (72/1, 1/250).
+// KeyNotFoundError will be returned if the key can't be found, ParseError if
+// a parsing error occurs.
+func (fm FileMetadata) GetRational(k string) (num, den int64, err error) {
+	v, found := fm.field(k)
+	if !found || v == nil {
+		return 0, 0, ErrKeyNotFound
+	}
+
+	str := strings.TrimSpace(toString(v))
+	if parts := strings.Split(str, "/"); len(parts) == 2 {
+		num, errNum := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 64)
+		den, errDen := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		if errNum != nil || errDen != nil || den == 0 {
+			return 0, 0, fmt.Errorf("rational parsing error (%v)", str)
+		}
+		return num, den, nil
+	}
+
+	r, ok := new(big.Rat).SetString(str)
+	if !ok || !r.Num().IsInt64() || !r.Denom().IsInt64() {
+		return 0, 0, fmt.Errorf("rational parsing error (%v)", str)
+	}
+	return r.Num().Int64(), r.Denom().Int64(), nil
+}
+
+var durationRegexp = regexp.MustCompile(`^(?:(\d+) days )?(\d+):(\d{2}):(\d{2}(?:\.\d+)?)$`)
+
+// GetDuration returns a field value as time.Duration and an error if one occurred. Exiftool's
+// renderings of durations are supported: "0:01:30", "1 days 2:03:04", "30.5 s" (optionally
+// followed by "(approx)") and numeric seconds (see NoPrintConversion).
+// KeyNotFoundError will be returned if the key can't be found, ParseError if
+// a parsing error occurs.
+func (fm FileMetadata) GetDuration(k string) (time.Duration, error) {
+	v, found := fm.field(k)
+	if !found || v == nil {
+		return 0, ErrKeyNotFound
+	}
+
+	str := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(toString(v)), "(approx)"))
+	if m := durationRegexp.FindStringSubmatch(str); m != nil {
+		days, _ := strconv.Atoi("0" + m[1])
+		hours, _ := strconv.Atoi(m[2])
+		minutes, _ := strconv.Atoi(m[3])
+		seconds, _ := strconv.ParseFloat(m[4], 64)
+		return time.Duration(days)*24*time.Hour + time.Duration(hours)*time.Hour +
+			time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second)), nil
+	}
+
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(str, " s")), 64)
+	if err != nil {
+		return 0, fmt.Errorf("duration parsing error (%v): %w", str, err)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// GetDateTime returns a field value as time.Time and an error if one occurred. Exiftool's
+// standard formats (YYYY:MM:DD HH:MM:SS[.ss][+/-HH:MM], or the date alone) are supported, as
+// well as the format configured with DateFormant and the layouts registered with DateLayouts.
+// Dates without timezone are returned in UTC.
+// KeyNotFoundError will be returned if the key can't be found, ErrNotDate if the value can't
+// be parsed.
+func (fm FileMetadata) GetDateTime(k string) (time.Time, error) {
+	v, found := fm.field(k)
+	if !found || v == nil {
+		return time.Time{}, ErrKeyNotFound
+	}
+	if t, ok := v.(time.Time); ok {
+		return t, nil
+	}
+
+	str := toString(v)
+	if fm.dateFormat != "" {
+		if t, err := parseStrftime(str, fm.dateFormat); err == nil {
+			return t, nil
+		}
+	}
+	for _, layouts := range [][]string{exifDateParseLayouts, fm.dateLayouts} {
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, str); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %v", ErrNotDate, str)
+}
+
+// captureTimeTags are the tags used by GetCaptureTime, by priority: the capture date, its sub
+// seconds and its offset
+var captureTimeTags = [][3]string{
+	{"SubSecDateTimeOriginal", "", ""},
+	{"DateTimeOriginal", "SubSecTimeOriginal", "OffsetTimeOriginal"},
+	{"CreateDate", "SubSecTimeDigitized", "OffsetTimeDigitized"},
+}
+
+var dateZoneRegexp = regexp.MustCompile(`(Z|[+-]\d{2}:\d{2})$`)
+
+// GetCaptureTime returns the best known capture time, combining the capture date with its sub
+// seconds and its timezone offset (DateTimeOriginal, SubSecTimeOriginal and OffsetTimeOriginal,
+// falling back to CreateDate and the related tags). The QuickTime CreateDate of videos is
+// considered as UTC, as specified. Dates without any offset information are interpreted in
+// defaultLoc (UTC if nil). Tags are found with group names or not (see PrintGroupNames).
+// KeyNotFoundError will be returned if there is no capture date.
+func (fm FileMetadata) GetCaptureTime(defaultLoc *time.Location) (time.Time, error) {
+	if defaultLoc == nil {
+		defaultLoc = time.UTC
+	}
+
+	fm.lenientKeys = true
+	for _, tags := range captureTimeTags {
+		t, err := fm.GetDateTime(tags[0])
+		if err == ErrKeyNotFound {
+			continue
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+		if str, _ := fm.GetString(tags[0]); dateZoneRegexp.MatchString(str) {
+			return t, nil
+		}
+
+		if subSec, err := fm.GetString(tags[1]); err == nil && t.Nanosecond() == 0 {
+			if f, err := strconv.ParseFloat("0."+strings.TrimSpace(subSec), 64); err == nil {
+				t = t.Add(time.Duration(f * float64(time.Second)))
+			}
+		}
+
+		loc := defaultLoc
+		if tags[0] == "CreateDate" && fm.isVideo() {
+			loc = time.UTC
+		} else if l, found := fm.offsetLocation(tags[2], "OffsetTime"); found {
+			loc = l
+		}
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc), nil
+	}
+	return time.Time{}, ErrKeyNotFound
+}
+
+// offsetLocation returns the location of the first valid timezone offset (e.g. +02:00) among the keys
+func (fm FileMetadata) offsetLocation(keys ...string) (*time.Location, bool) {
+	for _, k := range keys {
+		offset, err := fm.GetString(k)
+		if err != nil {
+			continue
+		}
+		if o, err := time.Parse("-07:00", strings.TrimSpace(offset)); err == nil {
+			return o.Location(), true
+		}
+	}
+	return nil, false
+}
+
+func (fm FileMetadata) isVideo() bool {
+	mime, _ := fm.GetString("MIMEType")
+	return strings.HasPrefix(mime, "video/")
+}
+
+// GetStringDefault returns a field value as string, or def if the key can't be found
+func (fm FileMetadata) GetStringDefault(k string, def string) string {
+	if v, err := fm.GetString(k); err == nil {
+		return v
+	}
+	return def
+}
+
+// GetIntDefault returns a field value as int64, or def if the key can't be found or its value
+// can't be parsed
+func (fm FileMetadata) GetIntDefault(k string, def int64) int64 {
+	if v, err := fm.GetInt(k); err == nil {
+		return v
+	}
+	return def
+}
+
+// GetFloatDefault returns a field value as float64, or def if the key can't be found or its
+// value can't be parsed
+func (fm FileMetadata) GetFloatDefault(k string, def float64) float64 {
+	if v, err := fm.GetFloat(k); err == nil {
+		return v
+	}
+	return def
+}
+
+// GetInts returns a field value as []int64 and an error if one occurred. Lists and space
+// separated values (e.g. "100 200 400") are supported.
+// KeyNotFoundError will be returned if the key can't be found, ParseError if
+// a parsing error occurs.
+func (fm FileMetadata) GetInts(k string) ([]int64, error) {
+	v, found := fm.field(k)
+	if !found || v == nil {
+		return []int64{}, ErrKeyNotFound
+	}
+
+	items := numericItems(v)
+	res := make([]int64, len(items))
+	for i, item := range items {
+		n, err := toInt(item)
+		if err != nil {
+			return []int64{}, err
+		}
+		res[i] = n
+	}
+	return res, nil
+}
+
+// GetFloats returns a field value as []float64 and an error if one occurred. Lists and space
+// separated values (e.g. "12 34 56.5") are supported.
+// KeyNotFoundError will be returned if the key can't be found, ParseError if
+// a parsing error occurs.
+func (fm FileMetadata) GetFloats(k string) ([]float64, error) {
+	v, found := fm.field(k)
+	if !found || v == nil {
+		return []float64{}, ErrKeyNotFound
+	}
+
+	items := numericItems(v)
+	res := make([]float64, len(items))
+	for i, item := range items {
+		f, err := toFloat(item)
+		if err != nil {
+			return []float64{}, err
+		}
+		res[i] = f
+	}
+	return res, nil
+}
+
+// numericItems splits a numeric list value into its items
+func numericItems(v interface{}) []interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		return v
+	case string:
+		fields := strings.Fields(v)
+		items := make([]interface{}, len(fields))
+		for i, f := range fields {
+			items[i] = f
+		}
+		return items
+	default:
+		return []interface{}{v}
+	}
+}
+
+// GetBinary returns the decoded content of a binary field and an error if one occurred.
+// Binary fields are only extracted when the ExtractAllBinaryMetadata option is enabled.
+// KeyNotFoundError will be returned if the key can't be found, ErrNotBinary if the field
+// does not contain binary data.
+func (fm FileMetadata) GetBinary(k string) ([]byte, error) {
+	v, found := fm.field(k)
+	if !found || v == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	str, ok := v.(string)
+	if !ok {
+		return nil, ErrNotBinary
+	}
+	if isBinaryPlaceholder(str) {
+		return nil, fmt.Errorf("%w: binary data not extracted (see ExtractAllBinaryMetadata option)", ErrNotBinary)
+	}
+	if !strings.HasPrefix(str, base64Prefix) {
+		return nil, ErrNotBinary
+	}
+
+	b, err := base64.StdEncoding.DecodeString(str[len(base64Prefix):])
+	if err != nil {
+		return nil, fmt.Errorf("base64 decoding error: %w", err)
+	}
+	return b, nil
+}
+
 func (fm FileMetadata) set(k string, v interface{}) {
 	fm.Fields[k] = v
+	if fm.changed != nil {
+		fm.changed[k] = true
+	}
 }
 
-// SetString sets a string value for a specific field
+// SetString sets a string value for a specific field. As for every setter, the key can be
+// group qualified (see TagKey) to target the tag of a specific group. An empty string is written
+// as an empty value, use Clear to delete a tag.
 func (fm FileMetadata) SetString(k string, v string) {
 	fm.set(k, v)
 }
 
-// SetInt sets a int value for a specific field
+// SetInt sets a int value for a specific field. Tags whose values are print converted by exiftool
+// (e.g. Orientation, "Rotate 90 CW") expect the numerical value to be written with the '#' suffix
+// on the key (e.g. SetInt("Orientation#", 6)), which disables print conversion for that tag.
 func (fm FileMetadata) SetInt(k string, v int64) {
 	fm.set(k, v)
 }
@@ -151,6 +631,27 @@ func (fm FileMetadata) SetFloat(k string, v float64) {
 	fm.set(k, v)
 }
 
+// SetDate sets a date/time value for a specific field. The value is written following the EXIF
+// convention ("YYYY:MM:DD HH:MM:SS", wall clock of the time's location) or, if the Exiftool
+// instance has been configured with the DateFormant option, following the configured format.
+func (fm FileMetadata) SetDate(k string, v time.Time) {
+	fm.set(k, v)
+}
+
+// SetDateString sets a date/time value, expressed as a string following the EXIF convention
+// ("YYYY:MM:DD HH:MM:SS" with optional sub-seconds and time zone), for a specific field. The value
+// is written as given, sub-seconds and time zone included (DateFormant does not apply).
+// An error is returned if the value does not follow the convention.
+func (fm FileMetadata) SetDateString(k string, v string) error {
+	for _, layout := range exifDateStringLayouts {
+		if _, err := time.Parse(layout, v); err == nil {
+			fm.set(k, v)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid date (%v), format YYYY:MM:DD HH:MM:SS[.ss][+/-HH:MM] expected", v)
+}
+
 // SetStrings sets a []String value for a specific field
 func (fm FileMetadata) SetStrings(k string, v []string) {
 	t := make([]interface{}, len(v))
@@ -160,21 +661,398 @@ func (fm FileMetadata) SetStrings(k string, v []string) {
 	fm.set(k, t)
 }
 
-// Clear removes value for a specific metadata field
+// SetStruct sets a structured value (e.g. XMP-mwg-rs:RegionInfo, XMP-iptcCore:CreatorContactInfo),
+// whose fields can themselves be structures (map[string]interface{}) or lists ([]interface{}).
+// It is written with exiftool's structure syntax.
+func (fm FileMetadata) SetStruct(k string, v map[string]interface{}) {
+	fm.set(k, v)
+}
+
+// SetStructs sets a list of structured values (see SetStruct)
+func (fm FileMetadata) SetStructs(k string, v []map[string]interface{}) {
+	t := make([]interface{}, len(v))
+	for i, c := range v {
+		t[i] = c
+	}
+	fm.set(k, t)
+}
+
+// AddToList appends values to a list tag (e.g. Keywords) when writing, instead of replacing its
+// current values (exiftool's "+=" operator). The operation is stored in Fields under the key
+// suffixed with "+" (e.g. "Keywords+"), successive calls accumulate the values.
+func (fm FileMetadata) AddToList(k string, values ...string) {
+	fm.appendStrings(k+"+", values)
+}
+
+// RemoveFromList removes values from a list tag (e.g. Keywords) when writing, leaving its other
+// values untouched (exiftool's "-=" operator). The operation is stored in Fields under the key
+// suffixed with "-" (e.g. "Keywords-"), successive calls accumulate the values.
+func (fm FileMetadata) RemoveFromList(k string, values ...string) {
+	fm.appendStrings(k+"-", values)
+}
+
+// keywordKeys are the tags in which keywords are written by the keyword helpers
+var keywordKeys = []string{"IPTC:Keywords", "XMP-dc:Subject"}
+
+// hierarchicalKeywordKey is the tag in which hierarchical keywords are written by SetHierarchicalKeywords
+const hierarchicalKeywordKey = "XMP-lr:HierarchicalSubject"
+
+// SetKeywords sets the keywords in all the tags read by the common tools (IPTC:Keywords and
+// XMP-dc:Subject), replacing the existing ones when writing
+func (fm FileMetadata) SetKeywords(keywords ...string) {
+	for _, k := range keywordKeys {
+		fm.SetStrings(k, keywords)
+	}
+}
+
+// AddKeywords adds keywords to all the tags read by the common tools (see SetKeywords),
+// keeping the existing ones when writing
+func (fm FileMetadata) AddKeywords(keywords ...string) {
+	for _, k := range keywordKeys {
+		fm.AddToList(k, keywords...)
+	}
+}
+
+// RemoveKeywords removes keywords from all the tags read by the common tools (see SetKeywords)
+func (fm FileMetadata) RemoveKeywords(keywords ...string) {
+	for _, k := range keywordKeys {
+		fm.RemoveFromList(k, keywords...)
+	}
+}
+
+// SetHierarchicalKeywords sets hierarchical keywords, whose levels are separated by "|" (e.g.
+// "Places|France|Paris"), the way Lightroom does: the full paths are written in
+// XMP-lr:HierarchicalSubject and every level as a flat keyword (see SetKeywords).
+func (fm FileMetadata) SetHierarchicalKeywords(keywords ...string) {
+	var flat []string
+	seen := make(map[string]bool)
+	for _, kw := range keywords {
+		for _, level := range strings.Split(kw, "|") {
+			if !seen[level] {
+				seen[level] = true
+				flat = append(flat, level)
+			}
+		}
+	}
+	fm.SetKeywords(flat...)
+	fm.SetStrings(hierarchicalKeywordKey, keywords)
+}
+
+// SetBinaryFromFile sets the value of a binary tag (e.g. Picture, ThumbnailImage) from the
+// content of a file when writing (exiftool's "<=" operator), which avoids loading the file in
+// memory. The operation is stored in Fields under the key suffixed with "<" (e.g. "Picture<").
+func (fm FileMetadata) SetBinaryFromFile(k string, path string) {
+	fm.set(k+"<", path)
+}
+
+func (fm FileMetadata) appendStrings(k string, values []string) {
+	existing, _ := fm.GetStrings(k)
+	fm.SetStrings(k, append(existing, values...))
+}
+
+// Clear schedules the deletion of a specific metadata field: the field is kept with a nil value,
+// which makes WriteMetadata delete the tag from the file. Use Remove to forget a field locally
+// without deleting it from the file.
 func (fm FileMetadata) Clear(k string) {
 	fm.set(k, nil)
 }
 
-// ClearAll removes all medatadata
+// Remove drops a field from Fields without scheduling its deletion: WriteMetadata leaves the
+// tag of the file untouched. Use Clear to delete the tag from the file.
+func (fm FileMetadata) Remove(k string) {
+	delete(fm.Fields, k)
+	delete(fm.changed, k)
+}
+
+// ClearGroup removes all the tags of a group (e.g. "GPS", "XMP", "XMP-dc") when writing,
+// keeping the rest of the metadata
+func (fm FileMetadata) ClearGroup(group string) {
+	fm.set(TagKey(group, "All"), nil)
+}
+
+// ClearAll schedules the deletion of all the fields (see Clear)
 func (fm FileMetadata) ClearAll() {
 	for k, _ := range fm.Fields {
 		fm.set(k, nil)
 	}
 }
 
+// OrderedFields returns the fields as an ordered list of key / value pairs. Fields are
+// returned in exiftool's output order when the PreserveFieldOrder option is enabled, fields
+// that have been added afterwards (or all fields if the option is disabled) follow in
+// alphabetical order.
+func (fm FileMetadata) OrderedFields() []Field {
+	res := make([]Field, 0, len(fm.Fields))
+	ordered := make(map[string]bool, len(fm.order))
+	for _, k := range fm.order {
+		if v, found := fm.Fields[k]; found && !ordered[k] {
+			res = append(res, Field{Key: k, Value: v})
+			ordered[k] = true
+		}
+	}
+
+	var others []string
+	for k := range fm.Fields {
+		if !ordered[k] {
+			others = append(others, k)
+		}
+	}
+	sort.Strings(others)
+	for _, k := range others {
+		res = append(res, Field{Key: k, Value: fm.Fields[k]})
+	}
+
+	return res
+}
+
+// Keys returns the keys of the fields, in the same order as OrderedFields
+func (fm FileMetadata) Keys() []string {
+	fields := fm.OrderedFields()
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.Key
+	}
+	return keys
+}
+
+// Has tells if a field exists for the key (resolved as getters do when the LenientKeys option is enabled)
+func (fm FileMetadata) Has(k string) bool {
+	_, found := fm.field(k)
+	return found
+}
+
+// Len returns the number of fields
+func (fm FileMetadata) Len() int {
+	return len(fm.Fields)
+}
+
+// Clone returns a deep copy of the FileMetadata: fields (including lists and structures) can be
+// modified on the copy, for instance before calling WriteMetadata, without altering the original.
+func (fm FileMetadata) Clone() FileMetadata {
+	c := fm
+	if fm.Fields != nil {
+		c.Fields = copyFields(fm.Fields)
+	}
+	if fm.Checksums != nil {
+		c.Checksums = make(map[crypto.Hash]string, len(fm.Checksums))
+		for h, sum := range fm.Checksums {
+			c.Checksums[h] = sum
+		}
+	}
+	if fm.WriteResult != nil {
+		wr := *fm.WriteResult
+		wr.Warnings = append([]string(nil), fm.WriteResult.Warnings...)
+		wr.Errors = append([]string(nil), fm.WriteResult.Errors...)
+		c.WriteResult = &wr
+	}
+	c.WriteOptions = append([]WriteOption(nil), fm.WriteOptions...)
+	c.order = append([]string(nil), fm.order...)
+	if fm.changed != nil {
+		c.changed = make(map[string]bool, len(fm.changed))
+		for k := range fm.changed {
+			c.changed[k] = true
+		}
+	}
+	return c
+}
+
+// MergeStrategy defines how Merge handles the fields that exist in both FileMetadata
+type MergeStrategy int
+
+// Merge strategies
+const (
+	// MergeOverwrite replaces existing values with the overlay ones
+	MergeOverwrite MergeStrategy = iota
+	// MergeKeepExisting keeps existing values, only adding the fields that do not exist
+	MergeKeepExisting
+	// MergeAppendLists appends the items of overlay lists (e.g. Keywords) that are not already
+	// present to existing values, other values being replaced with the overlay ones
+	MergeAppendLists
+)
+
+// Merge layers the fields of overlay onto the FileMetadata, following strategy for the fields
+// existing in both. Values are copied, so that overlay (e.g. a template holding copyright and
+// contact information) can be merged into several FileMetadata.
+// Sample :
+//   template := exiftool.EmptyFileMetadata()
+//   template.SetString("Copyright", "John Doe")
+//   template.SetKeywords("holidays")
+//   fm.Merge(template, exiftool.MergeAppendLists)
+func (fm FileMetadata) Merge(overlay FileMetadata, strategy MergeStrategy) {
+	for k, v := range overlay.Fields {
+		existing, found := fm.Fields[k]
+		switch {
+		case !found:
+			fm.set(k, copyValue(v))
+		case strategy == MergeKeepExisting:
+		case strategy == MergeAppendLists && existing != nil && v != nil && (isList(existing) || isList(v)):
+			items := listItems(existing)
+			for _, item := range listItems(v) {
+				if !containsValue(items, item) {
+					items = append(items, copyValue(item))
+				}
+			}
+			fm.set(k, items)
+		default:
+			fm.set(k, copyValue(v))
+		}
+	}
+}
+
+func isList(v interface{}) bool {
+	switch v.(type) {
+	case []interface{}, []string:
+		return true
+	default:
+		return false
+	}
+}
+
+// listItems returns the items of a list value, a single value being a list of one item
+func listItems(v interface{}) []interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		return append([]interface{}(nil), v...)
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return items
+	default:
+		return []interface{}{v}
+	}
+}
+
+func containsValue(items []interface{}, v interface{}) bool {
+	for _, item := range items {
+		if sameValue(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// copyFields copies fields deeply, so that lists and structures are not shared with the original
+func copyFields(fields map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		c[k] = copyValue(v)
+	}
+	return c
+}
+
+func copyValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case []interface{}:
+		c := make([]interface{}, len(val))
+		for i, item := range val {
+			c[i] = copyValue(item)
+		}
+		return c
+	case map[string]interface{}:
+		return copyFields(val)
+	case []string:
+		return append([]string(nil), val...)
+	case []byte:
+		return append([]byte(nil), val...)
+	case []map[string]interface{}:
+		c := make([]map[string]interface{}, len(val))
+		for i, item := range val {
+			c[i] = copyFields(item)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
 // EmptyFileMetadata creates an empty FileMetadata struct
 func EmptyFileMetadata() FileMetadata {
 	return FileMetadata{
-		Fields: make(map[string]interface{}),
+		Fields:  make(map[string]interface{}),
+		changed: make(map[string]bool),
+	}
+}
+
+// Changed returns the sorted keys of the fields modified with the setters since the FileMetadata
+// has been created (or since the last call to ResetChanges), see WriteChangedFieldsOnly
+func (fm FileMetadata) Changed() []string {
+	keys := make([]string, 0, len(fm.changed))
+	for k := range fm.changed {
+		if _, found := fm.Fields[k]; found {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ResetChanges forgets the modifications made with the setters, e.g. after a successful write
+func (fm FileMetadata) ResetChanges() {
+	for k := range fm.changed {
+		delete(fm.changed, k)
 	}
 }
+
+// NewFileMetadata creates an empty FileMetadata struct for the given file, to be populated with the
+// setters or the With* methods and written with WriteMetadata.
+// Sample :
+//   fm := exiftool.NewFileMetadata("a.jpg").
+//     WithString("Title", "Holidays").
+//     WithKeywords("beach", "sea")
+//   e.WriteMetadata([]exiftool.FileMetadata{fm})
+func NewFileMetadata(file string) FileMetadata {
+	fm := EmptyFileMetadata()
+	fm.File = file
+	return fm
+}
+
+// WithString sets a string value (see SetString) and returns the FileMetadata, for chaining
+func (fm FileMetadata) WithString(k string, v string) FileMetadata {
+	fm.SetString(k, v)
+	return fm
+}
+
+// WithInt sets an int value (see SetInt) and returns the FileMetadata, for chaining
+func (fm FileMetadata) WithInt(k string, v int64) FileMetadata {
+	fm.SetInt(k, v)
+	return fm
+}
+
+// WithFloat sets a float value (see SetFloat) and returns the FileMetadata, for chaining
+func (fm FileMetadata) WithFloat(k string, v float64) FileMetadata {
+	fm.SetFloat(k, v)
+	return fm
+}
+
+// WithDate sets a date/time value (see SetDate) and returns the FileMetadata, for chaining
+func (fm FileMetadata) WithDate(k string, v time.Time) FileMetadata {
+	fm.SetDate(k, v)
+	return fm
+}
+
+// WithStrings sets a []string value (see SetStrings) and returns the FileMetadata, for chaining
+func (fm FileMetadata) WithStrings(k string, v ...string) FileMetadata {
+	fm.SetStrings(k, v)
+	return fm
+}
+
+// WithKeywords sets the keywords (see SetKeywords) and returns the FileMetadata, for chaining
+func (fm FileMetadata) WithKeywords(keywords ...string) FileMetadata {
+	fm.SetKeywords(keywords...)
+	return fm
+}
+
+// WithCleared schedules the deletion of a field (see Clear) and returns the FileMetadata, for chaining
+func (fm FileMetadata) WithCleared(k string) FileMetadata {
+	fm.Clear(k)
+	return fm
+}
+
+// WithWriteOptions appends write options (see FileMetadata.WriteOptions) and returns the
+// FileMetadata, for chaining
+func (fm FileMetadata) WithWriteOptions(opts ...WriteOption) FileMetadata {
+	fm.WriteOptions = append(fm.WriteOptions, opts...)
+	return fm
+}
@@ -2,7 +2,9 @@ package exiftool
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -153,6 +155,72 @@ func TestGetStrings(t *testing.T) {
 	}
 }
 
+func TestGetBinary(t *testing.T) {
+	fm := FileMetadata{
+		Fields: map[string]interface{}{
+			"binary":      "base64:aGVsbG8=",
+			"placeholder": "(Binary data 5 bytes, use -b option to extract)",
+			"invalid":     "base64:!!!",
+			"string":      "hello",
+			"float":       float64(3.14),
+		},
+	}
+
+	tcs := []struct {
+		inKey      string
+		expIsError bool
+		expError   error
+		expVal     []byte
+	}{
+		{"binary", false, nil, []byte("hello")},
+		{"placeholder", true, ErrNotBinary, nil},
+		{"invalid", true, nil, nil},
+		{"string", true, ErrNotBinary, nil},
+		{"float", true, ErrNotBinary, nil},
+		{"unexisting", true, ErrKeyNotFound, nil},
+	}
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.inKey, func(t *testing.T) {
+			v, err := fm.GetBinary(tc.inKey)
+			if tc.expIsError {
+				assert.NotNil(t, err)
+				if tc.expError != nil {
+					assert.True(t, errors.Is(err, tc.expError))
+				}
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.expVal, v)
+			}
+		})
+	}
+}
+
+func TestTagKey(t *testing.T) {
+	assert.Equal(t, "XMP-dc:Title", TagKey("XMP-dc", "Title"))
+	assert.Equal(t, "Title", TagKey("", "Title"))
+}
+
+func TestSplitTagKey(t *testing.T) {
+	tcs := []struct {
+		inKey    string
+		expGroup string
+		expTag   string
+	}{
+		{"Title", "", "Title"},
+		{"XMP-dc:Title", "XMP-dc", "Title"},
+		{"File:Image:Main:ImageWidth", "File:Image:Main", "ImageWidth"},
+	}
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.inKey, func(t *testing.T) {
+			group, tag := SplitTagKey(tc.inKey)
+			assert.Equal(t, tc.expGroup, group)
+			assert.Equal(t, tc.expTag, tag)
+		})
+	}
+}
+
 func TestSetString(t *testing.T) {
 	k := "k"
 	v := "string"
@@ -193,6 +261,658 @@ func TestSetInt(t *testing.T) {
 	assert.Equal(t, got, v)
 }
 
+func TestResolveKey(t *testing.T) {
+	grouped := EmptyFileMetadata()
+	grouped.Fields = map[string]interface{}{"QuickTime:CreateDate": "a", "EXIF:CreateDate": "b", "EXIF:Artist": "c"}
+	flat := EmptyFileMetadata()
+	flat.Fields = map[string]interface{}{"CreateDate": "a", "Artist": "c"}
+
+	var tcs = []struct {
+		tcID     string
+		inFm     FileMetadata
+		inKey    string
+		expKey   string
+		expFound bool
+	}{
+		{"exact", grouped, "QuickTime:CreateDate", "QuickTime:CreateDate", true},
+		{"case", grouped, "quicktime:createdate", "QuickTime:CreateDate", true},
+		{"groupStripped", grouped, "artist", "EXIF:Artist", true},
+		{"groupStrippedFirst", grouped, "CreateDate", "EXIF:CreateDate", true},
+		{"groupIgnored", flat, "EXIF:createdate", "CreateDate", true},
+		{"wrongGroup", grouped, "XMP:Artist", "", false},
+		{"notFound", flat, "Title", "", false},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			key, found := tc.inFm.ResolveKey(tc.inKey)
+			assert.Equal(t, tc.expFound, found)
+			assert.Equal(t, tc.expKey, key)
+		})
+	}
+
+	v, found := grouped.Lookup("artist")
+	assert.True(t, found)
+	assert.Equal(t, "c", v)
+	_, found = grouped.Lookup("title")
+	assert.False(t, found)
+}
+
+func TestKeysHasLen(t *testing.T) {
+	fm := EmptyFileMetadata()
+	assert.Empty(t, fm.Keys())
+	assert.Equal(t, 0, fm.Len())
+	assert.False(t, fm.Has("a"))
+
+	fm.order = []string{"c", "a"}
+	fm.SetString("a", "1")
+	fm.SetString("b", "2")
+	fm.SetString("c", "3")
+	fm.Clear("d")
+	assert.Equal(t, []string{"c", "a", "b", "d"}, fm.Keys())
+	assert.Equal(t, 4, fm.Len())
+	assert.True(t, fm.Has("a"))
+	assert.True(t, fm.Has("d"))
+	assert.False(t, fm.Has("A"))
+
+	fm.lenientKeys = true
+	assert.True(t, fm.Has("A"))
+}
+
+func TestClone(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.File = "a.jpg"
+	fm.SetString("Title", "title")
+	fm.SetStrings("Keywords", []string{"a", "b"})
+	fm.SetStruct("RegionInfo", map[string]interface{}{"RegionList": []interface{}{map[string]interface{}{"Name": "n"}}})
+	fm.Fields["Raw"] = []string{"r"}
+	fm.order = []string{"Title"}
+	fm.WriteResult = &WriteResult{Updated: 1, Warnings: []string{"w"}}
+
+	c := fm.Clone()
+	assert.Equal(t, fm, c)
+
+	c.File = "b.jpg"
+	c.SetString("Title", "other")
+	c.Fields["Keywords"].([]interface{})[0] = "z"
+	c.Fields["RegionInfo"].(map[string]interface{})["RegionList"].([]interface{})[0].(map[string]interface{})["Name"] = "z"
+	c.Fields["Raw"].([]string)[0] = "z"
+	c.order[0] = "z"
+	c.WriteResult.Warnings[0] = "z"
+
+	assert.Equal(t, "a.jpg", fm.File)
+	assert.Equal(t, "title", fm.Fields["Title"])
+	assert.Equal(t, []interface{}{"a", "b"}, fm.Fields["Keywords"])
+	assert.Equal(t, "n", fm.Fields["RegionInfo"].(map[string]interface{})["RegionList"].([]interface{})[0].(map[string]interface{})["Name"])
+	assert.Equal(t, []string{"r"}, fm.Fields["Raw"])
+	assert.Equal(t, []string{"Title"}, fm.order)
+	assert.Equal(t, []string{"w"}, fm.WriteResult.Warnings)
+}
+
+func TestMerge(t *testing.T) {
+	var tcs = []struct {
+		tcID       string
+		inStrategy MergeStrategy
+		expFields  map[string]interface{}
+	}{
+		{"overwrite", MergeOverwrite, map[string]interface{}{
+			"Title":     "overlay",
+			"Artist":    "base",
+			"Copyright": "overlay",
+			"Keywords":  []interface{}{"b", "c"},
+			"Subject":   []interface{}{"c"},
+		}},
+		{"keepExisting", MergeKeepExisting, map[string]interface{}{
+			"Title":     "base",
+			"Artist":    "base",
+			"Copyright": "overlay",
+			"Keywords":  []interface{}{"a", "b"},
+			"Subject":   "a",
+		}},
+		{"appendLists", MergeAppendLists, map[string]interface{}{
+			"Title":     "overlay",
+			"Artist":    "base",
+			"Copyright": "overlay",
+			"Keywords":  []interface{}{"a", "b", "c"},
+			"Subject":   []interface{}{"a", "c"},
+		}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			fm := EmptyFileMetadata()
+			fm.SetString("Title", "base")
+			fm.SetString("Artist", "base")
+			fm.SetStrings("Keywords", []string{"a", "b"})
+			fm.SetString("Subject", "a")
+
+			overlay := EmptyFileMetadata()
+			overlay.SetString("Title", "overlay")
+			overlay.SetString("Copyright", "overlay")
+			overlay.SetStrings("Keywords", []string{"b", "c"})
+			overlay.SetStrings("Subject", []string{"c"})
+
+			fm.Merge(overlay, tc.inStrategy)
+			assert.Equal(t, tc.expFields, fm.Fields)
+
+			fm.Fields["Copyright"] = "changed"
+			assert.Equal(t, "overlay", overlay.Fields["Copyright"])
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.SetString("a", "1")
+	fm.SetString("b", "2")
+
+	fm.Clear("a")
+	v, found := fm.Fields["a"]
+	assert.True(t, found)
+	assert.Nil(t, v)
+
+	fm.Remove("b")
+	_, found = fm.Fields["b"]
+	assert.False(t, found)
+
+	fm.Remove("missing")
+	assert.Equal(t, map[string]interface{}{"a": nil}, fm.Fields)
+}
+
+func TestNewFileMetadata(t *testing.T) {
+	date := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	fm := NewFileMetadata("a.jpg").
+		WithString("Title", "title").
+		WithInt("Rating", 4).
+		WithFloat("FNumber", 2.8).
+		WithDate("DateTimeOriginal", date).
+		WithStrings("Creator", "a", "b").
+		WithKeywords("k").
+		WithCleared("Comment").
+		WithWriteOptions(WriteBackupOriginal(true))
+
+	assert.Equal(t, "a.jpg", fm.File)
+	assert.Nil(t, fm.Err)
+	assert.Equal(t, map[string]interface{}{
+		"Title":            "title",
+		"Rating":           int64(4),
+		"FNumber":          2.8,
+		"DateTimeOriginal": date,
+		"Creator":          []interface{}{"a", "b"},
+		"IPTC:Keywords":    []interface{}{"k"},
+		"XMP-dc:Subject":   []interface{}{"k"},
+		"Comment":          nil,
+	}, fm.Fields)
+	assert.Len(t, fm.WriteOptions, 1)
+}
+
+func TestChanged(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.Fields["Extracted"] = "value"
+	assert.Empty(t, fm.Changed())
+
+	fm.SetString("Title", "title")
+	fm.SetKeywords("a")
+	fm.Clear("Comment")
+	fm.SetString("Removed", "removed")
+	fm.Remove("Removed")
+	assert.Equal(t, []string{"Comment", "IPTC:Keywords", "Title", "XMP-dc:Subject"}, fm.Changed())
+
+	c := fm.Clone()
+	fm.ResetChanges()
+	assert.Empty(t, fm.Changed())
+	assert.Equal(t, []string{"Comment", "IPTC:Keywords", "Title", "XMP-dc:Subject"}, c.Changed())
+
+	assert.Empty(t, FileMetadata{}.Changed())
+}
+
+func TestLenientKeys(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.SetString("EXIF:Artist", "a")
+
+	_, err := fm.GetString("artist")
+	assert.Equal(t, ErrKeyNotFound, err)
+
+	fm.lenientKeys = true
+	got, err := fm.GetString("artist")
+	assert.Nil(t, err)
+	assert.Equal(t, "a", got)
+}
+
+func TestGetDefault(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.SetString("str", "a")
+	fm.SetInt("int", 1)
+	fm.SetFloat("float", 1.5)
+
+	assert.Equal(t, "a", fm.GetStringDefault("str", "def"))
+	assert.Equal(t, "def", fm.GetStringDefault("missing", "def"))
+	assert.Equal(t, int64(1), fm.GetIntDefault("int", 2))
+	assert.Equal(t, int64(2), fm.GetIntDefault("missing", 2))
+	assert.Equal(t, int64(2), fm.GetIntDefault("str", 2))
+	assert.Equal(t, 1.5, fm.GetFloatDefault("float", 2.5))
+	assert.Equal(t, 2.5, fm.GetFloatDefault("missing", 2.5))
+	assert.Equal(t, 2.5, fm.GetFloatDefault("str", 2.5))
+}
+
+func TestGetInts(t *testing.T) {
+	var tcs = []struct {
+		tcID   string
+		inVal  interface{}
+		expVal []int64
+		expErr bool
+	}{
+		{"list", []interface{}{float64(100), "200", int64(400)}, []int64{100, 200, 400}, false},
+		{"spaceSeparated", "100 200 400", []int64{100, 200, 400}, false},
+		{"single", float64(100), []int64{100}, false},
+		{"invalid", []interface{}{"100", "a"}, []int64{}, true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			fm := EmptyFileMetadata()
+			fm.Fields["ISO"] = tc.inVal
+			got, err := fm.GetInts("ISO")
+			assert.Equal(t, tc.expErr, err != nil)
+			assert.Equal(t, tc.expVal, got)
+		})
+	}
+
+	got, err := EmptyFileMetadata().GetInts("ISO")
+	assert.Equal(t, ErrKeyNotFound, err)
+	assert.Equal(t, []int64{}, got)
+}
+
+func TestGetFloats(t *testing.T) {
+	var tcs = []struct {
+		tcID   string
+		inVal  interface{}
+		expVal []float64
+		expErr bool
+	}{
+		{"list", []interface{}{float64(12), "34", int64(56)}, []float64{12, 34, 56}, false},
+		{"spaceSeparated", "12 34 56.5", []float64{12, 34, 56.5}, false},
+		{"single", "1.5", []float64{1.5}, false},
+		{"invalid", "12 a", []float64{}, true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			fm := EmptyFileMetadata()
+			fm.Fields["GPSTimeStamp"] = tc.inVal
+			got, err := fm.GetFloats("GPSTimeStamp")
+			assert.Equal(t, tc.expErr, err != nil)
+			assert.Equal(t, tc.expVal, got)
+		})
+	}
+
+	got, err := EmptyFileMetadata().GetFloats("GPSTimeStamp")
+	assert.Equal(t, ErrKeyNotFound, err)
+	assert.Equal(t, []float64{}, got)
+}
+
+func TestGetBool(t *testing.T) {
+	var tcs = []struct {
+		inVal  interface{}
+		expVal bool
+		expErr bool
+	}{
+		{"Yes", true, false},
+		{"no", false, false},
+		{"True", true, false},
+		{"FALSE", false, false},
+		{"On", true, false},
+		{"Off", false, false},
+		{float64(1), true, false},
+		{"0", false, false},
+		{true, true, false},
+		{"maybe", false, true},
+		{float64(2), false, true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(fmt.Sprintf("%v", tc.inVal), func(t *testing.T) {
+			fm := EmptyFileMetadata()
+			fm.Fields["Flag"] = tc.inVal
+			got, err := fm.GetBool("Flag")
+			assert.Equal(t, tc.expErr, err != nil)
+			assert.Equal(t, tc.expVal, got)
+		})
+	}
+
+	_, err := EmptyFileMetadata().GetBool("Flag")
+	assert.Equal(t, ErrKeyNotFound, err)
+}
+
+func TestGetRational(t *testing.T) {
+	var tcs = []struct {
+		tcID   string
+		inVal  interface{}
+		expNum int64
+		expDen int64
+		expErr bool
+	}{
+		{"fraction", "1/250", 1, 250, false},
+		{"unreduced", "10/20", 10, 20, false},
+		{"integer", "72", 72, 1, false},
+		{"decimal", "0.004", 1, 250, false},
+		{"numeric", 0.004, 1, 250, false},
+		{"negative", "-1/3", -1, 3, false},
+		{"zeroDenominator", "1/0", 0, 0, true},
+		{"undefined", "undef", 0, 0, true},
+		{"invalidFraction", "a/b", 0, 0, true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			fm := EmptyFileMetadata()
+			fm.Fields["ExposureTime"] = tc.inVal
+			num, den, err := fm.GetRational("ExposureTime")
+			assert.Equal(t, tc.expErr, err != nil)
+			assert.Equal(t, tc.expNum, num)
+			assert.Equal(t, tc.expDen, den)
+		})
+	}
+
+	_, _, err := EmptyFileMetadata().GetRational("ExposureTime")
+	assert.Equal(t, ErrKeyNotFound, err)
+}
+
+func TestGetDuration(t *testing.T) {
+	var tcs = []struct {
+		tcID   string
+		inVal  interface{}
+		expVal time.Duration
+		expErr bool
+	}{
+		{"hms", "0:01:30", 90 * time.Second, false},
+		{"hmsFraction", "1:00:00.5", time.Hour + 500*time.Millisecond, false},
+		{"days", "1 days 2:03:04", 26*time.Hour + 3*time.Minute + 4*time.Second, false},
+		{"seconds", "30.5 s", 30500 * time.Millisecond, false},
+		{"approx", "0:00:45 (approx)", 45 * time.Second, false},
+		{"approxSeconds", "12.25 s (approx)", 12250 * time.Millisecond, false},
+		{"numeric", 30.5, 30500 * time.Millisecond, false},
+		{"invalid", "a while", 0, true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			fm := EmptyFileMetadata()
+			fm.Fields["Duration"] = tc.inVal
+			got, err := fm.GetDuration("Duration")
+			assert.Equal(t, tc.expErr, err != nil)
+			assert.Equal(t, tc.expVal, got)
+		})
+	}
+
+	_, err := EmptyFileMetadata().GetDuration("Duration")
+	assert.Equal(t, ErrKeyNotFound, err)
+}
+
+func TestGetDateTime(t *testing.T) {
+	date := time.Date(2019, time.April, 4, 13, 18, 3, 0, time.UTC)
+	fm := EmptyFileMetadata()
+	fm.Fields["exif"] = "2019:04:04 13:18:03"
+	fm.Fields["subSec"] = "2019:04:04 13:18:03.25"
+	fm.Fields["zone"] = "2019:04:04 15:18:03+02:00"
+	fm.Fields["utc"] = "2019:04:04 13:18:03Z"
+	fm.Fields["dateOnly"] = "2019:04:04"
+	fm.Fields["time"] = date
+	fm.Fields["zero"] = "0000:00:00 00:00:00"
+	fm.Fields["notDate"] = "abc"
+	fm.Fields["nil"] = nil
+
+	var tcs = []struct {
+		tcID   string
+		inKey  string
+		expVal time.Time
+		expErr error
+	}{
+		{"exif", "exif", date, nil},
+		{"subSec", "subSec", date.Add(250 * time.Millisecond), nil},
+		{"zone", "zone", date, nil},
+		{"utc", "utc", date, nil},
+		{"dateOnly", "dateOnly", time.Date(2019, time.April, 4, 0, 0, 0, 0, time.UTC), nil},
+		{"time", "time", date, nil},
+		{"zero", "zero", time.Time{}, ErrNotDate},
+		{"notDate", "notDate", time.Time{}, ErrNotDate},
+		{"nil", "nil", time.Time{}, ErrKeyNotFound},
+		{"notFound", "notFound", time.Time{}, ErrKeyNotFound},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			got, err := fm.GetDateTime(tc.inKey)
+			assert.True(t, errors.Is(err, tc.expErr))
+			assert.True(t, tc.expVal.Equal(got), "%v != %v", tc.expVal, got)
+		})
+	}
+}
+
+func TestGetDateTimeDateFormat(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.dateFormat = "%Y%m%d-%H%M%S"
+	fm.Fields["formatted"] = "20190404-131803"
+	fm.Fields["exif"] = "2019:04:04 13:18:03"
+
+	for _, k := range []string{"formatted", "exif"} {
+		got, err := fm.GetDateTime(k)
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2019, time.April, 4, 13, 18, 3, 0, time.UTC), got)
+	}
+}
+
+func TestGetDateTimeLayouts(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.dateLayouts = []string{"2006/01/02 15:04:05", "Mon Jan 2 15:04:05 2006"}
+	fm.Fields["slashes"] = "2019/04/04 13:18:03"
+	fm.Fields["ansic"] = "Thu Apr 4 13:18:03 2019"
+	fm.Fields["exif"] = "2019:04:04 13:18:03"
+	fm.Fields["notDate"] = "04.04.2019"
+
+	for _, k := range []string{"slashes", "ansic", "exif"} {
+		got, err := fm.GetDateTime(k)
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2019, time.April, 4, 13, 18, 3, 0, time.UTC), got)
+	}
+	_, err := fm.GetDateTime("notDate")
+	assert.True(t, errors.Is(err, ErrNotDate))
+}
+
+func TestGetCaptureTime(t *testing.T) {
+	paris := time.FixedZone("", 2*3600)
+	newYork := time.FixedZone("", -4*3600)
+
+	var tcs = []struct {
+		tcID     string
+		inFields map[string]interface{}
+		inLoc    *time.Location
+		expVal   time.Time
+		expErr   error
+	}{
+		{"composite", map[string]interface{}{"SubSecDateTimeOriginal": "2019:04:04 13:18:03.25+02:00", "DateTimeOriginal": "2000:01:01 00:00:00"}, nil,
+			time.Date(2019, time.April, 4, 13, 18, 3, 250000000, paris), nil},
+		{"combined", map[string]interface{}{"DateTimeOriginal": "2019:04:04 13:18:03", "SubSecTimeOriginal": "25", "OffsetTimeOriginal": "+02:00"}, nil,
+			time.Date(2019, time.April, 4, 13, 18, 3, 250000000, paris), nil},
+		{"offsetTime", map[string]interface{}{"DateTimeOriginal": "2019:04:04 13:18:03", "OffsetTime": "+02:00"}, nil,
+			time.Date(2019, time.April, 4, 13, 18, 3, 0, paris), nil},
+		{"defaultUTC", map[string]interface{}{"DateTimeOriginal": "2019:04:04 13:18:03"}, nil,
+			time.Date(2019, time.April, 4, 13, 18, 3, 0, time.UTC), nil},
+		{"defaultLoc", map[string]interface{}{"DateTimeOriginal": "2019:04:04 13:18:03"}, newYork,
+			time.Date(2019, time.April, 4, 13, 18, 3, 0, newYork), nil},
+		{"createDate", map[string]interface{}{"CreateDate": "2019:04:04 13:18:03", "OffsetTimeDigitized": "+02:00"}, newYork,
+			time.Date(2019, time.April, 4, 13, 18, 3, 0, paris), nil},
+		{"video", map[string]interface{}{"CreateDate": "2019:04:04 11:18:03", "MIMEType": "video/mp4"}, newYork,
+			time.Date(2019, time.April, 4, 11, 18, 3, 0, time.UTC), nil},
+		{"videoQuickTimeUTC", map[string]interface{}{"CreateDate": "2019:04:04 13:18:03+02:00", "MIMEType": "video/mp4"}, nil,
+			time.Date(2019, time.April, 4, 13, 18, 3, 0, paris), nil},
+		{"groupNames", map[string]interface{}{"EXIF:DateTimeOriginal": "2019:04:04 13:18:03", "EXIF:SubSecTimeOriginal": "25", "EXIF:OffsetTimeOriginal": "+02:00"}, nil,
+			time.Date(2019, time.April, 4, 13, 18, 3, 250000000, paris), nil},
+		{"groupNamesVideo", map[string]interface{}{"QuickTime:CreateDate": "2019:04:04 11:18:03", "File:MIMEType": "video/mp4"}, newYork,
+			time.Date(2019, time.April, 4, 11, 18, 3, 0, time.UTC), nil},
+		{"invalid", map[string]interface{}{"DateTimeOriginal": "0000:00:00 00:00:00"}, nil, time.Time{}, ErrNotDate},
+		{"notFound", map[string]interface{}{}, nil, time.Time{}, ErrKeyNotFound},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			fm := EmptyFileMetadata()
+			fm.Fields = tc.inFields
+			got, err := fm.GetCaptureTime(tc.inLoc)
+			assert.True(t, errors.Is(err, tc.expErr))
+			assert.True(t, tc.expVal.Equal(got), "%v != %v", tc.expVal, got)
+			if tc.expErr == nil {
+				_, expOffset := tc.expVal.Zone()
+				_, gotOffset := got.Zone()
+				assert.Equal(t, expOffset, gotOffset)
+			}
+		})
+	}
+}
+
+func TestSetDate(t *testing.T) {
+	k := "k"
+	v := time.Date(2019, time.April, 4, 13, 18, 4, 500, time.UTC)
+	fm := EmptyFileMetadata()
+	fm.SetDate(k, v)
+	got, err := fm.GetString(k)
+	assert.Nil(t, err)
+	assert.Equal(t, "2019:04:04 13:18:04", got)
+}
+
+func TestSetDateString(t *testing.T) {
+	tcs := []struct {
+		inVal  string
+		expOk  bool
+		expVal string
+	}{
+		{"2019:04:04 13:18:04", true, "2019:04:04 13:18:04"},
+		{"2019:04:04 13:18:04.25", true, "2019:04:04 13:18:04.25"},
+		{"2019:04:04 13:18:04+02:00", true, "2019:04:04 13:18:04+02:00"},
+		{"2019:04:04 13:18:04.25Z", true, "2019:04:04 13:18:04.25Z"},
+		{"2019-04-04 13:18:04", false, ""},
+		{"2019:04:04", false, ""},
+		{"2019:13:04 13:18:04", false, ""},
+	}
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.inVal, func(t *testing.T) {
+			fm := EmptyFileMetadata()
+			err := fm.SetDateString("k", tc.inVal)
+			assert.Equal(t, tc.expOk, err == nil)
+			got, err := fm.GetString("k")
+			if tc.expOk {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.expVal, got)
+			} else {
+				assert.Equal(t, ErrKeyNotFound, err)
+			}
+		})
+	}
+}
+
+func TestAddToList(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.AddToList("Keywords", "a", "b")
+	fm.AddToList("Keywords", "c")
+	got, err := fm.GetStrings("Keywords+")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, got)
+	_, err = fm.GetStrings("Keywords")
+	assert.Equal(t, ErrKeyNotFound, err)
+}
+
+func TestRemoveFromList(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.RemoveFromList("Keywords", "a")
+	fm.RemoveFromList("Keywords", "b")
+	got, err := fm.GetStrings("Keywords-")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, got)
+}
+
+func TestKeywords(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.SetKeywords("a", "b")
+	fm.AddKeywords("c")
+	fm.RemoveKeywords("d")
+	exp := map[string]interface{}{
+		"IPTC:Keywords":   []interface{}{"a", "b"},
+		"IPTC:Keywords+":  []interface{}{"c"},
+		"IPTC:Keywords-":  []interface{}{"d"},
+		"XMP-dc:Subject":  []interface{}{"a", "b"},
+		"XMP-dc:Subject+": []interface{}{"c"},
+		"XMP-dc:Subject-": []interface{}{"d"},
+	}
+	assert.Equal(t, exp, fm.Fields)
+}
+
+func TestSetHierarchicalKeywords(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.SetHierarchicalKeywords("Places|France|Paris", "Places|France|Lyon", "Family")
+	exp := map[string]interface{}{
+		"IPTC:Keywords":              []interface{}{"Places", "France", "Paris", "Lyon", "Family"},
+		"XMP-dc:Subject":             []interface{}{"Places", "France", "Paris", "Lyon", "Family"},
+		"XMP-lr:HierarchicalSubject": []interface{}{"Places|France|Paris", "Places|France|Lyon", "Family"},
+	}
+	assert.Equal(t, exp, fm.Fields)
+}
+
+func TestWriteString(t *testing.T) {
+	var tcs = []struct {
+		tcID string
+		in   interface{}
+		exp  string
+	}{
+		{"string", "a,b}", "a,b}"},
+		{"number", float64(1.5), "1.5"},
+		{"struct", map[string]interface{}{"Name": "Bob", "Type": "Face"}, "{Name=Bob,Type=Face}"},
+		{"escaped", map[string]interface{}{"Name": "a|b,c]d}e", "Type": "{x"}, "{Name=a||b|,c|]d|}e,Type=|{x}"},
+		{"nested", map[string]interface{}{
+			"AppliedToDimensions": map[string]interface{}{"W": float64(4000), "H": float64(3000), "Unit": "pixel"},
+			"RegionList": []interface{}{
+				map[string]interface{}{"Name": "Bob", "Type": "Face"},
+			},
+		}, "{AppliedToDimensions={H=3000,Unit=pixel,W=4000},RegionList=[{Name=Bob,Type=Face}]}"},
+		{"list", map[string]interface{}{"Keywords": []interface{}{"a", "b,c"}}, "{Keywords=[a,b|,c]}"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			assert.Equal(t, tc.exp, writeString(tc.in))
+		})
+	}
+}
+
+func TestSetStructs(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.SetStruct("CreatorContactInfo", map[string]interface{}{"CiAdrCity": "Paris"})
+	fm.SetStructs("PersonInImageWDetails", []map[string]interface{}{{"PersonName": "a"}, {"PersonName": "b"}})
+	assert.Equal(t, map[string]interface{}{"CiAdrCity": "Paris"}, fm.Fields["CreatorContactInfo"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"PersonName": "a"},
+		map[string]interface{}{"PersonName": "b"},
+	}, fm.Fields["PersonInImageWDetails"])
+}
+
+func TestSetBinaryFromFile(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.SetBinaryFromFile("Picture", "cover.png")
+	got, err := fm.GetString("Picture<")
+	assert.Nil(t, err)
+	assert.Equal(t, "cover.png", got)
+}
+
 func TestClear(t *testing.T) {
 	fm := EmptyFileMetadata()
 	fm.SetString("k", "v")
@@ -207,6 +927,14 @@ func TestClear(t *testing.T) {
 	assert.Equal(t, ErrKeyNotFound, err)
 }
 
+func TestClearGroup(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.ClearGroup("GPS")
+	v, found := fm.Fields["GPS:All"]
+	assert.True(t, found)
+	assert.Nil(t, v)
+}
+
 func TestClearAll(t *testing.T) {
 	fm := EmptyFileMetadata()
 	fm.SetString("k", "v")
@@ -221,3 +949,20 @@ func TestClearAll(t *testing.T) {
 	assert.Equal(t, ErrKeyNotFound, err)
 
 }
+
+func TestOrderedFields(t *testing.T) {
+	fm := FileMetadata{
+		Fields: map[string]interface{}{
+			"b": "vb",
+			"a": "va",
+			"c": "vc",
+			"d": "vd",
+		},
+		order: []string{"c", "removed", "a"},
+	}
+
+	assert.Equal(t, []Field{{"c", "vc"}, {"a", "va"}, {"b", "vb"}, {"d", "vd"}}, fm.OrderedFields())
+
+	fm.order = nil
+	assert.Equal(t, []Field{{"a", "va"}, {"b", "vb"}, {"c", "vc"}, {"d", "vd"}}, fm.OrderedFields())
+}
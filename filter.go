@@ -0,0 +1,345 @@
+package exiftool
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Predicate selects files, see Filter. Keys are resolved as with the LenientKeys option.
+type Predicate func(fm FileMetadata) bool
+
+// Has selects the files having the key, with a non nil value
+func Has(key string) Predicate {
+	return func(fm FileMetadata) bool {
+		v, found := fm.Lookup(key)
+		return found && v != nil
+	}
+}
+
+// Equals selects the files whose value is v, values being compared once rendered (e.g. "72" is
+// equal to 72, see Diff)
+func Equals(key string, v interface{}) Predicate {
+	return func(fm FileMetadata) bool {
+		fv, found := fm.Lookup(key)
+		return found && fv != nil && sameValue(fv, v)
+	}
+}
+
+// Contains selects the files whose value, as string, contains substr
+func Contains(key string, substr string) Predicate {
+	return func(fm FileMetadata) bool {
+		fm.lenientKeys = true
+		s, err := fm.GetString(key)
+		return err == nil && strings.Contains(s, substr)
+	}
+}
+
+// GreaterThan selects the files whose numeric value is greater than v. Values that are not numbers
+// are not selected.
+func GreaterThan(key string, v float64) Predicate {
+	return compareNumber(key, func(f float64) bool { return f > v })
+}
+
+// LessThan selects the files whose numeric value is less than v. Values that are not numbers are
+// not selected.
+func LessThan(key string, v float64) Predicate {
+	return compareNumber(key, func(f float64) bool { return f < v })
+}
+
+func compareNumber(key string, cmp func(float64) bool) Predicate {
+	return func(fm FileMetadata) bool {
+		fm.lenientKeys = true
+		f, err := fm.GetFloat(key)
+		return err == nil && cmp(f)
+	}
+}
+
+// And selects the files selected by all the predicates
+func And(ps ...Predicate) Predicate {
+	return func(fm FileMetadata) bool {
+		for _, p := range ps {
+			if !p(fm) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or selects the files selected by any of the predicates
+func Or(ps ...Predicate) Predicate {
+	return func(fm FileMetadata) bool {
+		for _, p := range ps {
+			if p(fm) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not selects the files not selected by p
+func Not(p Predicate) Predicate {
+	return func(fm FileMetadata) bool {
+		return !p(fm)
+	}
+}
+
+// Filter returns the files selected by p, in order
+// Sample :
+//   selected := Filter(fms, And(Equals("Model", "HERO4 Silver"), GreaterThan("ISO", 800), Has("GPSLatitude")))
+func Filter(fms []FileMetadata, p Predicate) []FileMetadata {
+	var res []FileMetadata
+	for _, fm := range fms {
+		if p(fm) {
+			res = append(res, fm)
+		}
+	}
+	return res
+}
+
+// FilterStream forwards the files of in selected by p, the returned channel being closed once in
+// is closed
+func FilterStream(in <-chan FileMetadata, p Predicate) <-chan FileMetadata {
+	out := make(chan FileMetadata)
+	go func() {
+		defer close(out)
+		for fm := range in {
+			if p(fm) {
+				out <- fm
+			}
+		}
+	}()
+	return out
+}
+
+// ParseFilter parses a filter expression into a Predicate. Expressions compare tags (keys can be
+// group qualified, e.g. EXIF:Model) with quoted strings or numbers using ==, !=, <, <=, > and >=
+// (ordering operators being numeric), test their presence with has(Tag), and are combined with
+// &&, ||, ! and parentheses. Comparisons are false for files without the tag.
+// Sample :
+//   p, err := ParseFilter(`Model == "HERO4 Silver" && ISO > 800 && has(GPSLatitude)`)
+func ParseFilter(expr string) (Predicate, error) {
+	tokens, err := tokenizeFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	pred, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q in filter", p.peek().text)
+	}
+	return pred, nil
+}
+
+type filterTokenKind int
+
+const (
+	tokenIdent filterTokenKind = iota
+	tokenString
+	tokenNumber
+	tokenOperator
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+}
+
+// filterOperators are the operators of the filter language, longest first
+var filterOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")"}
+
+func tokenizeFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"':
+			s, err := strconv.QuotedPrefix(expr[i:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at offset %v of filter: %w", i, err)
+			}
+			unquoted, _ := strconv.Unquote(s)
+			tokens = append(tokens, filterToken{kind: tokenString, text: unquoted})
+			i += len(s)
+		case c == '-' || c == '.' || unicode.IsDigit(c):
+			j := i + 1
+			for j < len(expr) && (expr[j] == '.' || unicode.IsDigit(rune(expr[j]))) {
+				j++
+			}
+			tokens = append(tokens, filterToken{kind: tokenNumber, text: expr[i:j]})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i + 1
+			for j < len(expr) && isFilterIdentChar(rune(expr[j])) {
+				j++
+			}
+			tokens = append(tokens, filterToken{kind: tokenIdent, text: expr[i:j]})
+			i = j
+		default:
+			found := false
+			for _, op := range filterOperators {
+				if strings.HasPrefix(expr[i:], op) {
+					tokens = append(tokens, filterToken{kind: tokenOperator, text: op})
+					i += len(op)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unexpected character %q at offset %v of filter", c, i)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// isFilterIdentChar tells if a character can be part of a tag key (e.g. XMP-dc:Title)
+func isFilterIdentChar(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == ':' || c == '-'
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() filterToken {
+	if p.done() {
+		return filterToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+// accept consumes the next token if it is the given operator
+func (p *filterParser) accept(op string) bool {
+	if t := p.peek(); !p.done() && t.kind == tokenOperator && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (Predicate, error) {
+	pred, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		pred = Or(pred, right)
+	}
+	return pred, nil
+}
+
+func (p *filterParser) parseAnd() (Predicate, error) {
+	pred, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		pred = And(pred, right)
+	}
+	return pred, nil
+}
+
+func (p *filterParser) parseUnary() (Predicate, error) {
+	if p.accept("!") {
+		pred, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not(pred), nil
+	}
+	if p.accept("(") {
+		pred, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing ) in filter")
+		}
+		return pred, nil
+	}
+
+	key := p.peek()
+	if p.done() || key.kind != tokenIdent {
+		return nil, fmt.Errorf("tag expected in filter, got %q", key.text)
+	}
+	p.pos++
+	if key.text == "has" && p.accept("(") {
+		tag := p.peek()
+		if p.done() || tag.kind != tokenIdent {
+			return nil, fmt.Errorf("tag expected in has(), got %q", tag.text)
+		}
+		p.pos++
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing ) after has(%v", tag.text)
+		}
+		return Has(tag.text), nil
+	}
+	return p.parseComparison(key.text)
+}
+
+func (p *filterParser) parseComparison(key string) (Predicate, error) {
+	op := p.peek()
+	if p.done() || op.kind != tokenOperator {
+		return nil, fmt.Errorf("operator expected after %v in filter", key)
+	}
+	p.pos++
+	value := p.peek()
+	if p.done() || (value.kind != tokenString && value.kind != tokenNumber) {
+		return nil, fmt.Errorf("value expected after %v %v in filter", key, op.text)
+	}
+	p.pos++
+
+	var literal interface{} = value.text
+	var number float64
+	if value.kind == tokenNumber {
+		n, err := strconv.ParseFloat(value.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q in filter", value.text)
+		}
+		literal, number = n, n
+	}
+
+	switch op.text {
+	case "==":
+		return Equals(key, literal), nil
+	case "!=":
+		return And(Has(key), Not(Equals(key, literal))), nil
+	}
+	if value.kind != tokenNumber {
+		return nil, fmt.Errorf("number expected after %v %v in filter", key, op.text)
+	}
+	switch op.text {
+	case "<":
+		return LessThan(key, number), nil
+	case "<=":
+		return compareNumber(key, func(f float64) bool { return f <= number }), nil
+	case ">":
+		return GreaterThan(key, number), nil
+	case ">=":
+		return compareNumber(key, func(f float64) bool { return f >= number }), nil
+	default:
+		return nil, fmt.Errorf("unexpected operator %v after %v in filter", op.text, key)
+	}
+}
@@ -0,0 +1,113 @@
+package exiftool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func filterTestFiles() []FileMetadata {
+	gopro := NewFileMetadata("gopro.jpg")
+	gopro.Fields = map[string]interface{}{"EXIF:Model": "HERO4 Silver", "EXIF:ISO": float64(1600), "Composite:GPSLatitude": 48.8}
+	goproLow := NewFileMetadata("gopro_low.jpg")
+	goproLow.Fields = map[string]interface{}{"EXIF:Model": "HERO4 Silver", "EXIF:ISO": "400"}
+	pixel := NewFileMetadata("pixel.jpg")
+	pixel.Fields = map[string]interface{}{"EXIF:Model": "Pixel 3", "EXIF:ISO": float64(800)}
+	return []FileMetadata{gopro, goproLow, pixel, NewFileMetadata("empty.jpg")}
+}
+
+func files(fms []FileMetadata) []string {
+	var res []string
+	for _, fm := range fms {
+		res = append(res, fm.File)
+	}
+	return res
+}
+
+func TestFilter(t *testing.T) {
+	var tcs = []struct {
+		tcID     string
+		inPred   Predicate
+		expFiles []string
+	}{
+		{"has", Has("GPSLatitude"), []string{"gopro.jpg"}},
+		{"equals", Equals("Model", "HERO4 Silver"), []string{"gopro.jpg", "gopro_low.jpg"}},
+		{"equalsRendered", Equals("ISO", 400), []string{"gopro_low.jpg"}},
+		{"contains", Contains("Model", "Pixel"), []string{"pixel.jpg"}},
+		{"greaterThan", GreaterThan("ISO", 800), []string{"gopro.jpg"}},
+		{"lessThan", LessThan("EXIF:ISO", 800), []string{"gopro_low.jpg"}},
+		{"and", And(Equals("Model", "HERO4 Silver"), GreaterThan("ISO", 800)), []string{"gopro.jpg"}},
+		{"or", Or(Has("GPSLatitude"), Contains("Model", "Pixel")), []string{"gopro.jpg", "pixel.jpg"}},
+		{"not", Not(Has("Model")), []string{"empty.jpg"}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			assert.Equal(t, tc.expFiles, files(Filter(filterTestFiles(), tc.inPred)))
+		})
+	}
+}
+
+func TestFilterStream(t *testing.T) {
+	in := make(chan FileMetadata)
+	go func() {
+		for _, fm := range filterTestFiles() {
+			in <- fm
+		}
+		close(in)
+	}()
+
+	var got []string
+	for fm := range FilterStream(in, Has("Model")) {
+		got = append(got, fm.File)
+	}
+	assert.Equal(t, []string{"gopro.jpg", "gopro_low.jpg", "pixel.jpg"}, got)
+}
+
+func TestParseFilter(t *testing.T) {
+	var tcs = []struct {
+		tcID     string
+		inExpr   string
+		expFiles []string
+	}{
+		{"sample", `Model == "HERO4 Silver" && ISO > 800 && has(GPSLatitude)`, []string{"gopro.jpg"}},
+		{"or", `has(GPSLatitude) || EXIF:Model == "Pixel 3"`, []string{"gopro.jpg", "pixel.jpg"}},
+		{"precedence", `ISO < 500 || ISO >= 1600 && !has(GPSLatitude)`, []string{"gopro_low.jpg"}},
+		{"parentheses", `(ISO < 500 || ISO >= 1600) && Model != "Pixel 3"`, []string{"gopro.jpg", "gopro_low.jpg"}},
+		{"notEqualMissing", `Model != "Pixel 3"`, []string{"gopro.jpg", "gopro_low.jpg"}},
+		{"lessOrEqual", `ISO <= 800`, []string{"gopro_low.jpg", "pixel.jpg"}},
+		{"number", `ISO == 400`, []string{"gopro_low.jpg"}},
+		{"escapedString", `Model == "HERO4 \"Silver\""`, nil},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			p, err := ParseFilter(tc.inExpr)
+			require.Nil(t, err)
+			assert.Equal(t, tc.expFiles, files(Filter(filterTestFiles(), p)))
+		})
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`Model ==`,
+		`Model "Pixel"`,
+		`Model == "Pixel`,
+		`Model > "Pixel"`,
+		`(ISO > 3`,
+		`has(ISO`,
+		`has("ISO")`,
+		`ISO > 3 ISO`,
+		`ISO > 1.2.3`,
+		`ISO # 3`,
+		`ISO && 3`,
+	} {
+		_, err := ParseFilter(expr)
+		assert.NotNil(t, err, expr)
+	}
+}
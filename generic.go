@@ -0,0 +1,53 @@
+//go:build go1.18
+// +build go1.18
+
+package exiftool
+
+import (
+	"fmt"
+	"time"
+)
+
+// Get returns a field value converted to T and an error if one occurred. Supported types are
+// string, int, int64, float64, bool, time.Time (see GetDateTime), time.Duration (see
+// GetDuration), Orientation (for the Orientation key), []string, []int64 and []float64.
+// KeyNotFoundError will be returned if the key can't be found, ParseError if
+// a parsing error occurs. Get is only available when building with Go 1.18 or later.
+// Sample :
+//   width, err := exiftool.Get[int](fm, "ImageWidth")
+func Get[T any](fm FileMetadata, k string) (T, error) {
+	var v T
+	var err error
+	switch p := interface{}(&v).(type) {
+	case *string:
+		*p, err = fm.GetString(k)
+	case *int:
+		var i int64
+		i, err = fm.GetInt(k)
+		*p = int(i)
+	case *int64:
+		*p, err = fm.GetInt(k)
+	case *float64:
+		*p, err = fm.GetFloat(k)
+	case *bool:
+		*p, err = fm.GetBool(k)
+	case *time.Time:
+		*p, err = fm.GetDateTime(k)
+	case *time.Duration:
+		*p, err = fm.GetDuration(k)
+	case *Orientation:
+		var str string
+		if str, err = fm.GetString(k); err == nil {
+			*p, err = ParseOrientation(str)
+		}
+	case *[]string:
+		*p, err = fm.GetStrings(k)
+	case *[]int64:
+		*p, err = fm.GetInts(k)
+	case *[]float64:
+		*p, err = fm.GetFloats(k)
+	default:
+		return v, fmt.Errorf("unsupported type %T", v)
+	}
+	return v, err
+}
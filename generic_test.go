@@ -0,0 +1,76 @@
+//go:build go1.18
+// +build go1.18
+
+package exiftool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.Fields["str"] = "a"
+	fm.Fields["int"] = float64(3)
+	fm.Fields["float"] = 1.5
+	fm.Fields["bool"] = "Yes"
+	fm.Fields["date"] = "2019:04:04 13:18:03"
+	fm.Fields["duration"] = "0:01:30"
+	fm.Fields["Orientation"] = "Rotate 90 CW"
+	fm.Fields["list"] = []interface{}{"1", "2"}
+
+	s, err := Get[string](fm, "str")
+	assert.Nil(t, err)
+	assert.Equal(t, "a", s)
+
+	i, err := Get[int](fm, "int")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, i)
+
+	i64, err := Get[int64](fm, "int")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), i64)
+
+	f, err := Get[float64](fm, "float")
+	assert.Nil(t, err)
+	assert.Equal(t, 1.5, f)
+
+	b, err := Get[bool](fm, "bool")
+	assert.Nil(t, err)
+	assert.True(t, b)
+
+	d, err := Get[time.Time](fm, "date")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2019, time.April, 4, 13, 18, 3, 0, time.UTC), d)
+
+	dur, err := Get[time.Duration](fm, "duration")
+	assert.Nil(t, err)
+	assert.Equal(t, 90*time.Second, dur)
+
+	o, err := Get[Orientation](fm, "Orientation")
+	assert.Nil(t, err)
+	assert.Equal(t, OrientationRotate90CW, o)
+
+	strs, err := Get[[]string](fm, "list")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1", "2"}, strs)
+
+	ints, err := Get[[]int64](fm, "list")
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{1, 2}, ints)
+
+	floats, err := Get[[]float64](fm, "list")
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{1, 2}, floats)
+
+	_, err = Get[string](fm, "missing")
+	assert.Equal(t, ErrKeyNotFound, err)
+
+	_, err = Get[int](fm, "str")
+	assert.NotNil(t, err)
+
+	_, err = Get[complex128](fm, "str")
+	assert.NotNil(t, err)
+}
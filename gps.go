@@ -0,0 +1,170 @@
+package exiftool
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var coordinateNumberRegexp = regexp.MustCompile(`[0-9]+(?:\.[0-9]+)?`)
+
+// ParseCoordinate parses a GPS coordinate, whatever the way exiftool renders it (see CoordFormant
+// and NoPrintConversion): in decimal degrees (e.g. "48.8566", "-48.8566", "+48.856600" or
+// "48.8566 N"), in degrees and decimal minutes (e.g. `48 deg 51.396' N` or XMP's "48,51.396N") or in
+// degrees, minutes and seconds (e.g. `48 deg 51' 23.76" N` or `48°51'23.76"N`). The reference can
+// precede or follow the numbers and be abbreviated or not ("S" or "South"). South and west
+// coordinates are returned as negative values.
+func ParseCoordinate(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	numbers := coordinateNumberRegexp.FindAllString(s, -1)
+	if len(numbers) == 0 || len(numbers) > 3 {
+		return 0, fmt.Errorf("invalid coordinate %q", s)
+	}
+
+	var res float64
+	for i, n := range numbers {
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid coordinate %q: %w", s, err)
+		}
+		switch i {
+		case 0:
+			res = f
+		case 1:
+			res += f / 60
+		case 2:
+			res += f / 3600
+		}
+	}
+
+	if strings.HasPrefix(s, "-") || negativeReference(s) {
+		res = -res
+	}
+	return res, nil
+}
+
+// negativeReference tells if the first or last word of a coordinate is a south or west reference
+func negativeReference(s string) bool {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if len(words) == 0 {
+		return false
+	}
+	for _, w := range []string{words[0], words[len(words)-1]} {
+		switch strings.ToUpper(w) {
+		case "S", "W", "SOUTH", "WEST":
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePosition parses a GPS position made of a latitude and a longitude, optionally followed by
+// an altitude which is ignored, as rendered by exiftool for Composite:GPSPosition or
+// QuickTime:GPSCoordinates (e.g. `48 deg 51' 23.76" N, 2 deg 21' 7.92" E`, "48.8566 N, 2.3522 E,
+// 35 m Above Sea Level" or "48.8566 2.3522 35"). Coordinates are parsed with ParseCoordinate.
+func ParsePosition(s string) (lat, lon float64, err error) {
+	parts := positionParts(s)
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("invalid position %q", s)
+	}
+	if lat, err = ParseCoordinate(parts[0]); err != nil {
+		return 0, 0, err
+	}
+	if lon, err = ParseCoordinate(parts[1]); err != nil {
+		return 0, 0, err
+	}
+	return lat, lon, nil
+}
+
+// positionParts splits a position into its coordinates (and altitude): print converted positions
+// are comma separated, positions are space separated otherwise
+func positionParts(s string) []string {
+	if strings.Contains(s, ",") {
+		return strings.Split(s, ",")
+	}
+	parts := strings.Fields(s)
+	if len(parts) > 3 {
+		return nil
+	}
+	return parts
+}
+
+// GetGPSPosition returns the GPS latitude and longitude in signed decimal degrees, whatever the
+// configuration of the Exiftool instance: print converted or not (see NoPrintConversion and
+// CoordFormant), with group names or not (see PrintGroupNames). Position tags (Composite:GPSPosition
+// and QuickTime:GPSCoordinates) are used when the latitude and longitude tags are missing. ok is
+// false if the file has no (valid) GPS position.
+func (fm FileMetadata) GetGPSPosition() (lat, lon float64, ok bool) {
+	fm.lenientKeys = true
+	lat, okLat := fm.gpsCoordinate("GPSLatitude", "GPSLatitudeRef", "S")
+	lon, okLon := fm.gpsCoordinate("GPSLongitude", "GPSLongitudeRef", "W")
+	if okLat && okLon {
+		return lat, lon, true
+	}
+	for _, k := range gpsPositionKeys {
+		if v, err := fm.GetString(k); err == nil {
+			if lat, lon, err := ParsePosition(v); err == nil {
+				return lat, lon, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// gpsPositionKeys are the tags storing a full position
+var gpsPositionKeys = []string{"GPSPosition", "GPSCoordinates"}
+
+// GetGPSAltitude returns the GPS altitude in meters, negative below sea level, whatever the
+// configuration of the Exiftool instance (see GetGPSPosition). The altitude of QuickTime:GPSCoordinates
+// is used when the altitude tag is missing. ok is false if the file has no (valid) GPS altitude.
+func (fm FileMetadata) GetGPSAltitude() (alt float64, ok bool) {
+	fm.lenientKeys = true
+	if v, err := fm.GetString("GPSAltitude"); err == nil {
+		ref, _ := fm.GetString("GPSAltitudeRef")
+		return parseAltitude(v, ref)
+	}
+	if v, err := fm.GetString("GPSCoordinates"); err == nil {
+		if parts := positionParts(v); len(parts) == 3 {
+			return parseAltitude(parts[2], "")
+		}
+	}
+	return 0, false
+}
+
+// parseAltitude parses an altitude, with its reference either print converted ("Below Sea Level")
+// or not (1)
+func parseAltitude(v string, ref string) (float64, bool) {
+	n := coordinateNumberRegexp.FindString(v)
+	if n == "" {
+		return 0, false
+	}
+	alt, err := strconv.ParseFloat(n, 64)
+	if err != nil {
+		return 0, false
+	}
+	if strings.HasPrefix(strings.TrimSpace(v), "-") || strings.Contains(v, "Below") || strings.Contains(ref, "Below") || ref == "1" {
+		alt = -alt
+	}
+	return alt, true
+}
+
+// gpsCoordinate returns a coordinate, signed with its reference tag when the value isn't
+func (fm FileMetadata) gpsCoordinate(k string, refKey string, negativeRef string) (float64, bool) {
+	v, err := fm.GetString(k)
+	if err != nil {
+		return 0, false
+	}
+	c, err := ParseCoordinate(v)
+	if err != nil {
+		return 0, false
+	}
+
+	if ref, err := fm.GetString(refKey); err == nil && c > 0 && strings.HasPrefix(ref, negativeRef) {
+		c = -c
+	}
+	return c, true
+}
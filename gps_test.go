@@ -0,0 +1,155 @@
+package exiftool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCoordinate(t *testing.T) {
+	var tcs = []struct {
+		tcID   string
+		in     string
+		expOk  bool
+		expVal float64
+	}{
+		{"decimal", "48.8566", true, 48.8566},
+		{"negative", "-2.3522", true, -2.3522},
+		{"decimalRef", "48.8566 S", true, -48.8566},
+		{"dms", `48 deg 51' 23.76" N`, true, 48.8566},
+		{"dmsWest", `2 deg 21' 7.92" W`, true, -2.3522},
+		{"dm", `48 deg 51.396' N`, true, 48.8566},
+		{"plusSign", "+48.856600", true, 48.8566},
+		{"refPrefix", "S 48.8566", true, -48.8566},
+		{"refWord", "48.8566 South", true, -48.8566},
+		{"symbols", `48°51'23.76"S`, true, -48.8566},
+		{"xmp", "2,21.132W", true, -2.3522},
+		{"east", `2 deg 21' 7.92" E`, true, 2.3522},
+		{"empty", "", false, 0},
+		{"tooManyNumbers", "1 2 3 4", false, 0},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			got, err := ParseCoordinate(tc.in)
+			assert.Equal(t, tc.expOk, err == nil)
+			assert.InDelta(t, tc.expVal, got, 1e-6)
+		})
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	var tcs = []struct {
+		tcID   string
+		in     string
+		expOk  bool
+		expLat float64
+		expLon float64
+	}{
+		{"printConverted", `48 deg 51' 23.76" N, 2 deg 21' 7.92" W`, true, 48.8566, -2.3522},
+		{"coordFormat", "+48.856600, -2.352200", true, 48.8566, -2.3522},
+		{"noPrintConversion", "48.8566 -2.3522", true, 48.8566, -2.3522},
+		{"withAltitude", "48.8566 N, 2.3522 E, 35 m Above Sea Level", true, 48.8566, 2.3522},
+		{"withAltitudeNoPrintConversion", "48.8566 2.3522 35", true, 48.8566, 2.3522},
+		{"single", "48.8566", false, 0, 0},
+		{"tooManyParts", "1 2 3 4", false, 0, 0},
+		{"invalidLatitude", "abc, 2.3522", false, 0, 0},
+		{"invalidLongitude", "48.8566, abc", false, 0, 0},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			lat, lon, err := ParsePosition(tc.in)
+			assert.Equal(t, tc.expOk, err == nil)
+			assert.InDelta(t, tc.expLat, lat, 1e-6)
+			assert.InDelta(t, tc.expLon, lon, 1e-6)
+		})
+	}
+}
+
+func TestGetGPSPosition(t *testing.T) {
+	var tcs = []struct {
+		tcID     string
+		inFields map[string]interface{}
+		expOk    bool
+		expLat   float64
+		expLon   float64
+	}{
+		{"printConverted", map[string]interface{}{"GPSLatitude": `48 deg 51' 23.76" N`, "GPSLongitude": `2 deg 21' 7.92" W`}, true, 48.8566, -2.3522},
+		{"noPrintConversion", map[string]interface{}{"GPSLatitude": 48.8566, "GPSLatitudeRef": "S", "GPSLongitude": 2.3522, "GPSLongitudeRef": "E"}, true, -48.8566, 2.3522},
+		{"signed", map[string]interface{}{"GPSLatitude": -48.8566, "GPSLatitudeRef": "S", "GPSLongitude": -2.3522, "GPSLongitudeRef": "West"}, true, -48.8566, -2.3522},
+		{"grouped", map[string]interface{}{"EXIF:GPSLatitude": 48.8566, "EXIF:GPSLatitudeRef": "South", "EXIF:GPSLongitude": 2.3522, "EXIF:GPSLongitudeRef": "East"}, true, -48.8566, 2.3522},
+		{"position", map[string]interface{}{"Composite:GPSPosition": `48 deg 51' 23.76" N, 2 deg 21' 7.92" W`}, true, 48.8566, -2.3522},
+		{"positionNoPrintConversion", map[string]interface{}{"GPSPosition": "48.8566 -2.3522"}, true, 48.8566, -2.3522},
+		{"coordinates", map[string]interface{}{"QuickTime:GPSCoordinates": "48.8566 N, 2.3522 E, 35 m Above Sea Level"}, true, 48.8566, 2.3522},
+		{"missingLongitude", map[string]interface{}{"GPSLatitude": 48.8566}, false, 0, 0},
+		{"invalid", map[string]interface{}{"GPSLatitude": "abc", "GPSLongitude": 2.3522}, false, 0, 0},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			fm := EmptyFileMetadata()
+			fm.Fields = tc.inFields
+			lat, lon, ok := fm.GetGPSPosition()
+			assert.Equal(t, tc.expOk, ok)
+			assert.InDelta(t, tc.expLat, lat, 1e-6)
+			assert.InDelta(t, tc.expLon, lon, 1e-6)
+		})
+	}
+}
+
+func TestGetGPSAltitude(t *testing.T) {
+	var tcs = []struct {
+		tcID     string
+		inFields map[string]interface{}
+		expOk    bool
+		expAlt   float64
+	}{
+		{"above", map[string]interface{}{"GPSAltitude": "35.2 m Above Sea Level"}, true, 35.2},
+		{"below", map[string]interface{}{"GPSAltitude": "12 m Below Sea Level"}, true, -12},
+		{"numeric", map[string]interface{}{"GPSAltitude": 12.5, "GPSAltitudeRef": float64(1)}, true, -12.5},
+		{"numericAbove", map[string]interface{}{"GPSAltitude": 12.5, "GPSAltitudeRef": float64(0)}, true, 12.5},
+		{"grouped", map[string]interface{}{"EXIF:GPSAltitude": 12.5, "EXIF:GPSAltitudeRef": "Below Sea Level"}, true, -12.5},
+		{"coordinates", map[string]interface{}{"GPSCoordinates": "48.8566 N, 2.3522 E, 35 m Below Sea Level"}, true, -35},
+		{"coordinatesNoPrintConversion", map[string]interface{}{"GPSCoordinates": "48.8566 2.3522 35"}, true, 35},
+		{"coordinatesWithoutAltitude", map[string]interface{}{"GPSCoordinates": "48.8566 2.3522"}, false, 0},
+		{"missing", map[string]interface{}{}, false, 0},
+		{"invalid", map[string]interface{}{"GPSAltitude": "unknown"}, false, 0},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			fm := EmptyFileMetadata()
+			fm.Fields = tc.inFields
+			alt, ok := fm.GetGPSAltitude()
+			assert.Equal(t, tc.expOk, ok)
+			assert.InDelta(t, tc.expAlt, alt, 1e-6)
+		})
+	}
+}
+
+func TestExtractGPSPosition(t *testing.T) {
+	t.Parallel()
+
+	var lons []float64
+	for _, opts := range [][]func(*Exiftool) error{nil, {CoordFormant("%+f")}, {NoPrintConversion()}} {
+		e, err := NewExiftool(opts...)
+		require.Nil(t, err)
+		metas := e.ExtractMetadata("./testdata/gps.jpg")
+		require.Nil(t, e.Close())
+		require.Len(t, metas, 1)
+		require.Nil(t, metas[0].Err)
+
+		lat, lon, ok := metas[0].GetGPSPosition()
+		require.True(t, ok)
+		assert.InDelta(t, 43.467448, lat, 1e-6)
+		lons = append(lons, lon)
+	}
+	assert.InDelta(t, lons[0], lons[1], 1e-6)
+	assert.InDelta(t, lons[0], lons[2], 1e-6)
+}
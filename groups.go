@@ -0,0 +1,137 @@
+package exiftool
+
+import "time"
+
+// ExifData contains the common EXIF tags of an image, see FileMetadata.EXIF
+type ExifData struct {
+	Make             string      `exiftool:"EXIF:Make"`
+	Model            string      `exiftool:"EXIF:Model"`
+	SerialNumber     string      `exiftool:"EXIF:SerialNumber"`
+	LensModel        string      `exiftool:"EXIF:LensModel"`
+	Software         string      `exiftool:"EXIF:Software"`
+	Artist           string      `exiftool:"EXIF:Artist"`
+	Copyright        string      `exiftool:"EXIF:Copyright"`
+	ImageDescription string      `exiftool:"EXIF:ImageDescription"`
+	Orientation      Orientation `exiftool:"EXIF:Orientation"`
+	DateTimeOriginal time.Time   `exiftool:"EXIF:DateTimeOriginal"`
+	CreateDate       time.Time   `exiftool:"EXIF:CreateDate"`
+	ModifyDate       time.Time   `exiftool:"EXIF:ModifyDate"`
+	ExposureTime     string      `exiftool:"EXIF:ExposureTime"`
+	FNumber          float64     `exiftool:"EXIF:FNumber"`
+	ISO              int         `exiftool:"EXIF:ISO"`
+	FocalLength      string      `exiftool:"EXIF:FocalLength"`
+	Flash            string      `exiftool:"EXIF:Flash"`
+	ImageWidth       int         `exiftool:"EXIF:ExifImageWidth"`
+	ImageHeight      int         `exiftool:"EXIF:ExifImageHeight"`
+}
+
+// GPSData contains the GPS information of a file, see FileMetadata.GPS. Coordinates are in signed
+// decimal degrees and the altitude in meters (see GetGPSPosition and GetGPSAltitude).
+type GPSData struct {
+	HasPosition  bool
+	Latitude     float64
+	Longitude    float64
+	HasAltitude  bool
+	Altitude     float64
+	DateTime     time.Time `exiftool:"Composite:GPSDateTime"`
+	ImgDirection float64   `exiftool:"EXIF:GPSImgDirection"`
+	Speed        float64   `exiftool:"EXIF:GPSSpeed"`
+}
+
+// IPTCData contains the common IPTC tags of an image, see FileMetadata.IPTC
+type IPTCData struct {
+	ObjectName      string   `exiftool:"IPTC:ObjectName"`
+	Headline        string   `exiftool:"IPTC:Headline"`
+	Caption         string   `exiftool:"IPTC:Caption-Abstract"`
+	Keywords        []string `exiftool:"IPTC:Keywords"`
+	Byline          string   `exiftool:"IPTC:By-line"`
+	CopyrightNotice string   `exiftool:"IPTC:CopyrightNotice"`
+	Credit          string   `exiftool:"IPTC:Credit"`
+	Source          string   `exiftool:"IPTC:Source"`
+	City            string   `exiftool:"IPTC:City"`
+	SubLocation     string   `exiftool:"IPTC:Sub-location"`
+	ProvinceState   string   `exiftool:"IPTC:Province-State"`
+	Country         string   `exiftool:"IPTC:Country-PrimaryLocationName"`
+	CountryCode     string   `exiftool:"IPTC:Country-PrimaryLocationCode"`
+}
+
+// XMPData contains the common XMP tags of a file, see FileMetadata.XMP
+type XMPData struct {
+	Title               string    `exiftool:"XMP:Title"`
+	Description         string    `exiftool:"XMP:Description"`
+	Subject             []string  `exiftool:"XMP:Subject"`
+	HierarchicalSubject []string  `exiftool:"XMP:HierarchicalSubject"`
+	Creator             []string  `exiftool:"XMP:Creator"`
+	Rights              string    `exiftool:"XMP:Rights"`
+	Rating              int       `exiftool:"XMP:Rating"`
+	Label               string    `exiftool:"XMP:Label"`
+	CreatorTool         string    `exiftool:"XMP:CreatorTool"`
+	CreateDate          time.Time `exiftool:"XMP:CreateDate"`
+	ModifyDate          time.Time `exiftool:"XMP:ModifyDate"`
+	City                string    `exiftool:"XMP:City"`
+	State               string    `exiftool:"XMP:State"`
+	Country             string    `exiftool:"XMP:Country"`
+}
+
+// VideoData contains the common QuickTime tags of a video, see FileMetadata.Video
+type VideoData struct {
+	CreateDate      time.Time     `exiftool:"QuickTime:CreateDate"`
+	Duration        time.Duration `exiftool:"QuickTime:Duration"`
+	Width           int           `exiftool:"QuickTime:ImageWidth"`
+	Height          int           `exiftool:"QuickTime:ImageHeight"`
+	Rotation        int           `exiftool:"QuickTime:Rotation"`
+	FrameRate       float64       `exiftool:"QuickTime:VideoFrameRate"`
+	CompressorID    string        `exiftool:"QuickTime:CompressorID"`
+	AudioFormat     string        `exiftool:"QuickTime:AudioFormat"`
+	AudioChannels   int           `exiftool:"QuickTime:AudioChannels"`
+	AudioSampleRate int           `exiftool:"QuickTime:AudioSampleRate"`
+	Make            string        `exiftool:"QuickTime:Make"`
+	Model           string        `exiftool:"QuickTime:Model"`
+}
+
+// EXIF decodes the common EXIF tags (see Decode). Keys are resolved as with the LenientKeys option,
+// so that the tags are found whether the extraction has been made with group names (PrintGroupNames("0"))
+// or not. An error is returned if a value can't be converted.
+func (fm FileMetadata) EXIF() (ExifData, error) {
+	var d ExifData
+	err := fm.decodeGroup(&d)
+	return d, err
+}
+
+// GPS returns the GPS information, decoded the same way as EXIF
+func (fm FileMetadata) GPS() (GPSData, error) {
+	var d GPSData
+	if err := fm.decodeGroup(&d); err != nil {
+		return d, err
+	}
+	fm.lenientKeys = true
+	d.Latitude, d.Longitude, d.HasPosition = fm.GetGPSPosition()
+	d.Altitude, d.HasAltitude = fm.GetGPSAltitude()
+	return d, nil
+}
+
+// IPTC decodes the common IPTC tags, the same way as EXIF
+func (fm FileMetadata) IPTC() (IPTCData, error) {
+	var d IPTCData
+	err := fm.decodeGroup(&d)
+	return d, err
+}
+
+// XMP decodes the common XMP tags, the same way as EXIF
+func (fm FileMetadata) XMP() (XMPData, error) {
+	var d XMPData
+	err := fm.decodeGroup(&d)
+	return d, err
+}
+
+// Video decodes the common QuickTime tags of a video, the same way as EXIF
+func (fm FileMetadata) Video() (VideoData, error) {
+	var d VideoData
+	err := fm.decodeGroup(&d)
+	return d, err
+}
+
+func (fm FileMetadata) decodeGroup(dst interface{}) error {
+	fm.lenientKeys = true
+	return fm.Decode(dst)
+}
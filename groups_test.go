@@ -0,0 +1,133 @@
+package exiftool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEXIF(t *testing.T) {
+	var tcs = []struct {
+		tcID     string
+		inFields map[string]interface{}
+	}{
+		{"ungrouped", map[string]interface{}{
+			"Make":             "Canon",
+			"Orientation":      "Rotate 90 CW",
+			"DateTimeOriginal": "2019:04:04 13:18:04",
+			"FNumber":          float64(2.8),
+			"ISO":              float64(100),
+			"ExposureTime":     "1/250",
+			"ExifImageWidth":   float64(4000),
+		}},
+		{"grouped", map[string]interface{}{
+			"EXIF:Make":             "Canon",
+			"EXIF:Orientation":      float64(6),
+			"EXIF:DateTimeOriginal": "2019:04:04 13:18:04",
+			"EXIF:FNumber":          "2.8",
+			"EXIF:ISO":              "100",
+			"EXIF:ExposureTime":     "1/250",
+			"EXIF:ExifImageWidth":   float64(4000),
+			"XMP:Make":              "other",
+		}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			fm := EmptyFileMetadata()
+			fm.Fields = tc.inFields
+			got, err := fm.EXIF()
+			require.Nil(t, err)
+			assert.Equal(t, ExifData{
+				Make:             "Canon",
+				Orientation:      OrientationRotate90CW,
+				DateTimeOriginal: time.Date(2019, 4, 4, 13, 18, 4, 0, time.UTC),
+				FNumber:          2.8,
+				ISO:              100,
+				ExposureTime:     "1/250",
+				ImageWidth:       4000,
+			}, got)
+		})
+	}
+}
+
+func TestEXIFError(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.SetString("ISO", "high")
+	_, err := fm.EXIF()
+	assert.NotNil(t, err)
+}
+
+func TestGPS(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.Fields = map[string]interface{}{
+		"EXIF:GPSLatitude":      "48 deg 51' 29.60\"",
+		"EXIF:GPSLatitudeRef":   "North",
+		"EXIF:GPSLongitude":     "2 deg 17' 40.20\"",
+		"EXIF:GPSLongitudeRef":  "West",
+		"EXIF:GPSAltitude":      "35 m",
+		"EXIF:GPSImgDirection":  float64(90),
+		"Composite:GPSDateTime": "2019:04:04 11:18:04Z",
+	}
+	got, err := fm.GPS()
+	require.Nil(t, err)
+	assert.True(t, got.HasPosition)
+	assert.InDelta(t, 48.858222, got.Latitude, 0.000001)
+	assert.InDelta(t, -2.294500, got.Longitude, 0.000001)
+	assert.True(t, got.HasAltitude)
+	assert.Equal(t, float64(35), got.Altitude)
+	assert.Equal(t, float64(90), got.ImgDirection)
+	assert.True(t, time.Date(2019, 4, 4, 11, 18, 4, 0, time.UTC).Equal(got.DateTime))
+
+	got, err = EmptyFileMetadata().GPS()
+	require.Nil(t, err)
+	assert.Equal(t, GPSData{}, got)
+}
+
+func TestIPTC(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.Fields = map[string]interface{}{
+		"Caption-Abstract": "caption",
+		"Keywords":         "single",
+		"By-line":          "John Doe",
+	}
+	got, err := fm.IPTC()
+	require.Nil(t, err)
+	assert.Equal(t, IPTCData{Caption: "caption", Keywords: []string{"single"}, Byline: "John Doe"}, got)
+}
+
+func TestXMP(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.Fields = map[string]interface{}{
+		"XMP:Title":   "title",
+		"XMP:Subject": []interface{}{"a", "b"},
+		"XMP:Rating":  float64(4),
+		"IPTC:Title":  "other",
+	}
+	got, err := fm.XMP()
+	require.Nil(t, err)
+	assert.Equal(t, XMPData{Title: "title", Subject: []string{"a", "b"}, Rating: 4}, got)
+}
+
+func TestVideo(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.Fields = map[string]interface{}{
+		"CreateDate":     "2020:01:02 03:04:05",
+		"Duration":       "0:01:30",
+		"ImageWidth":     float64(1920),
+		"ImageHeight":    float64(1080),
+		"VideoFrameRate": float64(29.97),
+	}
+	got, err := fm.Video()
+	require.Nil(t, err)
+	assert.Equal(t, VideoData{
+		CreateDate: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Duration:   90 * time.Second,
+		Width:      1920,
+		Height:     1080,
+		FrameRate:  29.97,
+	}, got)
+}
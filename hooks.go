@@ -0,0 +1,43 @@
+package exiftool
+
+// BeforeHook is called before a call processes files, see Hooks
+type BeforeHook func(op Op, files []string)
+
+// AfterHook is called after a call has processed files, with the results of the call (nil for
+// calls that don't return FileMetadata) or the error it returns (nil for calls reporting errors in
+// FileMetadata.Err), see Hooks
+type AfterHook func(op Op, results []FileMetadata, err error)
+
+// Hooks registers functions called around ExtractMetadata, WriteMetadata and WriteMetadataBatch
+// (and their variants), e.g. for auditing or custom metrics. Hooks are called in the order they
+// have been registered, while the instance is locked: they must not call the Exiftool instance
+// and must not modify the files slice. Nil hooks are ignored.
+// Sample :
+//   e, err := NewExiftool(Hooks(
+//     func(op Op, files []string) { log.Printf("%v %v", op, files) },
+//     nil,
+//   ))
+func Hooks(before BeforeHook, after AfterHook) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		if before != nil {
+			e.beforeHooks = append(e.beforeHooks, before)
+		}
+		if after != nil {
+			e.afterHooks = append(e.afterHooks, after)
+		}
+		return nil
+	}
+}
+
+func (e *Exiftool) runBeforeHooks(op Op, files []string) {
+	e.stats.callStarted()
+	for _, h := range e.beforeHooks {
+		h(op, files)
+	}
+}
+
+func (e *Exiftool) runAfterHooks(op Op, results []FileMetadata, err error) {
+	for _, h := range e.afterHooks {
+		h(op, results, err)
+	}
+}
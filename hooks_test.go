@@ -0,0 +1,50 @@
+package exiftool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hookCall struct {
+	op      Op
+	files   []string
+	results []string
+	err     error
+}
+
+func TestHooks(t *testing.T) {
+	t.Parallel()
+
+	var calls []hookCall
+	before := func(op Op, files []string) {
+		calls = append(calls, hookCall{op: op, files: files})
+	}
+	after := func(op Op, results []FileMetadata, err error) {
+		c := hookCall{op: op, err: err}
+		for _, r := range results {
+			c.results = append(c.results, r.File)
+		}
+		calls = append(calls, c)
+	}
+
+	e, err := NewExiftool(Hooks(before, after), Hooks(nil, nil))
+	require.Nil(t, err)
+	defer e.Close()
+
+	e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	e.WriteMetadata([]FileMetadata{{File: "./testdata/nonExisting.jpg"}})
+	batchErr := e.WriteMetadataBatch(FileMetadata{}, "./testdata/nonExisting.jpg")
+	require.NotNil(t, batchErr)
+
+	exp := []hookCall{
+		{op: OpExtract, files: []string{"./testdata/20190404_131804.jpg"}},
+		{op: OpExtract, results: []string{"./testdata/20190404_131804.jpg"}},
+		{op: OpWrite, files: []string{"./testdata/nonExisting.jpg"}},
+		{op: OpWrite, results: []string{"./testdata/nonExisting.jpg"}},
+		{op: OpWrite, files: []string{"./testdata/nonExisting.jpg"}},
+		{op: OpWrite, err: batchErr},
+	}
+	assert.Equal(t, exp, calls)
+}
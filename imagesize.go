@@ -0,0 +1,89 @@
+package exiftool
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// imageSizeRegexp parses Composite:ImageSize, print converted ("4000x3000") or not ("4000 3000")
+var imageSizeRegexp = regexp.MustCompile(`^\s*(\d+)\s*[x ]\s*(\d+)\s*$`)
+
+// imageSizeKeys are the width / height tags, by order of preference
+var imageSizeKeys = [][2]string{
+	{"File:ImageWidth", "File:ImageHeight"},
+	{"EXIF:ExifImageWidth", "EXIF:ExifImageHeight"},
+	{"QuickTime:ImageWidth", "QuickTime:ImageHeight"},
+	{"ImageWidth", "ImageHeight"},
+}
+
+// ImageSizeOption configures ImageSize
+type ImageSizeOption func(*imageSizeConfig)
+
+type imageSizeConfig struct {
+	applyOrientation bool
+}
+
+// ApplyOrientation makes ImageSize return the displayed dimensions: width and height are swapped
+// when the image is rotated by 90 or 270 degrees (Orientation tag) or when the video is (Rotation tag)
+// Sample :
+//   w, h, err := fm.ImageSize(exiftool.ApplyOrientation())
+func ApplyOrientation() ImageSizeOption {
+	return func(c *imageSizeConfig) {
+		c.applyOrientation = true
+	}
+}
+
+// ImageSize returns the dimensions, in pixels, of the image or video, resolved from
+// Composite:ImageSize, ImageWidth / ImageHeight or ExifImageWidth / ExifImageHeight (whether the
+// extraction has been made with group names or not). Dimensions are the stored ones, see
+// ApplyOrientation to get the displayed ones.
+// KeyNotFoundError will be returned if the dimensions can't be found.
+func (fm FileMetadata) ImageSize(opts ...ImageSizeOption) (w, h int, err error) {
+	var c imageSizeConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	fm.lenientKeys = true
+	w, h, found := fm.storedImageSize()
+	if !found {
+		return 0, 0, ErrKeyNotFound
+	}
+
+	if c.applyOrientation {
+		if o, err := fm.GetOrientation(); err == nil && o.SwapsDimensions() {
+			w, h = h, w
+		} else if r, err := fm.GetInt("QuickTime:Rotation"); err == nil && (r == 90 || r == 270) {
+			w, h = h, w
+		}
+	}
+	return w, h, nil
+}
+
+func (fm FileMetadata) storedImageSize() (w, h int, found bool) {
+	if str, err := fm.GetString("Composite:ImageSize"); err == nil {
+		if m := imageSizeRegexp.FindStringSubmatch(str); m != nil {
+			w, _ = strconv.Atoi(m[1])
+			h, _ = strconv.Atoi(m[2])
+			return w, h, true
+		}
+	}
+	for _, keys := range imageSizeKeys {
+		width, errW := fm.GetInt(keys[0])
+		height, errH := fm.GetInt(keys[1])
+		if errW == nil && errH == nil {
+			return int(width), int(height), true
+		}
+	}
+	return 0, 0, false
+}
+
+// Megapixels returns the number of megapixels of the image or video (see ImageSize).
+// KeyNotFoundError will be returned if the dimensions can't be found.
+func (fm FileMetadata) Megapixels() (float64, error) {
+	w, h, err := fm.ImageSize()
+	if err != nil {
+		return 0, err
+	}
+	return float64(w) * float64(h) / 1e6, nil
+}
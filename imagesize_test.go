@@ -0,0 +1,57 @@
+package exiftool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageSize(t *testing.T) {
+	var tcs = []struct {
+		tcID       string
+		inFields   map[string]interface{}
+		inOriented bool
+		expW       int
+		expH       int
+		expErr     error
+	}{
+		{"composite", map[string]interface{}{"ImageSize": "4000x3000", "ImageWidth": float64(1)}, false, 4000, 3000, nil},
+		{"compositeNoPrintConv", map[string]interface{}{"Composite:ImageSize": "4000 3000"}, false, 4000, 3000, nil},
+		{"file", map[string]interface{}{"File:ImageWidth": float64(4000), "File:ImageHeight": float64(3000), "EXIF:ExifImageWidth": float64(1), "EXIF:ExifImageHeight": float64(1)}, false, 4000, 3000, nil},
+		{"exif", map[string]interface{}{"ExifImageWidth": "4000", "ExifImageHeight": "3000"}, false, 4000, 3000, nil},
+		{"video", map[string]interface{}{"QuickTime:ImageWidth": float64(1920), "QuickTime:ImageHeight": float64(1080)}, false, 1920, 1080, nil},
+		{"notOriented", map[string]interface{}{"ImageSize": "4000x3000", "Orientation": "Rotate 90 CW"}, false, 4000, 3000, nil},
+		{"oriented", map[string]interface{}{"ImageSize": "4000x3000", "Orientation": "Rotate 90 CW"}, true, 3000, 4000, nil},
+		{"orientedNormal", map[string]interface{}{"ImageSize": "4000x3000", "Orientation": float64(3)}, true, 4000, 3000, nil},
+		{"orientedVideo", map[string]interface{}{"ImageSize": "1920x1080", "Rotation": float64(270)}, true, 1080, 1920, nil},
+		{"incomplete", map[string]interface{}{"ImageWidth": float64(4000)}, false, 0, 0, ErrKeyNotFound},
+		{"none", map[string]interface{}{}, false, 0, 0, ErrKeyNotFound},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			fm := EmptyFileMetadata()
+			fm.Fields = tc.inFields
+			var opts []ImageSizeOption
+			if tc.inOriented {
+				opts = append(opts, ApplyOrientation())
+			}
+			w, h, err := fm.ImageSize(opts...)
+			assert.Equal(t, tc.expErr, err)
+			assert.Equal(t, tc.expW, w)
+			assert.Equal(t, tc.expH, h)
+		})
+	}
+}
+
+func TestMegapixels(t *testing.T) {
+	fm := EmptyFileMetadata()
+	_, err := fm.Megapixels()
+	assert.Equal(t, ErrKeyNotFound, err)
+
+	fm.SetString("ImageSize", "4000x3000")
+	mp, err := fm.Megapixels()
+	assert.Nil(t, err)
+	assert.Equal(t, float64(12), mp)
+}
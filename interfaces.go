@@ -0,0 +1,20 @@
+package exiftool
+
+// MetadataReader extracts metadata, see Exiftool.ExtractMetadata. Code depending on it can be
+// tested without exiftool (see the exiftooltest package).
+type MetadataReader interface {
+	ExtractMetadata(files ...string) []FileMetadata
+}
+
+// MetadataWriter writes metadata, see Exiftool.WriteMetadata
+type MetadataWriter interface {
+	WriteMetadata(fileMetadata []FileMetadata, opts ...WriteOption)
+}
+
+// MetadataReadWriter extracts and writes metadata
+type MetadataReadWriter interface {
+	MetadataReader
+	MetadataWriter
+}
+
+var _ MetadataReadWriter = (*Exiftool)(nil)
@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package exiftool
+
+import (
+	"io"
+	"os"
+)
+
+// bindToParent does nothing, job objects being specific to Windows
+func bindToParent(p *os.Process) (io.Closer, error) {
+	return nil, nil
+}
@@ -0,0 +1,93 @@
+package exiftool
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                     = syscall.NewLazyDLL("kernel32.dll")
+	procCreateJobObject          = kernel32.NewProc("CreateJobObjectW")
+	procSetInformationJobObject  = kernel32.NewProc("SetInformationJobObject")
+	procAssignProcessToJobObject = kernel32.NewProc("AssignProcessToJobObject")
+)
+
+// job object constants, see https://learn.microsoft.com/en-us/windows/win32/api/jobapi2/nf-jobapi2-setinformationjobobject
+const (
+	jobObjectExtendedLimitInformationClass = 9
+	jobObjectLimitKillOnJobClose           = 0x00002000
+	processSetQuota                        = 0x0100
+	processTerminate                       = 0x0001
+)
+
+type jobObjectBasicLimitInformation struct {
+	PerProcessUserTimeLimit int64
+	PerJobUserTimeLimit     int64
+	LimitFlags              uint32
+	MinimumWorkingSetSize   uintptr
+	MaximumWorkingSetSize   uintptr
+	ActiveProcessLimit      uint32
+	Affinity                uintptr
+	PriorityClass           uint32
+	SchedulingClass         uint32
+}
+
+type ioCounters struct {
+	ReadOperationCount  uint64
+	WriteOperationCount uint64
+	OtherOperationCount uint64
+	ReadTransferCount   uint64
+	WriteTransferCount  uint64
+	OtherTransferCount  uint64
+}
+
+type jobObjectExtendedLimitInformation struct {
+	BasicLimitInformation jobObjectBasicLimitInformation
+	IoInfo                ioCounters
+	ProcessMemoryLimit    uintptr
+	JobMemoryLimit        uintptr
+	PeakProcessMemoryUsed uintptr
+	PeakJobMemoryUsed     uintptr
+}
+
+// jobObject is a job object killing its processes when its last handle is closed
+type jobObject syscall.Handle
+
+func (j jobObject) Close() error {
+	return syscall.CloseHandle(syscall.Handle(j))
+}
+
+// bindToParent assigns the exiftool process to a job object killing it when closed. The handle is
+// only closed by the system when the application exits, even if it crashes or is terminated, so
+// that no orphan exiftool.exe is left behind. The processes started by exiftool after its
+// assignment also belong to the job.
+func bindToParent(p *os.Process) (io.Closer, error) {
+	h, _, err := procCreateJobObject.Call(0, 0)
+	if h == 0 {
+		return nil, fmt.Errorf("error when creating job object: %w", err)
+	}
+	job := jobObject(h)
+
+	info := jobObjectExtendedLimitInformation{}
+	info.BasicLimitInformation.LimitFlags = jobObjectLimitKillOnJobClose
+	if r, _, err := procSetInformationJobObject.Call(h, jobObjectExtendedLimitInformationClass,
+		uintptr(unsafe.Pointer(&info)), unsafe.Sizeof(info)); r == 0 {
+		job.Close()
+		return nil, fmt.Errorf("error when configuring job object: %w", err)
+	}
+
+	ph, err := syscall.OpenProcess(processSetQuota|processTerminate, false, uint32(p.Pid))
+	if err != nil {
+		job.Close()
+		return nil, fmt.Errorf("error when opening exiftool process: %w", err)
+	}
+	defer syscall.CloseHandle(ph)
+	if r, _, err := procAssignProcessToJobObject.Call(h, uintptr(ph)); r == 0 {
+		job.Close()
+		return nil, fmt.Errorf("error when assigning exiftool to job object: %w", err)
+	}
+	return job, nil
+}
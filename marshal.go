@@ -0,0 +1,125 @@
+package exiftool
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// checksumNames are the names of the hashes supported by the Checksums option in FileMetadata's JSON form
+var checksumNames = map[crypto.Hash]string{
+	crypto.MD5:    "MD5",
+	crypto.SHA1:   "SHA-1",
+	crypto.SHA256: "SHA-256",
+}
+
+// knownErrors are the sentinel errors restored when unmarshaling a FileMetadata, so that they can
+// still be tested with errors.Is
+var knownErrors = []error{ErrNotExist, ErrNotFile, ErrBufferTooSmall, ErrInvalidTagKey, ErrInvalidTagValue,
+	ErrInvalidArgument, ErrTagNotAllowed, ErrKeyNotFound, ErrNotBinary, ErrNotDate, ErrRenameCollision, ErrInvalidPath}
+
+// fileMetadataJSON is the JSON form of FileMetadata
+type fileMetadataJSON struct {
+	File      string                 `json:"File"`
+	Fields    map[string]interface{} `json:"Fields"`
+	Checksums map[string]string      `json:"Checksums,omitempty"`
+	Error     string                 `json:"Error,omitempty"`
+	Order     []string               `json:"Order,omitempty"`
+}
+
+// MarshalJSON serializes the FileMetadata (File, Fields, Checksums and the message of Err) so that it
+// can be persisted or sent over the wire, and later unmarshaled and written with WriteMetadata.
+// Dates set with SetDate are serialized following the EXIF convention ("YYYY:MM:DD HH:MM:SS").
+// Sample :
+//   {"File":"a.jpg","Fields":{"Artist":"John Doe"},"Checksums":{"MD5":"..."},"Error":"..."}
+func (fm FileMetadata) MarshalJSON() ([]byte, error) {
+	j := fileMetadataJSON{
+		File:   fm.File,
+		Fields: make(map[string]interface{}, len(fm.Fields)),
+		Order:  fm.order,
+	}
+	for k, v := range fm.Fields {
+		j.Fields[k] = marshalValue(v)
+	}
+	if len(fm.Checksums) > 0 {
+		j.Checksums = make(map[string]string, len(fm.Checksums))
+		for h, sum := range fm.Checksums {
+			name, found := checksumNames[h]
+			if !found {
+				return nil, fmt.Errorf("unsupported checksum hash (%v)", h)
+			}
+			j.Checksums[name] = sum
+		}
+	}
+	if fm.Err != nil {
+		j.Error = fm.Err.Error()
+	}
+	return json.Marshal(j)
+}
+
+func marshalValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case time.Time:
+		return val.Format(exifDateLayout)
+	case []interface{}:
+		c := make([]interface{}, len(val))
+		for i, item := range val {
+			c[i] = marshalValue(item)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// UnmarshalJSON deserializes a FileMetadata serialized with MarshalJSON. Numbers are decoded as
+// float64, as for extractions. Err is restored from its message: sentinel errors (ErrNotExist, ...)
+// are restored as such, other errors lose their type. The fields are considered as changed, so that
+// they are all written even with WriteChangedFieldsOnly.
+func (fm *FileMetadata) UnmarshalJSON(data []byte) error {
+	var j fileMetadataJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	res := NewFileMetadata(j.File)
+	for k, v := range j.Fields {
+		res.set(k, v)
+	}
+	if len(j.Checksums) > 0 {
+		res.Checksums = make(map[crypto.Hash]string, len(j.Checksums))
+		for name, sum := range j.Checksums {
+			h, found := checksumHash(name)
+			if !found {
+				return fmt.Errorf("unsupported checksum hash (%v)", name)
+			}
+			res.Checksums[h] = sum
+		}
+	}
+	if j.Error != "" {
+		res.Err = unmarshalError(j.Error)
+	}
+	res.order = j.Order
+	*fm = res
+	return nil
+}
+
+func checksumHash(name string) (crypto.Hash, bool) {
+	for h, n := range checksumNames {
+		if n == name {
+			return h, true
+		}
+	}
+	return 0, false
+}
+
+func unmarshalError(msg string) error {
+	for _, err := range knownErrors {
+		if err.Error() == msg {
+			return err
+		}
+	}
+	return errors.New(msg)
+}
@@ -0,0 +1,104 @@
+package exiftool
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.File = "a.jpg"
+	fm.SetString("Artist", "John Doe")
+	fm.SetInt("ISO", 100)
+	fm.SetDate("DateTimeOriginal", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	fm.SetStrings("Keywords", []string{"a", "b"})
+	fm.Clear("Title")
+	fm.Checksums = map[crypto.Hash]string{crypto.MD5: "abc"}
+	fm.Err = fmt.Errorf("error while writing: %w", ErrTagNotAllowed)
+	fm.order = []string{"Artist"}
+
+	b, err := json.Marshal(fm)
+	require.Nil(t, err)
+	assert.JSONEq(t, `{
+		"File": "a.jpg",
+		"Fields": {"Artist": "John Doe", "ISO": 100, "DateTimeOriginal": "2020:01:02 03:04:05", "Keywords": ["a", "b"], "Title": null},
+		"Checksums": {"MD5": "abc"},
+		"Error": "error while writing: tag not allowed",
+		"Order": ["Artist"]
+	}`, string(b))
+
+	var got FileMetadata
+	require.Nil(t, json.Unmarshal(b, &got))
+	assert.Equal(t, "a.jpg", got.File)
+	assert.Equal(t, map[string]interface{}{
+		"Artist":           "John Doe",
+		"ISO":              float64(100),
+		"DateTimeOriginal": "2020:01:02 03:04:05",
+		"Keywords":         []interface{}{"a", "b"},
+		"Title":            nil,
+	}, got.Fields)
+	assert.Equal(t, fm.Checksums, got.Checksums)
+	assert.Equal(t, fm.Err.Error(), got.Err.Error())
+	assert.Equal(t, fm.order, got.order)
+	assert.Empty(t, Diff(fm, got))
+}
+
+func TestMarshalJSONEmpty(t *testing.T) {
+	b, err := json.Marshal(EmptyFileMetadata())
+	require.Nil(t, err)
+	assert.JSONEq(t, `{"File": "", "Fields": {}}`, string(b))
+
+	var got FileMetadata
+	require.Nil(t, json.Unmarshal(b, &got))
+	assert.Equal(t, EmptyFileMetadata(), got)
+}
+
+func TestMarshalJSONUnsupportedChecksum(t *testing.T) {
+	fm := EmptyFileMetadata()
+	fm.Checksums = map[crypto.Hash]string{crypto.SHA512: "abc"}
+	_, err := json.Marshal(fm)
+	assert.NotNil(t, err)
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	var tcs = []struct {
+		tcID      string
+		inJSON    string
+		expErr    error
+		expFmtErr bool
+	}{
+		{"sentinel", `{"File": "a.jpg", "Fields": {}, "Error": "file does not exist"}`, ErrNotExist, false},
+		{"other", `{"File": "a.jpg", "Fields": {}, "Error": "other"}`, errors.New("other"), false},
+		{"unsupportedChecksum", `{"File": "a.jpg", "Fields": {}, "Checksums": {"CRC": "abc"}}`, nil, true},
+		{"malformed", `{"File": 1}`, nil, true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			var got FileMetadata
+			err := json.Unmarshal([]byte(tc.inJSON), &got)
+			assert.Equal(t, tc.expFmtErr, err != nil)
+			if !tc.expFmtErr {
+				assert.Equal(t, tc.expErr, got.Err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalJSONChanged(t *testing.T) {
+	var got FileMetadata
+	require.Nil(t, json.Unmarshal([]byte(`{"File": "a.jpg", "Fields": {"Title": "title", "Artist": "artist"}}`), &got))
+	assert.Equal(t, []string{"Artist", "Title"}, got.Changed())
+
+	args, err := (&Exiftool{writeChangedOnly: true}).writeArgs(got)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"-overwrite_original", "-Artist=artist", "-Title=title"}, args)
+}
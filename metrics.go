@@ -0,0 +1,101 @@
+package exiftool
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Op is the kind of operation performed by exiftool
+type Op int
+
+const (
+	// OpExtract is a metadata extraction (ExtractMetadata and its variants)
+	OpExtract Op = iota
+	// OpWrite modifies files (WriteMetadata and its variants, as well as the commands writing tags
+	// such as Geotag or ShiftDates)
+	OpWrite
+	// OpRename renames files (RenameByTemplate and PlanRename)
+	OpRename
+	// OpOriginals manages the backups of the original files (RestoreOriginals and DeleteOriginals)
+	OpOriginals
+)
+
+// String returns the name of the operation, e.g. to be used as a metric label
+func (o Op) String() string {
+	switch o {
+	case OpExtract:
+		return "extract"
+	case OpWrite:
+		return "write"
+	case OpRename:
+		return "rename"
+	case OpOriginals:
+		return "originals"
+	default:
+		return "unknown"
+	}
+}
+
+// MetricsRecorder receives measures about the work done by exiftool, so that they can be exported
+// to any monitoring system (see Metrics). Its methods are called synchronously and have to be fast.
+type MetricsRecorder interface {
+	// ObserveCommand is called after each command sent to exiftool, with the round-trip duration,
+	// the number of bytes read from exiftool and the error that prevented the command from being
+	// processed (nil otherwise)
+	ObserveCommand(op Op, d time.Duration, bytesRead int, err error)
+	// ObserveFile is called for each file processed by ExtractMetadata or WriteMetadata (and their
+	// variants), with its FileMetadata.Err (see ErrorKind)
+	ObserveFile(op Op, err error)
+	// ObserveRestart is called each time exiftool has been restarted (see AutoRestart and Restart)
+	ObserveRestart()
+}
+
+// Metrics registers a MetricsRecorder
+// Sample :
+//   e, err := NewExiftool(Metrics(myPrometheusRecorder))
+func Metrics(r MetricsRecorder) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		e.metrics = r
+		return nil
+	}
+}
+
+// observeFiles reports the result of the processing of each file to the MetricsRecorder
+func (e *Exiftool) observeFiles(op Op, fms []FileMetadata) {
+	e.stats.filesDone(fms)
+	if e.metrics == nil {
+		return
+	}
+	for _, fm := range fms {
+		e.metrics.ObserveFile(op, fm.Err)
+	}
+}
+
+// ErrorKind classifies errors with a short name that can be used as a metric label: "" (no
+// error), "not_exist", "not_file", "buffer_too_small", "invalid_argument", "invalid_path", "tag_not_allowed",
+// "canceled", "timeout" or "other"
+func ErrorKind(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrNotExist):
+		return "not_exist"
+	case errors.Is(err, ErrNotFile):
+		return "not_file"
+	case errors.Is(err, ErrBufferTooSmall):
+		return "buffer_too_small"
+	case errors.Is(err, ErrInvalidArgument), errors.Is(err, ErrInvalidTagKey), errors.Is(err, ErrInvalidTagValue):
+		return "invalid_argument"
+	case errors.Is(err, ErrInvalidPath):
+		return "invalid_path"
+	case errors.Is(err, ErrTagNotAllowed):
+		return "tag_not_allowed"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	default:
+		return "other"
+	}
+}
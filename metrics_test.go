@@ -0,0 +1,118 @@
+package exiftool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedCommand struct {
+	op        Op
+	bytesRead int
+	err       error
+}
+
+type recordedFile struct {
+	op  Op
+	err error
+}
+
+type testRecorder struct {
+	commands []recordedCommand
+	files    []recordedFile
+	restarts int
+}
+
+func (r *testRecorder) ObserveCommand(op Op, d time.Duration, bytesRead int, err error) {
+	r.commands = append(r.commands, recordedCommand{op: op, bytesRead: bytesRead, err: err})
+}
+
+func (r *testRecorder) ObserveFile(op Op, err error) {
+	r.files = append(r.files, recordedFile{op: op, err: err})
+}
+
+func (r *testRecorder) ObserveRestart() {
+	r.restarts++
+}
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	r := &testRecorder{}
+	e, err := NewExiftool(Metrics(r))
+	require.Nil(t, err)
+	defer e.Close()
+
+	e.ExtractMetadata("./testdata/20190404_131804.jpg", "./testdata/nonExisting.jpg")
+
+	require.Len(t, r.commands, 1)
+	assert.Equal(t, OpExtract, r.commands[0].op)
+	assert.True(t, r.commands[0].bytesRead > 0)
+	assert.Nil(t, r.commands[0].err)
+	assert.Equal(t, []recordedFile{{OpExtract, nil}, {OpExtract, ErrNotExist}}, r.files)
+}
+
+func TestMetricsRestart(t *testing.T) {
+	t.Parallel()
+
+	r := &testRecorder{}
+	e, err := NewExiftool(Metrics(r))
+	require.Nil(t, err)
+	defer e.Close()
+
+	require.Nil(t, e.Restart())
+	assert.Equal(t, 1, r.restarts)
+}
+
+func TestOpString(t *testing.T) {
+	var tcs = []struct {
+		tcID   string
+		inOp   Op
+		expStr string
+	}{
+		{"extract", OpExtract, "extract"},
+		{"write", OpWrite, "write"},
+		{"rename", OpRename, "rename"},
+		{"originals", OpOriginals, "originals"},
+		{"unknown", Op(42), "unknown"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			assert.Equal(t, tc.expStr, tc.inOp.String())
+		})
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	var tcs = []struct {
+		tcID    string
+		inErr   error
+		expKind string
+	}{
+		{"nil", nil, ""},
+		{"notExist", ErrNotExist, "not_exist"},
+		{"notFile", ErrNotFile, "not_file"},
+		{"bufferTooSmall", ErrBufferTooSmall, "buffer_too_small"},
+		{"invalidArgument", fmt.Errorf("%w: %q", ErrInvalidArgument, "a"), "invalid_argument"},
+		{"invalidTagKey", ErrInvalidTagKey, "invalid_argument"},
+		{"invalidPath", ErrInvalidPath, "invalid_path"},
+		{"tagNotAllowed", ErrTagNotAllowed, "tag_not_allowed"},
+		{"canceled", context.Canceled, "canceled"},
+		{"timeout", context.DeadlineExceeded, "timeout"},
+		{"other", errors.New("error"), "other"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			assert.Equal(t, tc.expKind, ErrorKind(tc.inErr))
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package exiftool
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// OrganizeMode tells if ApplyOrganizePlan moves or copies the files
+type OrganizeMode int
+
+// Organization modes
+const (
+	OrganizeMove OrganizeMode = iota
+	OrganizeCopy
+)
+
+// Sources of the dates of the files of an OrganizePlan
+const (
+	// DateFromCaptureTime is used for files organized according to their capture time (see
+	// FileMetadata.GetCaptureTime)
+	DateFromCaptureTime = "CaptureTime"
+	// DateFromFileTime is used for files organized according to their modification time
+	DateFromFileTime = "FileModifyDate"
+)
+
+// OrganizeOptions configures PlanOrganize
+type OrganizeOptions struct {
+	// Mode tells if the files are moved (default) or copied
+	Mode OrganizeMode
+	// NoFileTimeFallback reports the files without capture time as undated, instead of organizing
+	// them according to their modification time
+	NoFileTimeFallback bool
+}
+
+// OrganizeAction is a file of an OrganizePlan, to be moved or copied to Target
+type OrganizeAction struct {
+	Source string
+	Target string
+	// Date is the date the target has been computed from, DateSource tells where it comes from
+	// (DateFromCaptureTime or DateFromFileTime)
+	Date       time.Time
+	DateSource string
+}
+
+// OrganizePlan is a set of moves or copies proposed by PlanOrganize, to be reviewed before being
+// applied with ApplyOrganizePlan
+type OrganizePlan struct {
+	Mode    OrganizeMode
+	Actions []OrganizeAction
+	// Collisions maps the targets that are claimed by several files, or that already exist, to
+	// the files that would be moved or copied to them. These files have no action.
+	Collisions map[string][]string
+	// Undated lists the files without capture time, when NoFileTimeFallback is set
+	Undated []string
+	// Failed maps the files whose metadata could not be extracted to the error
+	Failed map[string]error
+}
+
+// PlanOrganize computes, without moving nor copying anything, how the files of srcDir (and of its
+// sub-directories) would be organized in destDir according to their dates, formatted with layout
+// (strftime syntax, e.g. "%Y/%m/%d") to get the directory of each file, which keeps its name. The
+// capture time is used (see FileMetadata.GetCaptureTime), falling back to the modification time
+// of the file unless opts.NoFileTimeFallback is set.
+// Sample :
+//   plan, err := e.PlanOrganize("/media/sdcard", "/photos", "%Y/%m/%d", OrganizeOptions{Mode: OrganizeCopy})
+//   // review plan.Collisions, plan.Undated and plan.Failed
+//   err = ApplyOrganizePlan(plan)
+func (e *Exiftool) PlanOrganize(srcDir, destDir, layout string, opts OrganizeOptions) (OrganizePlan, error) {
+	if _, err := formatStrftime(time.Time{}, layout); err != nil {
+		return OrganizePlan{}, err
+	}
+	files, err := walkFiles(srcDir)
+	if err != nil {
+		return OrganizePlan{}, fmt.Errorf("error while listing files: %w", err)
+	}
+	var fms []FileMetadata
+	if len(files) > 0 {
+		fms = e.ExtractMetadata(files...)
+	}
+	return planOrganize(fms, destDir, layout, opts)
+}
+
+// planOrganize computes the organization plan of extracted files
+func planOrganize(fms []FileMetadata, destDir, layout string, opts OrganizeOptions) (OrganizePlan, error) {
+	plan := OrganizePlan{Mode: opts.Mode, Collisions: make(map[string][]string), Failed: make(map[string]error)}
+	sources := make(map[string][]OrganizeAction)
+	for _, fm := range fms {
+		if fm.Err != nil {
+			plan.Failed[fm.File] = fm.Err
+			continue
+		}
+
+		a := OrganizeAction{Source: fm.File, DateSource: DateFromCaptureTime}
+		fm.lenientKeys = true
+		t, err := fm.GetCaptureTime(nil)
+		if err != nil {
+			if opts.NoFileTimeFallback {
+				plan.Undated = append(plan.Undated, fm.File)
+				continue
+			}
+			fi, err := os.Stat(fm.File)
+			if err != nil {
+				plan.Failed[fm.File] = err
+				continue
+			}
+			t, a.DateSource = fi.ModTime(), DateFromFileTime
+		}
+		a.Date = t
+
+		dir, err := formatStrftime(t, layout)
+		if err != nil {
+			return OrganizePlan{}, err
+		}
+		a.Target = filepath.Join(destDir, filepath.FromSlash(dir), filepath.Base(fm.File))
+		sources[a.Target] = append(sources[a.Target], a)
+	}
+
+	for target, actions := range sources {
+		if _, err := os.Stat(target); len(actions) == 1 && os.IsNotExist(err) {
+			plan.Actions = append(plan.Actions, actions[0])
+			continue
+		}
+		for _, a := range actions {
+			plan.Collisions[target] = append(plan.Collisions[target], a.Source)
+		}
+		sort.Strings(plan.Collisions[target])
+	}
+	sort.Slice(plan.Actions, func(i, j int) bool {
+		return plan.Actions[i].Source < plan.Actions[j].Source
+	})
+	sort.Strings(plan.Undated)
+	return plan, nil
+}
+
+// ApplyOrganizePlan moves or copies the files of a plan computed by PlanOrganize, creating the
+// target directories when required. Moved files are renamed, or copied, checked and removed when
+// the destination is on another volume (e.g. from a memory card to a library). Copies keep the
+// permissions and the modification time of the files. ErrRenameCollision is returned, before moving or copying anything, if the
+// plan contains collisions, and when a target has been created since the plan was computed.
+// Files moved or copied before a failure are left as is.
+func ApplyOrganizePlan(plan OrganizePlan) error {
+	if len(plan.Collisions) > 0 {
+		return fmt.Errorf("%w: %v files", ErrRenameCollision, len(plan.Collisions))
+	}
+
+	for _, a := range plan.Actions {
+		if _, err := os.Stat(a.Target); err == nil {
+			return fmt.Errorf("%w: %v already exists", ErrRenameCollision, a.Target)
+		}
+		if err := os.MkdirAll(filepath.Dir(a.Target), 0755); err != nil {
+			return fmt.Errorf("error while creating directory of %v: %w", a.Target, err)
+		}
+		switch plan.Mode {
+		case OrganizeCopy:
+			if err := copyFileWithTimes(a.Source, a.Target); err != nil {
+				return fmt.Errorf("error while copying %v: %w", a.Source, err)
+			}
+		default:
+			if err := moveFile(a.Source, a.Target); err != nil {
+				return fmt.Errorf("error while moving %v: %w", a.Source, err)
+			}
+		}
+	}
+	return nil
+}
+
+// renameFile renames files, replaced by tests to simulate moves between volumes
+var renameFile = os.Rename
+
+// moveFile renames a file, falling back to a copy when the destination is on another volume: the
+// source is only removed once the content of the copy has been checked
+func moveFile(src, dest string) error {
+	err := renameFile(src, dest)
+	if err == nil || !errors.Is(err, errCrossDevice) {
+		return err
+	}
+
+	if err := copyFileWithTimes(src, dest); err != nil {
+		return err
+	}
+	sums, err := computeChecksums(src, []crypto.Hash{crypto.SHA256})
+	if err != nil {
+		os.Remove(dest)
+		return err
+	}
+	copySums, err := computeChecksums(dest, []crypto.Hash{crypto.SHA256})
+	if err != nil {
+		os.Remove(dest)
+		return err
+	}
+	if sums[crypto.SHA256] != copySums[crypto.SHA256] {
+		os.Remove(dest)
+		return fmt.Errorf("copy of %v to %v differs from the original", src, dest)
+	}
+	return os.Remove(src)
+}
+
+// copyFileWithTimes copies a file, keeping its permissions and its modification time. The
+// destination is removed if the copy fails.
+func copyFileWithTimes(src, dest string) (err error) {
+	fi, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	s, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	d, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(dest)
+		}
+	}()
+	if _, err = io.Copy(d, s); err != nil {
+		d.Close()
+		return err
+	}
+	if err = d.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(dest, fi.ModTime(), fi.ModTime())
+}
@@ -0,0 +1,156 @@
+package exiftool
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanOrganize(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "dest")
+	noDate := filepath.Join(dir, "nodate.jpg")
+	require.Nil(t, ioutil.WriteFile(noDate, nil, 0644))
+	modTime := time.Date(2018, 2, 3, 4, 5, 6, 0, time.Local)
+	require.Nil(t, os.Chtimes(noDate, modTime, modTime))
+	existing := filepath.Join(dest, "2019", "05", "existing.jpg")
+	require.Nil(t, os.MkdirAll(filepath.Dir(existing), 0755))
+	require.Nil(t, ioutil.WriteFile(existing, nil, 0644))
+
+	dated := func(file, date string) FileMetadata {
+		return NewFileMetadata(file).WithString("DateTimeOriginal", date)
+	}
+	failed := NewFileMetadata("failed.jpg")
+	failed.Err = ErrNotExist
+	fms := []FileMetadata{
+		dated("a/IMG_1.jpg", "2019:04:04 13:18:04"),
+		dated("b/IMG_1.jpg", "2019:04:04 15:00:00"),
+		dated("b/IMG_2.jpg", "2019:04:05 10:00:00+02:00"),
+		dated("other/existing.jpg", "2019:05:01 10:00:00"),
+		NewFileMetadata(noDate),
+		failed,
+	}
+
+	plan, err := planOrganize(fms, dest, "%Y/%m", OrganizeOptions{Mode: OrganizeCopy})
+	require.Nil(t, err)
+	assert.Equal(t, OrganizeCopy, plan.Mode)
+	require.Len(t, plan.Actions, 2)
+	// absolute paths sort first
+	assert.Equal(t, noDate, plan.Actions[0].Source)
+	assert.Equal(t, filepath.Join(dest, "2018", "02", "nodate.jpg"), plan.Actions[0].Target)
+	assert.Equal(t, DateFromFileTime, plan.Actions[0].DateSource)
+	assert.Equal(t, "b/IMG_2.jpg", plan.Actions[1].Source)
+	assert.Equal(t, filepath.Join(dest, "2019", "04", "IMG_2.jpg"), plan.Actions[1].Target)
+	assert.Equal(t, DateFromCaptureTime, plan.Actions[1].DateSource)
+	assert.Equal(t, map[string][]string{
+		filepath.Join(dest, "2019", "04", "IMG_1.jpg"): {"a/IMG_1.jpg", "b/IMG_1.jpg"},
+		existing: {"other/existing.jpg"},
+	}, plan.Collisions)
+	assert.Equal(t, map[string]error{"failed.jpg": ErrNotExist}, plan.Failed)
+	assert.Empty(t, plan.Undated)
+
+	plan, err = planOrganize(fms, dest, "%Y/%m", OrganizeOptions{NoFileTimeFallback: true})
+	require.Nil(t, err)
+	assert.Len(t, plan.Actions, 1)
+	assert.Equal(t, []string{noDate}, plan.Undated)
+
+	_, err = planOrganize(fms, dest, "%Q", OrganizeOptions{})
+	assert.NotNil(t, err)
+}
+
+func TestOrganize(t *testing.T) {
+	t.Parallel()
+
+	var tcs = []struct {
+		tcID       string
+		inMode     OrganizeMode
+		expSrcKept bool
+	}{
+		{"move", OrganizeMove, false},
+		{"copy", OrganizeCopy, true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			t.Parallel()
+
+			src, dest := t.TempDir(), t.TempDir()
+			require.Nil(t, copyFile("testdata/20190404_131804.jpg", filepath.Join(src, "a.jpg")))
+
+			e, err := NewExiftool()
+			require.Nil(t, err)
+			defer e.Close()
+
+			plan, err := e.PlanOrganize(src, dest, "%Y/%m", OrganizeOptions{Mode: tc.inMode})
+			require.Nil(t, err)
+			require.Len(t, plan.Actions, 1)
+			target := filepath.Join(dest, "2019", "04", "a.jpg")
+			assert.Equal(t, target, plan.Actions[0].Target)
+
+			require.Nil(t, ApplyOrganizePlan(plan))
+			_, err = os.Stat(target)
+			assert.Nil(t, err)
+			_, err = os.Stat(filepath.Join(src, "a.jpg"))
+			assert.Equal(t, tc.expSrcKept, err == nil)
+
+			assert.True(t, errors.Is(ApplyOrganizePlan(plan), ErrRenameCollision))
+		})
+	}
+}
+
+func TestMoveFile(t *testing.T) {
+	var tcs = []struct {
+		tcID       string
+		inErr      error
+		expErr     bool
+		expSrcKept bool
+	}{
+		{"crossDevice", errCrossDevice, false, false},
+		{"otherError", errors.New("rename error"), true, true},
+	}
+
+	defer func() { renameFile = os.Rename }()
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			dir := t.TempDir()
+			src, dest := filepath.Join(dir, "a.jpg"), filepath.Join(dir, "b.jpg")
+			require.Nil(t, ioutil.WriteFile(src, []byte("content"), 0644))
+			modTime := time.Date(2019, time.April, 4, 13, 18, 3, 0, time.UTC)
+			require.Nil(t, os.Chtimes(src, modTime, modTime))
+			renameFile = func(old, new string) error {
+				return &os.LinkError{Op: "rename", Old: old, New: new, Err: tc.inErr}
+			}
+
+			err := moveFile(src, dest)
+			assert.Equal(t, tc.expErr, err != nil)
+			_, err = os.Stat(src)
+			assert.Equal(t, tc.expSrcKept, err == nil)
+			if !tc.expErr {
+				content, err := ioutil.ReadFile(dest)
+				require.Nil(t, err)
+				assert.Equal(t, "content", string(content))
+				fi, err := os.Stat(dest)
+				require.Nil(t, err)
+				assert.True(t, modTime.Equal(fi.ModTime()))
+			}
+		})
+	}
+}
+
+func TestCopyFileWithTimesFailure(t *testing.T) {
+	dir := t.TempDir()
+	src, dest := filepath.Join(dir, "src"), filepath.Join(dir, "dest")
+	require.Nil(t, os.Mkdir(src, 0755))
+
+	assert.NotNil(t, copyFileWithTimes(src, dest))
+	_, err := os.Stat(dest)
+	assert.True(t, os.IsNotExist(err))
+}
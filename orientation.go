@@ -0,0 +1,102 @@
+package exiftool
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Orientation is the EXIF orientation of an image, describing how it has to be transformed to be
+// displayed upright
+type Orientation int
+
+// Orientation values, as defined by the EXIF specification
+const (
+	OrientationUnknown                     Orientation = 0
+	OrientationNormal                      Orientation = 1
+	OrientationMirrorHorizontal            Orientation = 2
+	OrientationRotate180                   Orientation = 3
+	OrientationMirrorVertical              Orientation = 4
+	OrientationMirrorHorizontalRotate270CW Orientation = 5
+	OrientationRotate90CW                  Orientation = 6
+	OrientationMirrorHorizontalRotate90CW  Orientation = 7
+	OrientationRotate270CW                 Orientation = 8
+)
+
+// orientationNames are exiftool's print converted orientations
+var orientationNames = map[Orientation]string{
+	OrientationNormal:                      "Horizontal (normal)",
+	OrientationMirrorHorizontal:            "Mirror horizontal",
+	OrientationRotate180:                   "Rotate 180",
+	OrientationMirrorVertical:              "Mirror vertical",
+	OrientationMirrorHorizontalRotate270CW: "Mirror horizontal and rotate 270 CW",
+	OrientationRotate90CW:                  "Rotate 90 CW",
+	OrientationMirrorHorizontalRotate90CW:  "Mirror horizontal and rotate 90 CW",
+	OrientationRotate270CW:                 "Rotate 270 CW",
+}
+
+// String returns exiftool's print converted value of the orientation
+func (o Orientation) String() string {
+	if n, found := orientationNames[o]; found {
+		return n
+	}
+	return "Unknown (" + strconv.Itoa(int(o)) + ")"
+}
+
+// Degrees returns the clockwise rotation (0, 90, 180 or 270) to apply, after the optional
+// horizontal mirroring, to display the image upright
+func (o Orientation) Degrees() int {
+	switch o {
+	case OrientationRotate180, OrientationMirrorVertical:
+		return 180
+	case OrientationRotate90CW, OrientationMirrorHorizontalRotate90CW:
+		return 90
+	case OrientationRotate270CW, OrientationMirrorHorizontalRotate270CW:
+		return 270
+	default:
+		return 0
+	}
+}
+
+// Mirrored returns true if the image has to be mirrored horizontally to be displayed upright
+func (o Orientation) Mirrored() bool {
+	switch o {
+	case OrientationMirrorHorizontal, OrientationMirrorVertical,
+		OrientationMirrorHorizontalRotate270CW, OrientationMirrorHorizontalRotate90CW:
+		return true
+	default:
+		return false
+	}
+}
+
+// SwapsDimensions returns true if the displayed width and height are the stored height and width
+func (o Orientation) SwapsDimensions() bool {
+	return o.Degrees() == 90 || o.Degrees() == 270
+}
+
+// GetOrientation returns the Orientation tag, whether it is print converted ("Rotate 90 CW") or
+// not (6).
+// KeyNotFoundError will be returned if the key can't be found.
+func (fm FileMetadata) GetOrientation() (Orientation, error) {
+	v, err := fm.GetString("Orientation")
+	if err != nil {
+		return OrientationUnknown, err
+	}
+	return ParseOrientation(v)
+}
+
+// ParseOrientation parses an orientation, print converted ("Rotate 90 CW") or not (6)
+func ParseOrientation(v string) (Orientation, error) {
+	v = strings.TrimSpace(v)
+	if i, err := strconv.Atoi(v); err == nil {
+		if _, found := orientationNames[Orientation(i)]; found {
+			return Orientation(i), nil
+		}
+	}
+	for o, n := range orientationNames {
+		if strings.EqualFold(n, v) {
+			return o, nil
+		}
+	}
+	return OrientationUnknown, fmt.Errorf("invalid orientation %q", v)
+}
@@ -0,0 +1,67 @@
+package exiftool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrientation(t *testing.T) {
+	var tcs = []struct {
+		in          Orientation
+		expString   string
+		expDegrees  int
+		expMirrored bool
+		expSwap     bool
+	}{
+		{OrientationNormal, "Horizontal (normal)", 0, false, false},
+		{OrientationMirrorHorizontal, "Mirror horizontal", 0, true, false},
+		{OrientationRotate180, "Rotate 180", 180, false, false},
+		{OrientationMirrorVertical, "Mirror vertical", 180, true, false},
+		{OrientationMirrorHorizontalRotate270CW, "Mirror horizontal and rotate 270 CW", 270, true, true},
+		{OrientationRotate90CW, "Rotate 90 CW", 90, false, true},
+		{OrientationMirrorHorizontalRotate90CW, "Mirror horizontal and rotate 90 CW", 90, true, true},
+		{OrientationRotate270CW, "Rotate 270 CW", 270, false, true},
+		{OrientationUnknown, "Unknown (0)", 0, false, false},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.expString, func(t *testing.T) {
+			assert.Equal(t, tc.expString, tc.in.String())
+			assert.Equal(t, tc.expDegrees, tc.in.Degrees())
+			assert.Equal(t, tc.expMirrored, tc.in.Mirrored())
+			assert.Equal(t, tc.expSwap, tc.in.SwapsDimensions())
+		})
+	}
+}
+
+func TestGetOrientation(t *testing.T) {
+	var tcs = []struct {
+		tcID   string
+		inVal  interface{}
+		expVal Orientation
+		expErr bool
+	}{
+		{"printConverted", "Rotate 90 CW", OrientationRotate90CW, false},
+		{"caseInsensitive", "rotate 270 cw", OrientationRotate270CW, false},
+		{"numeric", float64(3), OrientationRotate180, false},
+		{"numericString", "8", OrientationRotate270CW, false},
+		{"outOfRange", float64(9), OrientationUnknown, true},
+		{"invalid", "upside down", OrientationUnknown, true},
+		{"missing", nil, OrientationUnknown, true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			fm := EmptyFileMetadata()
+			if tc.inVal != nil {
+				fm.Fields["Orientation"] = tc.inVal
+			}
+			got, err := fm.GetOrientation()
+			assert.Equal(t, tc.expErr, err != nil)
+			assert.Equal(t, tc.expVal, got)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package exiftool
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// OutputParser splits the output of exiftool run with -stay_open True into the responses of the
+// commands, which are followed by a {ready} token. It can be used to build custom transports.
+// Sample :
+//   p := NewOutputParser(stdout)
+//   for {
+//     resp, err := p.Next()
+//     if err != nil {
+//       break
+//     }
+//     fms, err := ParseJSONResponse(resp)
+//     ...
+//   }
+type OutputParser struct {
+	scanner *bufio.Scanner
+}
+
+// NewOutputParser creates an OutputParser reading r
+func NewOutputParser(r io.Reader) *OutputParser {
+	s := bufio.NewScanner(r)
+	s.Split(splitReadyToken)
+	return &OutputParser{scanner: s}
+}
+
+// Buffer sets the buffer used to read a response and its maximum size (see the Buffer option), it
+// must be called before Next
+func (p *OutputParser) Buffer(buf []byte, max int) {
+	p.scanner.Buffer(buf, max)
+}
+
+// Next returns the next response, which is only valid until the next call. io.EOF is returned
+// when the output is over, ErrBufferTooSmall when the response is bigger than the buffer (the
+// parser can't be used anymore).
+func (p *OutputParser) Next() ([]byte, error) {
+	if p.scanner.Scan() {
+		return p.scanner.Bytes(), nil
+	}
+	if err := p.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, ErrBufferTooSmall
+		}
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func splitReadyToken(data []byte, atEOF bool) (int, []byte, error) {
+	idx := bytes.Index(data, readyToken)
+	if idx == -1 {
+		if atEOF && len(data) > 0 {
+			return 0, data, fmt.Errorf("no final token found")
+		}
+
+		return 0, nil, nil
+	}
+
+	return idx + readyTokenLen, data[:idx], nil
+}
+
+// ParseJSONResponse parses the response of an extraction (exiftool's -j output), File being set
+// from the SourceFile field
+func ParseJSONResponse(resp []byte) ([]FileMetadata, error) {
+	var m []map[string]interface{}
+	if err := json.Unmarshal(resp, &m); err != nil {
+		return nil, fmt.Errorf("error during unmarshaling (%v): %w)", string(resp), err)
+	}
+	if len(m) == 0 {
+		return nil, fmt.Errorf("no metadata in response (%v)", string(resp))
+	}
+	fms := make([]FileMetadata, len(m))
+	for i, fields := range m {
+		fms[i] = EmptyFileMetadata()
+		fms[i].Fields = fields
+		fms[i].File, _ = fields["SourceFile"].(string)
+	}
+	return fms, nil
+}
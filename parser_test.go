@@ -0,0 +1,68 @@
+package exiftool
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputParser(t *testing.T) {
+	out := `[{"SourceFile": "a.jpg"}]` + string(readyToken) + "    1 image files updated\n" + string(readyToken)
+	p := NewOutputParser(strings.NewReader(out))
+
+	resp, err := p.Next()
+	require.Nil(t, err)
+	assert.Equal(t, `[{"SourceFile": "a.jpg"}]`, string(resp))
+	resp, err = p.Next()
+	require.Nil(t, err)
+	assert.Equal(t, "    1 image files updated\n", string(resp))
+	_, err = p.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestOutputParserErrors(t *testing.T) {
+	p := NewOutputParser(strings.NewReader("incomplete response"))
+	_, err := p.Next()
+	assert.NotNil(t, err)
+	assert.NotEqual(t, io.EOF, err)
+
+	p = NewOutputParser(strings.NewReader(strings.Repeat("a", 100) + string(readyToken)))
+	p.Buffer(make([]byte, 10), 20)
+	_, err = p.Next()
+	assert.Equal(t, ErrBufferTooSmall, err)
+}
+
+func TestParseJSONResponse(t *testing.T) {
+	var tcs = []struct {
+		tcID     string
+		inResp   string
+		expOk    bool
+		expFiles []string
+	}{
+		{"single", `[{"SourceFile": "a.jpg", "Make": "Canon"}]`, true, []string{"a.jpg"}},
+		{"multiple", `[{"SourceFile": "a.jpg"}, {"SourceFile": "b.jpg"}]`, true, []string{"a.jpg", "b.jpg"}},
+		{"noSourceFile", `[{"Make": "Canon"}]`, true, []string{""}},
+		{"empty", `[]`, false, nil},
+		{"notJSON", `Error: File not found - a.jpg`, false, nil},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			fms, err := ParseJSONResponse([]byte(tc.inResp))
+			assert.Equal(t, tc.expOk, err == nil)
+			if !tc.expOk {
+				return
+			}
+			var files []string
+			for _, fm := range fms {
+				files = append(files, fm.File)
+				assert.NotNil(t, fm.Fields)
+			}
+			assert.Equal(t, tc.expFiles, files)
+		})
+	}
+}
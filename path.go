@@ -0,0 +1,45 @@
+package exiftool
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidPath is a sentinel error that is returned when a path can't be used on the current
+// platform (e.g. a Windows reserved device name)
+var ErrInvalidPath = errors.New("invalid path")
+
+// windowsReservedNames are the device names that can't be used as file names on Windows, whatever
+// their extension
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// normalizeWindowsPath uses backslashes as separators and rejects, with ErrInvalidPath, the
+// paths containing a reserved device name (e.g. "NUL" or "con.jpg") or a component ending with a
+// dot or a space (Windows silently strips them). Extended-length paths (\\?\...) are left as is.
+func normalizeWindowsPath(p string) (string, error) {
+	if strings.HasPrefix(p, `\\?\`) {
+		return p, nil
+	}
+	p = strings.Replace(p, "/", `\`, -1)
+	for _, c := range strings.Split(p, `\`) {
+		if c == "" || c == "." || c == ".." {
+			continue
+		}
+		if strings.HasSuffix(c, ".") || strings.HasSuffix(c, " ") {
+			return "", fmt.Errorf("%w: %q ends with a dot or a space", ErrInvalidPath, c)
+		}
+		name := c
+		if i := strings.Index(name, "."); i >= 0 {
+			name = name[:i]
+		}
+		if windowsReservedNames[strings.ToUpper(strings.TrimRight(name, " "))] {
+			return "", fmt.Errorf("%w: %q is a reserved device name", ErrInvalidPath, c)
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,38 @@
+package exiftool
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeWindowsPath(t *testing.T) {
+	var tcs = []struct {
+		tcID    string
+		inPath  string
+		expPath string
+		expErr  error
+	}{
+		{"nominal", `C:\photos\a.jpg`, `C:\photos\a.jpg`, nil},
+		{"mixedSlashes", `C:/photos\sub/a.jpg`, `C:\photos\sub\a.jpg`, nil},
+		{"relative", `../photos/./a.jpg`, `..\photos\.\a.jpg`, nil},
+		{"unc", `\\server\share\a.jpg`, `\\server\share\a.jpg`, nil},
+		{"extendedLength", `\\?\C:\photos\nul.`, `\\?\C:\photos\nul.`, nil},
+		{"reservedName", `C:\photos\NUL`, "", ErrInvalidPath},
+		{"reservedNameWithExtension", `C:\photos\con.jpg`, "", ErrInvalidPath},
+		{"reservedFolder", `C:\Lpt1\a.jpg`, "", ErrInvalidPath},
+		{"notReserved", `C:\photos\console.jpg`, `C:\photos\console.jpg`, nil},
+		{"trailingDot", `C:\photos\a.jpg.`, "", ErrInvalidPath},
+		{"trailingSpace", `C:\photos \a.jpg`, "", ErrInvalidPath},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			p, err := normalizeWindowsPath(tc.inPath)
+			assert.True(t, errors.Is(err, tc.expErr), "%v", err)
+			assert.Equal(t, tc.expPath, p)
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package exiftool
+
+import "syscall"
+
+// setParentDeathSignal makes the kernel kill exiftool when the application dies without closing
+// it (PR_SET_PDEATHSIG). The signal is actually sent when the thread that started exiftool exits,
+// which the Go runtime only does for threads locked by a goroutine that ended (runtime.LockOSThread).
+func setParentDeathSignal(attr *syscall.SysProcAttr) {
+	attr.Pdeathsig = syscall.SIGKILL
+}
@@ -0,0 +1,14 @@
+package exiftool
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSysProcAttrParentDeathSignal(t *testing.T) {
+	for _, a := range []processAttrs{{}, {newGroup: true}, {detached: true}} {
+		assert.Equal(t, syscall.SIGKILL, sysProcAttr(a).Pdeathsig)
+	}
+}
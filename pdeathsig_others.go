@@ -0,0 +1,10 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+package exiftool
+
+import "syscall"
+
+// setParentDeathSignal does nothing, the parent death signal being specific to Linux
+func setParentDeathSignal(attr *syscall.SysProcAttr) {
+}
@@ -4,4 +4,15 @@ var readyToken = []byte("{ready}\n")
 
 const writeMetadataSuccessToken = "image files updated\n"
 
+const writeMetadataCreatedToken = "image files created\n"
+
 var exiftoolBinary = "exiftool"
+
+// filenameCharset is the charset of the file names passed to exiftool by default (see Charset), "" for exiftool's default one
+const filenameCharset = ""
+
+// normalizePath validates and normalizes the paths sent to exiftool
+var normalizePath = func(p string) (string, error) { return p, nil }
+
+// exiftoolAltBinaries are the other names of the exiftool executable, looked for when exiftoolBinary is not found
+var exiftoolAltBinaries []string
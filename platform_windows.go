@@ -4,4 +4,15 @@ var readyToken = []byte("{ready}\r\n")
 
 const writeMetadataSuccessToken = "image files updated\r\n"
 
+const writeMetadataCreatedToken = "image files created\r\n"
+
 var exiftoolBinary = "exiftool.exe"
+
+// filenameCharset is the charset of the file names passed to exiftool by default (see Charset), "" for exiftool's default one
+const filenameCharset = "utf8"
+
+// normalizePath validates and normalizes the paths sent to exiftool
+var normalizePath = normalizeWindowsPath
+
+// exiftoolAltBinaries are the other names of the exiftool executable, looked for when exiftoolBinary is not found
+var exiftoolAltBinaries = []string{windowsDistributionBinary}
@@ -0,0 +1,65 @@
+package exiftool
+
+// PhotoPreset configures the instance for photo libraries: exiftool doesn't scan the end of the
+// files for trailers (-fast) and GPS coordinates are output as signed decimal degrees. Options
+// given after the preset override it.
+// Key tags are not made numeric with exiftool's per-tag -n ("-Orientation#", "-GPSLatitude#"):
+// passed for every command, these arguments would restrict the extraction to these tags. GPS
+// coordinates are numeric thanks to the coordinate format, and GetOrientation parses both forms
+// of the Orientation tag. File names are passed as UTF-8 on Windows whatever the preset (see
+// Charset).
+// Sample :
+//   e, err := NewExiftool(PhotoPreset(), PrintGroupNames("0"))
+func PhotoPreset() func(*Exiftool) error {
+	return presetOptions(
+		extraInitArgs("-fast"),
+		CoordFormant("%+.8f"),
+	)
+}
+
+// VideoPreset configures the instance for video libraries: embedded metadata (e.g. timed GPS
+// tracks) is extracted (see ExtractEmbedded), files larger than 2GB are supported, QuickTime
+// dates are converted from UTC to local time (exiftool's QuickTimeUTC API option, see
+// https://exiftool.org/ExifTool.html#QuickTimeUTC).
+// Sample :
+//   e, err := NewExiftool(VideoPreset())
+func VideoPreset() func(*Exiftool) error {
+	return presetOptions(
+		ExtractEmbedded(),
+		Api("largefilesupport=1"),
+		Api("QuickTimeUTC=1"),
+	)
+}
+
+// ForensicPreset configures the instance to extract as much information as possible: unknown tags
+// (-u -U), duplicate tags (-a, distinguished by their group, see PrintGroupNames("1")) and the
+// result of the validation of the metadata structure (-validate, see the Validate, Warning and
+// Error tags). Extraction is slower and FileMetadata bigger.
+// Sample :
+//   e, err := NewExiftool(ForensicPreset())
+func ForensicPreset() func(*Exiftool) error {
+	return presetOptions(
+		extraInitArgs("-u", "-U", "-a", "-validate"),
+		PrintGroupNames("1"),
+	)
+}
+
+// presetOptions applies options in order
+func presetOptions(opts ...func(*Exiftool) error) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		for _, opt := range opts {
+			if err := opt(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// extraInitArgs appends arguments to the ones passed to exiftool for every command
+func extraInitArgs(args ...string) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		e.extraInitArgs = append(e.extraInitArgs, args...)
+		return nil
+	}
+}
@@ -0,0 +1,48 @@
+package exiftool
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresets(t *testing.T) {
+	var tcs = []struct {
+		tcID    string
+		inOpt   func(*Exiftool) error
+		expArgs []string
+	}{
+		{"photo", PhotoPreset(), []string{"-fast", "-coordFormat", "%+.8f"}},
+		{"video", VideoPreset(), []string{"-ee", "-api", "largefilesupport=1", "-api", "QuickTimeUTC=1"}},
+		{"forensic", ForensicPreset(), []string{"-u", "-U", "-a", "-validate", "-G1"}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			e := Exiftool{}
+			require.Nil(t, tc.inOpt(&e))
+			assert.Equal(t, tc.expArgs, e.extraInitArgs)
+		})
+	}
+}
+
+func TestPresetOptionsError(t *testing.T) {
+	err := presetOptions(func(*Exiftool) error {
+		return errors.New("error")
+	})(&Exiftool{})
+	assert.NotNil(t, err)
+}
+
+func TestPhotoPresetExtraction(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool(PhotoPreset())
+	require.Nil(t, err)
+	defer e.Close()
+	mds := e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	require.Len(t, mds, 1)
+	assert.Nil(t, mds[0].Err)
+}
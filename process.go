@@ -0,0 +1,59 @@
+package exiftool
+
+import "fmt"
+
+// processAttrs are the OS specific attributes of the exiftool process
+type processAttrs struct {
+	hideWindow bool
+	newGroup   bool
+	detached   bool
+}
+
+// ProcessAttribute is a portable attribute of the exiftool process, see ProcessAttributes
+type ProcessAttribute int
+
+const (
+	// NewProcessGroup starts exiftool in a new process group, so that the signals sent to the group
+	// of the application (e.g. Ctrl+C in a terminal) don't reach it. On Windows, the process is
+	// created with CREATE_NEW_PROCESS_GROUP, elsewhere its process group ID is set (Setpgid).
+	NewProcessGroup ProcessAttribute = iota
+	// DetachedSession detaches exiftool from the terminal of the application. On Windows, the
+	// process is created with DETACHED_PROCESS, elsewhere in a new session (Setsid, which implies a
+	// new process group).
+	DetachedSession
+	// HiddenWindow prevents exiftool from opening a console window on Windows (HideWindow and
+	// CREATE_NO_WINDOW), it has no effect on other platforms
+	HiddenWindow
+)
+
+// ProcessAttributes sets portable attributes of the exiftool process, translated to the
+// attributes of the current platform (see ProcessAttribute), so that cross-platform applications
+// don't need their own build tags
+// Sample :
+//   e, err := NewExiftool(ProcessAttributes(NewProcessGroup, HiddenWindow))
+func ProcessAttributes(attrs ...ProcessAttribute) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		for _, a := range attrs {
+			switch a {
+			case NewProcessGroup:
+				e.processAttrs.newGroup = true
+			case DetachedSession:
+				e.processAttrs.detached = true
+			case HiddenWindow:
+				e.processAttrs.hideWindow = true
+			default:
+				return fmt.Errorf("unknown process attribute %v", a)
+			}
+		}
+		return nil
+	}
+}
+
+// HideWindowsConsole prevents exiftool from opening a console window on Windows, which GUI
+// applications would otherwise flash for every instance. It has no effect on other platforms.
+// It is a shortcut for ProcessAttributes(HiddenWindow).
+// Sample :
+//   e, err := NewExiftool(HideWindowsConsole())
+func HideWindowsConsole() func(*Exiftool) error {
+	return ProcessAttributes(HiddenWindow)
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package exiftool
+
+import "syscall"
+
+// sysProcAttr returns the attributes of the exiftool process
+func sysProcAttr(a processAttrs) *syscall.SysProcAttr {
+	attr := &syscall.SysProcAttr{}
+	if a.detached {
+		// a new session is also a new process group, Setpgid would fail
+		attr.Setsid = true
+	} else if a.newGroup {
+		attr.Setpgid = true
+	}
+	setParentDeathSignal(attr)
+	return attr
+}
@@ -0,0 +1,43 @@
+package exiftool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessAttributes(t *testing.T) {
+	var tcs = []struct {
+		tcID     string
+		inOpt    func(*Exiftool) error
+		expAttrs processAttrs
+	}{
+		{"hideWindowsConsole", HideWindowsConsole(), processAttrs{hideWindow: true}},
+		{"newProcessGroup", ProcessAttributes(NewProcessGroup), processAttrs{newGroup: true}},
+		{"all", ProcessAttributes(NewProcessGroup, DetachedSession, HiddenWindow), processAttrs{hideWindow: true, newGroup: true, detached: true}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			e := Exiftool{}
+			require.Nil(t, tc.inOpt(&e))
+			assert.Equal(t, tc.expAttrs, e.processAttrs)
+		})
+	}
+
+	assert.NotNil(t, ProcessAttributes(ProcessAttribute(42))(&Exiftool{}))
+}
+
+func TestProcessAttributesExtraction(t *testing.T) {
+	t.Parallel()
+
+	for _, attrs := range [][]ProcessAttribute{{NewProcessGroup, HiddenWindow}, {DetachedSession}} {
+		e, err := NewExiftool(ProcessAttributes(attrs...))
+		require.Nil(t, err)
+		fms := e.ExtractMetadata("./testdata/20190404_131804.jpg")
+		assert.Nil(t, fms[0].Err)
+		require.Nil(t, e.Close())
+	}
+}
@@ -0,0 +1,25 @@
+package exiftool
+
+import "syscall"
+
+// process creation flags, see https://learn.microsoft.com/en-us/windows/win32/procthread/process-creation-flags
+const (
+	createNoWindow  = 0x08000000
+	detachedProcess = 0x00000008
+)
+
+// sysProcAttr returns the attributes of the exiftool process
+func sysProcAttr(a processAttrs) *syscall.SysProcAttr {
+	attr := &syscall.SysProcAttr{}
+	if a.hideWindow {
+		attr.HideWindow = true
+		attr.CreationFlags |= createNoWindow
+	}
+	if a.newGroup {
+		attr.CreationFlags |= syscall.CREATE_NEW_PROCESS_GROUP
+	}
+	if a.detached {
+		attr.CreationFlags |= detachedProcess
+	}
+	return attr
+}
@@ -0,0 +1,156 @@
+package exiftool
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Profile is a set of options that can be loaded from a JSON file (see FromConfigFile), so that
+// exiftool's behavior can be changed without recompiling. Presets are applied first, then the
+// other options.
+// Sample file :
+//   {
+//     "binaryPath": "/usr/bin/exiftool",
+//     "presets": ["photo"],
+//     "charset": ["filename=utf8"],
+//     "api": [{"name": "QuickTimeUTC"}, {"name": "TimeZone", "value": "Europe/Paris"}],
+//     "printGroupNames": "0"
+//   }
+type Profile struct {
+	// BinaryPath is the exiftool binary (see SetExiftoolBinaryPath)
+	BinaryPath string `json:"binaryPath"`
+	// ConfigFile is an exiftool configuration file (see ConfigFile)
+	ConfigFile string `json:"configFile"`
+	// Presets are "photo", "video" or "forensic" (see PhotoPreset, VideoPreset and ForensicPreset)
+	Presets []string `json:"presets"`
+	// Charset are -charset values (see Charset)
+	Charset []string `json:"charset"`
+	// Api are validated -api values (see ApiOptions)
+	Api []ApiOption `json:"api"`
+	// CommonArgs are arguments passed to exiftool for every command (see CommonArgs)
+	CommonArgs []string `json:"commonArgs"`
+	// PrintGroupNames is the -G value (see PrintGroupNames)
+	PrintGroupNames string `json:"printGroupNames"`
+	// DateFormat and CoordFormat are the -d and -c values (see DateFormant and CoordFormant)
+	DateFormat  string `json:"dateFormat"`
+	CoordFormat string `json:"coordFormat"`
+
+	NoPrintConversion        bool     `json:"noPrintConversion"`
+	ExtractEmbedded          bool     `json:"extractEmbedded"`
+	BackupOriginal           bool     `json:"backupOriginal"`
+	ClearFieldsBeforeWriting bool     `json:"clearFieldsBeforeWriting"`
+	LenientKeys              bool     `json:"lenientKeys"`
+	AllowWriteTags           []string `json:"allowWriteTags"`
+	DenyWriteTags            []string `json:"denyWriteTags"`
+	AllowWriteFromFiles      bool     `json:"allowWriteFromFiles"`
+	AutoRestart              bool     `json:"autoRestart"`
+}
+
+// presets are the presets that can be referenced by a Profile
+var presets = map[string]func() func(*Exiftool) error{
+	"photo":    PhotoPreset,
+	"video":    VideoPreset,
+	"forensic": ForensicPreset,
+}
+
+// Options returns the options matching the profile, in the order they have to be applied
+func (p Profile) Options() ([]func(*Exiftool) error, error) {
+	var opts []func(*Exiftool) error
+	for _, name := range p.Presets {
+		preset, ok := presets[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown preset %q", name)
+		}
+		opts = append(opts, preset())
+	}
+	if p.BinaryPath != "" {
+		opts = append(opts, SetExiftoolBinaryPath(p.BinaryPath))
+	}
+	if p.ConfigFile != "" {
+		opts = append(opts, ConfigFile(p.ConfigFile))
+	}
+	for _, c := range p.Charset {
+		opts = append(opts, Charset(c))
+	}
+	if len(p.Api) > 0 {
+		opts = append(opts, ApiOptions(p.Api...))
+	}
+	if len(p.CommonArgs) > 0 {
+		opts = append(opts, CommonArgs(p.CommonArgs...))
+	}
+	if p.PrintGroupNames != "" {
+		opts = append(opts, PrintGroupNames(p.PrintGroupNames))
+	}
+	if p.DateFormat != "" {
+		opts = append(opts, DateFormant(p.DateFormat))
+	}
+	if p.CoordFormat != "" {
+		opts = append(opts, CoordFormant(p.CoordFormat))
+	}
+	flags := []struct {
+		enabled bool
+		opt     func() func(*Exiftool) error
+	}{
+		{p.NoPrintConversion, NoPrintConversion},
+		{p.ExtractEmbedded, ExtractEmbedded},
+		{p.BackupOriginal, BackupOriginal},
+		{p.ClearFieldsBeforeWriting, ClearFieldsBeforeWriting},
+		{p.LenientKeys, LenientKeys},
+		{p.AutoRestart, AutoRestart},
+	}
+	for _, f := range flags {
+		if f.enabled {
+			opts = append(opts, f.opt())
+		}
+	}
+	if len(p.AllowWriteTags) > 0 {
+		opts = append(opts, AllowWriteTags(p.AllowWriteTags...))
+	}
+	if len(p.DenyWriteTags) > 0 {
+		opts = append(opts, DenyWriteTags(p.DenyWriteTags...))
+	}
+	if p.AllowWriteFromFiles {
+		opts = append(opts, AllowWriteFromFiles())
+	}
+	return opts, nil
+}
+
+// LoadProfile reads a Profile from a JSON file, unknown fields being rejected
+func LoadProfile(path string) (Profile, error) {
+	var p Profile
+	f, err := os.Open(path)
+	if err != nil {
+		return p, fmt.Errorf("error while opening profile %v: %w", path, err)
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return p, fmt.Errorf("error while decoding profile %v: %w", path, err)
+	}
+	return p, nil
+}
+
+// FromConfigFile applies the options of a profile loaded from a JSON file (see Profile). Options
+// given after it override the profile.
+// Sample :
+//   e, err := NewExiftool(FromConfigFile("/etc/myapp/exiftool.json"))
+func FromConfigFile(path string) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		p, err := LoadProfile(path)
+		if err != nil {
+			return err
+		}
+		opts, err := p.Options()
+		if err != nil {
+			return fmt.Errorf("error while applying profile %v: %w", path, err)
+		}
+		for _, opt := range opts {
+			if err := opt(e); err != nil {
+				return fmt.Errorf("error while applying profile %v: %w", path, err)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,48 @@
+package exiftool
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromConfigFile(t *testing.T) {
+	e := Exiftool{}
+	require.Nil(t, FromConfigFile("./testdata/profile.json")(&e))
+
+	expArgs := []string{"-fast", "-coordFormat", "%+.8f", "-charset", "filename=utf8", "-api", "QuickTimeUTC", "-api", "TimeZone=Europe/Paris", "-G0"}
+	assert.Equal(t, expArgs, e.extraInitArgs)
+	assert.Equal(t, "testdata/custom.config", e.configFile)
+	assert.True(t, e.backupOriginal)
+	assert.Equal(t, []string{"filename"}, e.deniedWriteTags)
+}
+
+func TestFromConfigFileErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	var tcs = []struct {
+		tcID      string
+		inContent string
+	}{
+		{"invalidJSON", `{"presets": `},
+		{"unknownField", `{"binaryPth": "/usr/bin/exiftool"}`},
+		{"unknownPreset", `{"presets": ["audio"]}`},
+		{"invalidApi", `{"api": [{"name": ""}]}`},
+		{"reservedArg", `{"commonArgs": ["-stay_open"]}`},
+		{"missingBinary", `{"binaryPath": "/does/not/exist"}`},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			p := filepath.Join(dir, tc.tcID+".json")
+			require.Nil(t, ioutil.WriteFile(p, []byte(tc.inContent), 0644))
+			assert.NotNil(t, FromConfigFile(p)(&Exiftool{}))
+		})
+	}
+
+	assert.NotNil(t, FromConfigFile(filepath.Join(dir, "missing.json"))(&Exiftool{}))
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+// +build !windows
+
+package exiftool
+
+import "syscall"
+
+// errCrossDevice is the error returned when renaming a file to another volume
+var errCrossDevice error = syscall.EXDEV
@@ -0,0 +1,6 @@
+package exiftool
+
+import "syscall"
+
+// errCrossDevice is the error returned when renaming a file to another volume (ERROR_NOT_SAME_DEVICE)
+var errCrossDevice error = syscall.Errno(17)
@@ -0,0 +1,48 @@
+package exiftool
+
+import (
+	"fmt"
+	"time"
+)
+
+// AutoRestart restarts exiftool when it can't be communicated with anymore (e.g. the process has
+// exited, see EventCrashed) or when a response could not be read entirely (see EventDesync), so
+// that a long running application doesn't have to replace the instance. Exiftool is restarted
+// before the command following the failure: the command that failed is not sent again, its error
+// being returned as is.
+// Sample :
+//   e, err := NewExiftool(AutoRestart())
+func AutoRestart() func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		e.autoRestart = true
+		return nil
+	}
+}
+
+// Restart stops exiftool and starts it again with the same arguments, e.g. to recover from a crash
+// (see EventCrashed) when AutoRestart is not used. The instance can't be used anymore if exiftool
+// can't be started again, except by calling Restart again.
+func (e *Exiftool) Restart() error {
+	e.acquire()
+	defer e.lock.Unlock()
+	return e.restart()
+}
+
+func (e *Exiftool) restart() error {
+	if e.desynced {
+		// exiftool may be blocked writing the rest of a response
+		e.stdMergedOut.Close()
+	}
+	// errors are expected, exiftool being restarted because it failed
+	e.stop()
+	if err := e.start(); err != nil {
+		return fmt.Errorf("error when restarting exiftool: %w", err)
+	}
+	e.crashed, e.desynced = false, false
+	e.stats.restarted(time.Now())
+	if e.metrics != nil {
+		e.metrics.ObserveRestart()
+	}
+	e.emit(EventRestarted, nil)
+	return nil
+}
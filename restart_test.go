@@ -0,0 +1,59 @@
+package exiftool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoRestart(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool(AutoRestart())
+	require.Nil(t, err)
+	defer e.Close()
+	started := e.Stats().StartedAt
+
+	require.Nil(t, e.cmd.Process.Kill())
+	fms := e.ExtractMetadata("./testdata/20190404_131804.jpg", "./testdata/20190404_131804.jpg")
+	require.Len(t, fms, 2)
+	assert.NotNil(t, fms[0].Err)
+	assert.Nil(t, fms[1].Err)
+
+	s := e.Stats()
+	assert.Equal(t, int64(1), s.Restarts)
+	assert.True(t, s.StartedAt.After(started))
+}
+
+func TestRestart(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	require.Nil(t, e.cmd.Process.Kill())
+	fms := e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	assert.NotNil(t, fms[0].Err)
+	// not restarted automatically
+	fms = e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	assert.NotNil(t, fms[0].Err)
+
+	require.Nil(t, e.Restart())
+	fms = e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	assert.Nil(t, fms[0].Err)
+	assert.Equal(t, int64(1), e.Stats().Restarts)
+}
+
+func TestRestartError(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	e.exiftoolBinPath = "./doesNotExist"
+	assert.NotNil(t, e.Restart())
+	assert.Equal(t, int64(0), e.Stats().Restarts)
+}
@@ -0,0 +1,97 @@
+package exiftool
+
+import "crypto"
+
+// ConfigSnapshot describes the effective configuration of an Exiftool instance, see Options
+type ConfigSnapshot struct {
+	// BinaryPath is the exiftool binary
+	BinaryPath string
+	// Args are the arguments exiftool has been started with, including the ones passed to every
+	// command (after -common_args)
+	Args []string
+	// BufferSize and BufferMaxSize are the initial and maximum sizes of the buffer used to read the
+	// output of exiftool (see Buffer), 0 when the default buffer is used
+	BufferSize    int
+	BufferMaxSize int
+	// ConfigFile is the exiftool configuration file (see ConfigFile)
+	ConfigFile    string
+	ConfigFileSet bool
+
+	BackupOriginal           bool
+	ClearFieldsBeforeWriting bool
+	WriteChangedFieldsOnly   bool
+	XMPSidecar               bool
+	DryRun                   bool
+	AllowedWriteTags         []string
+	DeniedWriteTags          []string
+	WriteFromFiles           bool
+
+	DateFormat             string
+	DateLayouts            []string
+	Checksums              []crypto.Hash
+	DropBinaryPlaceholders bool
+	PreserveFieldOrder     bool
+	LenientKeys            bool
+	CustomDecoder          bool
+	ExtractConverters      int
+	WriteConverters        int
+	TraceProtocol          bool
+	ArgFile                string
+	Metrics                bool
+	Tracing                bool
+	BeforeHooks            int
+	AfterHooks             int
+	EventHandlers          int
+	AutoRestart            bool
+	ExpvarName             string
+	Transport              bool
+	HideWindowsConsole     bool
+	NewProcessGroup        bool
+	DetachedSession        bool
+}
+
+// Options returns the effective configuration of the instance, e.g. to log how it has been
+// configured
+func (e *Exiftool) Options() ConfigSnapshot {
+	s := ConfigSnapshot{
+		BinaryPath:               e.exiftoolBinPath,
+		ConfigFile:               e.configFile,
+		ConfigFileSet:            e.configFileSet,
+		BackupOriginal:           e.backupOriginal,
+		ClearFieldsBeforeWriting: e.clearFieldsBeforeWriting,
+		WriteChangedFieldsOnly:   e.writeChangedOnly,
+		XMPSidecar:               e.xmpSidecar,
+		DryRun:                   e.dryRun != nil,
+		AllowedWriteTags:         append([]string(nil), e.allowedWriteTags...),
+		DeniedWriteTags:          append([]string(nil), e.deniedWriteTags...),
+		WriteFromFiles:           e.writeFromFiles,
+		DateFormat:               e.dateFormat,
+		DateLayouts:              append([]string(nil), e.dateLayouts...),
+		Checksums:                append([]crypto.Hash(nil), e.checksums...),
+		DropBinaryPlaceholders:   e.dropBinaryPlaceholders,
+		PreserveFieldOrder:       e.preserveFieldOrder,
+		LenientKeys:              e.lenientKeys,
+		CustomDecoder:            e.decodeFunc != nil,
+		ExtractConverters:        len(e.extractConverters),
+		WriteConverters:          len(e.writeConverters),
+		TraceProtocol:            e.trace != nil,
+		ArgFile:                  e.argFilePath,
+		Metrics:                  e.metrics != nil,
+		Tracing:                  e.tracer != nil,
+		BeforeHooks:              len(e.beforeHooks),
+		AfterHooks:               len(e.afterHooks),
+		EventHandlers:            len(e.eventHandlers),
+		AutoRestart:              e.autoRestart,
+		ExpvarName:               e.expvarName,
+		Transport:                e.transport != nil,
+		HideWindowsConsole:       e.processAttrs.hideWindow,
+		NewProcessGroup:          e.processAttrs.newGroup,
+		DetachedSession:          e.processAttrs.detached,
+	}
+	s.Args = append([]string(nil), e.args...)
+	if e.bufferSet {
+		s.BufferSize = len(e.buffer)
+		s.BufferMaxSize = e.bufferMaxSize
+	}
+	return s
+}
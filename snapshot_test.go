@@ -0,0 +1,62 @@
+package exiftool
+
+import (
+	"bytes"
+	"crypto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptions(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool(
+		Buffer(make([]byte, 128*1000), 256*1000),
+		Charset("filename=utf8"),
+		BackupOriginal(),
+		DryRun(&bytes.Buffer{}),
+		DenyWriteTags("FileName"),
+		DateLayouts("2006/01/02"),
+		Checksums(crypto.MD5),
+		ConvertExtracted("*", trimConverter),
+	)
+	require.Nil(t, err)
+	defer e.Close()
+
+	got := e.Options()
+	assert.Equal(t, exiftoolBinary, got.BinaryPath)
+	assert.Equal(t, []string{"-stay_open", "True", "-@", "-", "-common_args", "-charset", "filename=utf8"}, got.Args)
+	assert.Equal(t, 128*1000, got.BufferSize)
+	assert.Equal(t, 256*1000, got.BufferMaxSize)
+	assert.True(t, got.BackupOriginal)
+	assert.True(t, got.DryRun)
+	assert.False(t, got.XMPSidecar)
+	assert.Equal(t, []string{"filename"}, got.DeniedWriteTags)
+	assert.Equal(t, []string{"2006/01/02"}, got.DateLayouts)
+	assert.Equal(t, []crypto.Hash{crypto.MD5}, got.Checksums)
+	assert.Equal(t, 1, got.ExtractConverters)
+	assert.Equal(t, 0, got.WriteConverters)
+
+	got.Args[0] = "changed"
+	assert.Equal(t, "-stay_open", e.Options().Args[0])
+}
+
+func TestOptionsDefault(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	got := e.Options()
+	expArgs := []string{"-stay_open", "True", "-@", "-"}
+	if filenameCharset != "" {
+		expArgs = append(expArgs, "-common_args", "-charset", "filename="+filenameCharset)
+	}
+	assert.Equal(t, expArgs, got.Args)
+	assert.Equal(t, 0, got.BufferSize)
+	assert.False(t, got.DryRun)
+	assert.False(t, got.CustomDecoder)
+}
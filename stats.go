@@ -0,0 +1,124 @@
+package exiftool
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats are cumulative statistics about an Exiftool instance, see (*Exiftool).Stats
+type Stats struct {
+	// Calls is the number of ExtractMetadata, WriteMetadata and WriteMetadataBatch calls (and their variants)
+	Calls int64
+	// Commands is the number of commands sent to exiftool, whatever the method that sent them
+	Commands int64
+	// Files and FailedFiles are the numbers of files processed by ExtractMetadata and WriteMetadata
+	// (and their variants), and of those whose FileMetadata.Err was set
+	Files       int64
+	FailedFiles int64
+	// BytesRead is the number of bytes read from exiftool
+	BytesRead int64
+	// TotalLatency is the cumulated round-trip duration of the commands, AverageLatency its average
+	TotalLatency   time.Duration
+	AverageLatency time.Duration
+	// StartedAt is the time exiftool has been started at (or restarted at, see Restart), Uptime the
+	// duration since then
+	StartedAt time.Time
+	Uptime    time.Duration
+	// Restarts is the number of times exiftool has been restarted (see AutoRestart and Restart)
+	Restarts int64
+	// Waiting is the number of calls waiting for the current one to end, an instance processing
+	// one call at a time (i.e. the depth of its queue)
+	Waiting int64
+	// LastError is the last error returned by a command or set in a FileMetadata.Err
+	LastError error
+}
+
+// stats are updated while the instance is locked, but have their own lock so that they can be read
+// while a call is in progress
+type stats struct {
+	lock         sync.Mutex
+	startedAt    time.Time
+	calls        int64
+	commands     int64
+	files        int64
+	failedFiles  int64
+	bytesRead    int64
+	totalLatency time.Duration
+	restarts     int64
+	lastError    error
+}
+
+func (s *stats) callStarted() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.calls++
+}
+
+func (s *stats) commandDone(d time.Duration, bytesRead int, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err != nil {
+		s.lastError = err
+	}
+	s.commands++
+	s.bytesRead += int64(bytesRead)
+	s.totalLatency += d
+}
+
+func (s *stats) totalBytesRead() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.bytesRead
+}
+
+func (s *stats) restarted(at time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.restarts++
+	s.startedAt = at
+}
+
+func (s *stats) filesDone(fms []FileMetadata) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, fm := range fms {
+		s.files++
+		if fm.Err != nil {
+			s.failedFiles++
+			s.lastError = fm.Err
+		}
+	}
+}
+
+// acquire locks the instance, the calls waiting for the lock being counted (see Stats.Waiting)
+func (e *Exiftool) acquire() {
+	atomic.AddInt64(&e.waiting, 1)
+	e.lock.Lock()
+	atomic.AddInt64(&e.waiting, -1)
+}
+
+// Stats returns cumulative statistics about the instance, for lightweight monitoring (see Metrics
+// for a full integration with a monitoring system). It can be called while a call is in progress.
+func (e *Exiftool) Stats() Stats {
+	e.stats.lock.Lock()
+	defer e.stats.lock.Unlock()
+
+	s := Stats{
+		Calls:        e.stats.calls,
+		Commands:     e.stats.commands,
+		Files:        e.stats.files,
+		FailedFiles:  e.stats.failedFiles,
+		BytesRead:    e.stats.bytesRead,
+		TotalLatency: e.stats.totalLatency,
+		StartedAt:    e.stats.startedAt,
+		Uptime:       time.Since(e.stats.startedAt),
+		Restarts:     e.stats.restarts,
+		Waiting:      atomic.LoadInt64(&e.waiting),
+		LastError:    e.stats.lastError,
+	}
+	if s.Commands > 0 {
+		s.AverageLatency = s.TotalLatency / time.Duration(s.Commands)
+	}
+	return s
+}
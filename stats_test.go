@@ -0,0 +1,60 @@
+package exiftool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	s := e.Stats()
+	assert.Equal(t, int64(0), s.Calls)
+	assert.Equal(t, time.Duration(0), s.AverageLatency)
+	assert.False(t, s.StartedAt.IsZero())
+
+	e.ExtractMetadata("./testdata/20190404_131804.jpg", "./testdata/nonExisting.jpg")
+	e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	e.WriteMetadata([]FileMetadata{{File: "./testdata/nonExisting.jpg"}})
+
+	s = e.Stats()
+	assert.Equal(t, int64(3), s.Calls)
+	assert.Equal(t, int64(2), s.Commands)
+	assert.Equal(t, int64(4), s.Files)
+	assert.Equal(t, int64(2), s.FailedFiles)
+	assert.True(t, s.BytesRead > 0)
+	assert.True(t, s.TotalLatency > 0)
+	assert.Equal(t, s.TotalLatency/2, s.AverageLatency)
+	assert.True(t, s.Uptime > 0)
+}
+
+func TestStatsWaiting(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	e.lock.Lock()
+	done := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		go func() {
+			e.ExtractMetadata("./testdata/20190404_131804.jpg")
+			done <- struct{}{}
+		}()
+	}
+	for e.Stats().Waiting != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	e.lock.Unlock()
+	<-done
+	<-done
+	assert.Equal(t, int64(0), e.Stats().Waiting)
+}
@@ -0,0 +1,97 @@
+package exiftool
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// strftimeLayouts maps the strftime conversion specifications (as used by exiftool's -dateFormat)
+// to their Go layout equivalent
+var strftimeLayouts = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'e': "_2",
+	'H': "15",
+	'I': "03",
+	'M': "04",
+	'S': "05",
+	'p': "PM",
+	'b': "Jan",
+	'h': "Jan",
+	'B': "January",
+	'a': "Mon",
+	'A': "Monday",
+	'z': "-0700",
+	'Z': "MST",
+	'F': "2006-01-02",
+	'T': "15:04:05",
+	'D': "01/02/06",
+}
+
+// formatStrftime formats a time according to a strftime format
+func formatStrftime(t time.Time, format string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			sb.WriteByte(format[i])
+			continue
+		}
+		i++
+		if i == len(format) {
+			return "", fmt.Errorf("incomplete conversion specification at the end of %q", format)
+		}
+		switch c := format[i]; c {
+		case '%':
+			sb.WriteByte('%')
+		case 's':
+			sb.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case 'j':
+			sb.WriteString(fmt.Sprintf("%03d", t.YearDay()))
+		default:
+			layout, found := strftimeLayouts[c]
+			if !found {
+				return "", fmt.Errorf("unsupported conversion specification %%%c in %q", c, format)
+			}
+			sb.WriteString(t.Format(layout))
+		}
+	}
+	return sb.String(), nil
+}
+
+// parseStrftime parses a time formatted according to a strftime format. Only the conversion
+// specifications having a Go layout equivalent (and %s alone) are supported.
+func parseStrftime(value string, format string) (time.Time, error) {
+	if format == "%s" {
+		sec, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid epoch %q: %w", value, err)
+		}
+		return time.Unix(sec, 0), nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			sb.WriteByte(format[i])
+			continue
+		}
+		i++
+		if i == len(format) {
+			return time.Time{}, fmt.Errorf("incomplete conversion specification at the end of %q", format)
+		}
+		if format[i] == '%' {
+			sb.WriteByte('%')
+			continue
+		}
+		layout, found := strftimeLayouts[format[i]]
+		if !found {
+			return time.Time{}, fmt.Errorf("unsupported conversion specification %%%c in %q", format[i], format)
+		}
+		sb.WriteString(layout)
+	}
+	return time.Parse(sb.String(), value)
+}
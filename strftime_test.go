@@ -0,0 +1,68 @@
+package exiftool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatStrftime(t *testing.T) {
+	d := time.Date(2019, time.April, 4, 13, 18, 3, 0, time.FixedZone("CEST", 2*3600))
+
+	tcs := []struct {
+		tcID   string
+		inFmt  string
+		expOk  bool
+		expVal string
+	}{
+		{"exif", "%Y:%m:%d %H:%M:%S", true, "2019:04:04 13:18:03"},
+		{"epoch", "%s", true, "1554376683"},
+		{"names", "%a %A %b %B %p", true, "Thu Thursday Apr April PM"},
+		{"short", "%y %e %I %j", true, "19  4 01 094"},
+		{"zone", "%z %Z", true, "+0200 CEST"},
+		{"composite", "%F %T %D", true, "2019-04-04 13:18:03 04/04/19"},
+		{"literals", "IMG_%Y%%-c 1", true, "IMG_2019%-c 1"},
+		{"unsupported", "%Q", false, ""},
+		{"incomplete", "%Y%", false, ""},
+	}
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			v, err := formatStrftime(d, tc.inFmt)
+			assert.Equal(t, tc.expOk, err == nil)
+			if tc.expOk {
+				assert.Equal(t, tc.expVal, v)
+			}
+		})
+	}
+}
+
+func TestParseStrftime(t *testing.T) {
+	tcs := []struct {
+		tcID    string
+		inValue string
+		inFmt   string
+		expOk   bool
+		expVal  time.Time
+	}{
+		{"exif", "2019:04:04 13:18:03", "%Y:%m:%d %H:%M:%S", true, time.Date(2019, time.April, 4, 13, 18, 3, 0, time.UTC)},
+		{"composite", "2019-04-04T13:18:03", "%FT%T", true, time.Date(2019, time.April, 4, 13, 18, 3, 0, time.UTC)},
+		{"zone", "20190404 131803 +0200", "%Y%m%d %H%M%S %z", true, time.Date(2019, time.April, 4, 11, 18, 3, 0, time.UTC)},
+		{"epoch", "1554376683", "%s", true, time.Date(2019, time.April, 4, 11, 18, 3, 0, time.UTC)},
+		{"invalidEpoch", "a", "%s", false, time.Time{}},
+		{"mismatch", "2019:04:04", "%Y-%m-%d", false, time.Time{}},
+		{"unsupported", "094", "%j", false, time.Time{}},
+		{"incomplete", "2019", "%Y%", false, time.Time{}},
+	}
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			v, err := parseStrftime(tc.inValue, tc.inFmt)
+			assert.Equal(t, tc.expOk, err == nil)
+			if tc.expOk {
+				assert.True(t, tc.expVal.Equal(v), "%v != %v", tc.expVal, v)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+// Package tags provides the group qualified keys of common exiftool tags (e.g. tags.EXIFDateTimeOriginal
+// is "EXIF:DateTimeOriginal"), to be used instead of hand written strings with the FileMetadata getters
+// and setters. Groups are exiftool's family 0 groups: keys match the fields extracted with the
+// PrintGroupNames("0") option (or any extraction when the LenientKeys option is enabled).
+// Sample :
+//   fm.SetString(tags.XMPTitle, "Holidays")
+//   rating, err := fm.GetInt(tags.XMPRating)
+package tags
+
+//go:generate go run gen.go
@@ -0,0 +1,165 @@
+//go:build ignore
+// +build ignore
+
+// gen generates tags.go: for each group (family 0) of commonTags, a block of constants named after
+// the group and the tag (e.g. EXIFDateTimeOriginal) whose values are the group qualified keys. Tags are checked against the output of
+// "exiftool -listx", which can be skipped with -novalidate when exiftool is not installed.
+// Usage : go run gen.go [-exiftool /path/to/exiftool] [-novalidate]
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// commonTags are the generated tags, by group (family 0)
+var commonTags = map[string][]string{
+	"EXIF": {
+		"Make", "Model", "Software", "Artist", "Copyright", "ImageDescription", "Orientation",
+		"XResolution", "YResolution", "ResolutionUnit", "ModifyDate", "DateTimeOriginal", "CreateDate",
+		"OffsetTime", "OffsetTimeOriginal", "OffsetTimeDigitized", "SubSecTime", "SubSecTimeOriginal",
+		"SubSecTimeDigitized", "ExposureTime", "FNumber", "ExposureProgram", "ISO", "ExposureCompensation",
+		"MaxApertureValue", "MeteringMode", "Flash", "FocalLength", "FocalLengthIn35mmFormat", "WhiteBalance",
+		"ColorSpace", "ExifImageWidth", "ExifImageHeight", "SerialNumber", "LensMake", "LensModel",
+		"LensSerialNumber", "LensInfo", "OwnerName", "UserComment", "GPSLatitude", "GPSLatitudeRef",
+		"GPSLongitude", "GPSLongitudeRef", "GPSAltitude", "GPSAltitudeRef", "GPSTimeStamp", "GPSDateStamp",
+		"GPSImgDirection", "GPSSpeed", "ThumbnailImage",
+	},
+	"IPTC": {
+		"ObjectName", "Keywords", "Caption-Abstract", "By-line", "By-lineTitle", "CopyrightNotice",
+		"Credit", "Source", "City", "Sub-location", "Province-State", "Country-PrimaryLocationName",
+		"Country-PrimaryLocationCode", "DateCreated", "TimeCreated", "Headline", "SpecialInstructions",
+		"Writer-Editor", "Category", "SupplementalCategories", "OriginalTransmissionReference",
+	},
+	"XMP": {
+		"Title", "Description", "Subject", "Creator", "Rights", "Rating", "Label", "HierarchicalSubject",
+		"DateCreated", "CreateDate", "ModifyDate", "MetadataDate", "CreatorTool", "Headline", "City",
+		"State", "Country", "CountryCode", "Location", "Credit", "Source", "Instructions", "UsageTerms",
+		"WebStatement", "Marked", "RegionInfo", "PersonInImage", "CreatorContactInfo", "DocumentID",
+		"InstanceID", "OriginalDocumentID", "PreservedFileName", "Lens",
+	},
+	"QuickTime": {
+		"CreateDate", "ModifyDate", "TrackCreateDate", "TrackModifyDate", "MediaCreateDate",
+		"MediaModifyDate", "CreationDate", "Duration", "ImageWidth", "ImageHeight", "VideoFrameRate",
+		"CompressorID", "AudioFormat", "AudioChannels", "AudioSampleRate", "Rotation", "GPSCoordinates",
+		"Make", "Model", "Software", "Title", "Artist", "Comment", "HandlerType", "MajorBrand",
+	},
+	"File": {
+		"FileName", "Directory", "FileSize", "FileModifyDate", "FileAccessDate", "FileCreateDate",
+		"FileInodeChangeDate", "FilePermissions", "FileType", "FileTypeExtension", "MIMEType",
+		"ImageWidth", "ImageHeight", "BitsPerSample", "ColorComponents", "EncodingProcess",
+	},
+	"Composite": {
+		"ImageSize", "Megapixels", "Aperture", "ShutterSpeed", "LightValue", "FocalLength35efl",
+		"ScaleFactor35efl", "CircleOfConfusion", "FOV", "HyperfocalDistance", "LensID", "GPSPosition",
+		"GPSLatitude", "GPSLongitude", "GPSAltitude", "GPSDateTime", "DateTimeOriginal", "SubSecCreateDate",
+		"SubSecDateTimeOriginal", "SubSecModifyDate", "Rotation", "AvgBitrate",
+	},
+}
+
+// taginfo is the subset of the "exiftool -listx" output needed to check the tags
+type taginfo struct {
+	Tables []struct {
+		G0   string `xml:"g0,attr"`
+		Tags []struct {
+			Name string `xml:"name,attr"`
+			G0   string `xml:"g0,attr"`
+		} `xml:"tag"`
+	} `xml:"table"`
+}
+
+func main() {
+	bin := flag.String("exiftool", "exiftool", "exiftool binary used to check the tags")
+	noValidate := flag.Bool("novalidate", false, "do not check the tags against exiftool -listx")
+	flag.Parse()
+
+	if !*noValidate {
+		if err := validate(*bin); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	src, err := format.Source(generate())
+	if err != nil {
+		log.Fatalf("error while formatting: %v", err)
+	}
+	if err := ioutil.WriteFile("tags.go", src, 0644); err != nil {
+		log.Fatalf("error while writing tags.go: %v", err)
+	}
+}
+
+func validate(bin string) error {
+	out, err := exec.Command(bin, "-listx").Output()
+	if err != nil {
+		return fmt.Errorf("error while running %v -listx: %w", bin, err)
+	}
+	var ti taginfo
+	if err := xml.Unmarshal(out, &ti); err != nil {
+		return fmt.Errorf("error while parsing %v -listx output: %w", bin, err)
+	}
+
+	known := make(map[string]bool)
+	for _, table := range ti.Tables {
+		for _, tag := range table.Tags {
+			g0 := table.G0
+			if tag.G0 != "" {
+				g0 = tag.G0
+			}
+			known[g0+":"+tag.Name] = true
+		}
+	}
+
+	var unknown []string
+	for group, names := range commonTags {
+		for _, name := range names {
+			if !known[group+":"+name] {
+				unknown = append(unknown, group+":"+name)
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown tags: %v", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+func generate() []byte {
+	groups := make([]string, 0, len(commonTags))
+	for group := range commonTags {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "// Code generated by gen.go; DO NOT EDIT.")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "package tags")
+	for _, group := range groups {
+		names := append([]string(nil), commonTags[group]...)
+		sort.Strings(names)
+
+		fmt.Fprintln(&b)
+		fmt.Fprintf(&b, "// Keys of the common tags of the %v group\n", group)
+		fmt.Fprintln(&b, "const (")
+		for _, name := range names {
+			fmt.Fprintf(&b, "%v = %q\n", constName(group, name), group+":"+name)
+		}
+		fmt.Fprintln(&b, ")")
+	}
+	return b.Bytes()
+}
+
+// constName turns a group and a tag name into a Go identifier (e.g. "IPTC" and "Caption-Abstract"
+// give "IPTCCaptionAbstract")
+func constName(group, tag string) string {
+	return group + strings.Replace(tag, "-", "", -1)
+}
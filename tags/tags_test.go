@@ -0,0 +1,36 @@
+package tags
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTags(t *testing.T) {
+	assert.Equal(t, "EXIF:DateTimeOriginal", EXIFDateTimeOriginal)
+	assert.Equal(t, "XMP:Rating", XMPRating)
+	assert.Equal(t, "IPTC:Caption-Abstract", IPTCCaptionAbstract)
+
+	f, err := parser.ParseFile(token.NewFileSet(), "tags.go", nil, 0)
+	require.Nil(t, err)
+	count := 0
+	for _, decl := range f.Decls {
+		for _, spec := range decl.(*ast.GenDecl).Specs {
+			vs := spec.(*ast.ValueSpec)
+			name := vs.Names[0].Name
+			key, err := strconv.Unquote(vs.Values[0].(*ast.BasicLit).Value)
+			require.Nil(t, err)
+			i := strings.Index(key, ":")
+			require.True(t, i > 0, key)
+			assert.Equal(t, name, key[:i]+strings.Replace(key[i+1:], "-", "", -1))
+			count++
+		}
+	}
+	assert.True(t, count > 0)
+}
@@ -0,0 +1,105 @@
+package exiftool
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExpandTemplate expands the tags referenced by a template, following exiftool's tag name
+// interpolation (as used by -p, -FileName< or -w): $Tag or ${Tag} is replaced by the value of the
+// tag, which can be group qualified (${EXIF:Model}), and $$ by $. Keys are resolved as with the
+// LenientKeys option. The advanced formatting expressions (${Tag;expr}) can't be evaluated without
+// perl, only the following ones are supported:
+//   - DateFmt(format): formats a date with a strftime format (e.g. %Y%m%d)
+//   - lc, uc: converts to lower or upper case
+// An error wrapping ErrKeyNotFound is returned if a tag is missing.
+// Sample :
+//   name, err := ExpandTemplate(fm, "IMG_${DateTimeOriginal;DateFmt(%Y%m%d)}_${Model}")
+func ExpandTemplate(fm FileMetadata, template string) (string, error) {
+	fm.lenientKeys = true
+	var sb strings.Builder
+	for i := 0; i < len(template); i++ {
+		if template[i] != '$' {
+			sb.WriteByte(template[i])
+			continue
+		}
+		i++
+		switch {
+		case i == len(template):
+			return "", fmt.Errorf("incomplete tag reference at the end of %q", template)
+		case template[i] == '$':
+			sb.WriteByte('$')
+		case template[i] == '{':
+			end := strings.IndexByte(template[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("missing } in %q", template)
+			}
+			v, err := expandReference(fm, template[i+1:i+end])
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(v)
+			i += end
+		default:
+			end := i
+			for end < len(template) && isTemplateNameChar(template[end]) {
+				end++
+			}
+			// as with exiftool, names end with a word character
+			for end > i && template[end-1] == '-' {
+				end--
+			}
+			if end == i {
+				return "", fmt.Errorf("invalid tag reference at offset %v of %q", i-1, template)
+			}
+			v, err := expandReference(fm, template[i:end])
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(v)
+			i = end - 1
+		}
+	}
+	return sb.String(), nil
+}
+
+// isTemplateNameChar tells if a character can be part of a tag name referenced without braces,
+// which can't be group qualified (as with exiftool, "$Model:" is the Model tag followed by ":")
+func isTemplateNameChar(c byte) bool {
+	return c == '_' || c == '-' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// expandReference returns the value of a tag reference, optionally followed by an expression
+func expandReference(fm FileMetadata, ref string) (string, error) {
+	tag, expr := ref, ""
+	if i := strings.IndexByte(ref, ';'); i >= 0 {
+		tag, expr = ref[:i], strings.TrimSpace(ref[i+1:])
+	}
+	if tag == "" {
+		return "", fmt.Errorf("empty tag reference in ${%v}", ref)
+	}
+
+	switch {
+	case strings.HasPrefix(expr, "DateFmt(") && strings.HasSuffix(expr, ")"):
+		t, err := fm.GetDateTime(tag)
+		if err != nil {
+			return "", fmt.Errorf("error while expanding %v: %w", tag, err)
+		}
+		return formatStrftime(t, expr[len("DateFmt("):len(expr)-1])
+	}
+
+	v, err := fm.GetString(tag)
+	if err != nil {
+		return "", fmt.Errorf("error while expanding %v: %w", tag, err)
+	}
+	switch expr {
+	case "":
+		return v, nil
+	case "lc":
+		return strings.ToLower(v), nil
+	case "uc":
+		return strings.ToUpper(v), nil
+	default:
+		return "", fmt.Errorf("unsupported expression %q in ${%v}", expr, ref)
+	}
+}
@@ -0,0 +1,54 @@
+package exiftool
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	fm := NewFileMetadata("a.jpg")
+	fm.Fields = map[string]interface{}{
+		"EXIF:DateTimeOriginal":  "2019:04:04 13:18:04",
+		"EXIF:Model":             "Pixel 3",
+		"EXIF:ISO":               float64(800),
+		"File:FileTypeExtension": "jpg",
+	}
+
+	var tcs = []struct {
+		tcID       string
+		inTemplate string
+		expValue   string
+		expErr     bool
+	}{
+		{"sample", "IMG_${DateTimeOriginal;DateFmt(%Y%m%d)}_${Model}", "IMG_20190404_Pixel 3", false},
+		{"noBraces", "$Model-$ISO.$FileTypeExtension", "Pixel 3-800.jpg", false},
+		{"group", "${EXIF:Model}", "Pixel 3", false},
+		{"noBracesGroupSeparator", "$Model:x", "Pixel 3:x", false},
+		{"dollar", "$$5 $$Model", "$5 $Model", false},
+		{"case", "${Model;uc} ${Model;lc}", "PIXEL 3 pixel 3", false},
+		{"dateTime", "${DateTimeOriginal;DateFmt(%Y-%m-%d %H:%M:%S)}", "2019-04-04 13:18:04", false},
+		{"noTag", "IMG.jpg", "IMG.jpg", false},
+		{"missingTag", "${Copyright}", "", true},
+		{"notDate", "${Model;DateFmt(%Y)}", "", true},
+		{"unsupportedFormat", "${DateTimeOriginal;DateFmt(%Q)}", "", true},
+		{"unsupportedExpression", "${Model;s/ /_/g}", "", true},
+		{"missingBrace", "${Model", "", true},
+		{"emptyReference", "${;lc}", "", true},
+		{"trailingDollar", "IMG$", "", true},
+		{"invalidReference", "IMG_$.jpg", "", true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc // Pin variable
+		t.Run(tc.tcID, func(t *testing.T) {
+			v, err := ExpandTemplate(fm, tc.inTemplate)
+			assert.Equal(t, tc.expErr, err != nil, "%v", err)
+			assert.Equal(t, tc.expValue, v)
+		})
+	}
+
+	_, err := ExpandTemplate(fm, "${Copyright}")
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+}
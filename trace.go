@@ -0,0 +1,62 @@
+package exiftool
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// TraceProtocol copies everything written to exiftool's stdin and read from its merged output to
+// w, lines being prefixed by "> " (sent to exiftool) or "< " (received from exiftool). It is
+// meant to be used to debug or report protocol issues, as it slows down every command. Errors
+// returned by w are ignored.
+// Sample :
+//   e, err := NewExiftool(TraceProtocol(os.Stderr))
+func TraceProtocol(w io.Writer) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		e.trace = w
+		return nil
+	}
+}
+
+// protocolTracer serializes the traces of both directions of the protocol
+type protocolTracer struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+// writer returns a writer tracing lines with the given prefix
+func (t *protocolTracer) writer(prefix string) io.Writer {
+	return &traceWriter{tracer: t, prefix: []byte(prefix), lineStart: true}
+}
+
+type traceWriter struct {
+	tracer    *protocolTracer
+	prefix    []byte
+	lineStart bool
+}
+
+func (w *traceWriter) Write(p []byte) (int, error) {
+	w.tracer.lock.Lock()
+	defer w.tracer.lock.Unlock()
+
+	for rest := p; len(rest) > 0; {
+		line := rest
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			line = rest[:i+1]
+		}
+		if w.lineStart {
+			w.tracer.w.Write(w.prefix)
+		}
+		w.tracer.w.Write(line)
+		w.lineStart = line[len(line)-1] == '\n'
+		rest = rest[len(line):]
+	}
+	return len(p), nil
+}
+
+// tracedWriteCloser traces what is written to a WriteCloser
+type tracedWriteCloser struct {
+	io.Writer
+	io.Closer
+}
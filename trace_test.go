@@ -0,0 +1,40 @@
+package exiftool
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceWriter(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := &protocolTracer{w: &buf}
+	in, out := tracer.writer("> "), tracer.writer("< ")
+
+	in.Write([]byte("-j\n-exe"))
+	in.Write([]byte("cute\n"))
+	out.Write([]byte("[{}]\n{rea"))
+	out.Write([]byte("dy}\n"))
+
+	assert.Equal(t, "> -j\n> -execute\n< [{}]\n< {ready}\n", buf.String())
+}
+
+func TestTraceProtocol(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	e, err := NewExiftool(TraceProtocol(&buf))
+	require.Nil(t, err)
+	mds := e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	require.Len(t, mds, 1)
+	require.Nil(t, mds[0].Err)
+	require.Nil(t, e.Close())
+
+	trace := buf.String()
+	assert.True(t, strings.Contains(trace, "> ./testdata/20190404_131804.jpg\n> -execute\n"), trace)
+	assert.True(t, strings.Contains(trace, "< {ready}\n"), trace)
+	assert.True(t, strings.Contains(trace, "> -stay_open\n> False\n"), trace)
+}
@@ -0,0 +1,110 @@
+package exiftool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Tracer creates spans for the ExtractMetadata and WriteMetadata calls (and their variants). Its
+// methods match the ones of OpenTelemetry's API, so that an adapter is a few lines long.
+// Sample adapter :
+//   type otelTracer struct{ t trace.Tracer }
+//   func (o otelTracer) Start(ctx context.Context, name string) (context.Context, exiftool.Span) {
+//     ctx, s := o.t.Start(ctx, name)
+//     return ctx, otelSpan{s}
+//   }
+type Tracer interface {
+	// Start starts a span, the returned context being the one given to exiftool's commands
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Attribute is a span attribute, whose Value is a string, an int or an int64
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Span attribute keys
+const (
+	// AttrFiles is the number of files processed by the call
+	AttrFiles = "exiftool.files"
+	// AttrFailedFiles is the number of files whose FileMetadata.Err is set at the end of the call
+	AttrFailedFiles = "exiftool.failed_files"
+	// AttrBytesRead is the number of bytes read from exiftool during the call
+	AttrBytesRead = "exiftool.bytes_read"
+	// AttrVersion is the version of exiftool
+	AttrVersion = "exiftool.version"
+)
+
+// Tracing creates a span per ExtractMetadata and WriteMetadata call (and their variants), named
+// "exiftool.extract" or "exiftool.write", see Tracer and the Attr* attribute keys. The first error
+// of the call is recorded.
+// Sample :
+//   e, err := NewExiftool(Tracing(myOtelAdapter))
+func Tracing(t Tracer) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		e.tracer = t
+		return nil
+	}
+}
+
+// Version returns the version of exiftool (e.g. "12.40")
+func (e *Exiftool) Version() (string, error) {
+	e.acquire()
+	defer e.lock.Unlock()
+	return e.exiftoolVersion()
+}
+
+// exiftoolVersion returns the cached version of exiftool, the caller must hold the lock
+func (e *Exiftool) exiftoolVersion() (string, error) {
+	if e.version != "" {
+		return e.version, nil
+	}
+	resp, err := e.roundTrip([]string{"-ver"})
+	if err != nil {
+		return "", fmt.Errorf("error while reading exiftool's version: %w", err)
+	}
+	e.version = strings.TrimSpace(string(resp))
+	return e.version, nil
+}
+
+// startSpan starts the span of a call when a Tracer is registered. The returned function ends it,
+// reporting the results of the call. The caller must hold the lock.
+func (e *Exiftool) startSpan(ctx context.Context, op Op, files int) (context.Context, func([]FileMetadata)) {
+	if e.tracer == nil {
+		return ctx, func([]FileMetadata) {}
+	}
+
+	ctx, span := e.tracer.Start(ctx, "exiftool."+op.String())
+	span.SetAttributes(Attribute{Key: AttrFiles, Value: files})
+	if v, err := e.exiftoolVersion(); err == nil {
+		span.SetAttributes(Attribute{Key: AttrVersion, Value: v})
+	}
+	bytesRead := e.stats.totalBytesRead()
+
+	return ctx, func(fms []FileMetadata) {
+		failed := 0
+		for _, fm := range fms {
+			if fm.Err == nil {
+				continue
+			}
+			if failed == 0 {
+				span.RecordError(fm.Err)
+			}
+			failed++
+		}
+		span.SetAttributes(
+			Attribute{Key: AttrFailedFiles, Value: failed},
+			Attribute{Key: AttrBytesRead, Value: e.stats.totalBytesRead() - bytesRead},
+		)
+		span.End()
+	}
+}
@@ -0,0 +1,89 @@
+package exiftool
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSpan struct {
+	name  string
+	attrs map[string]interface{}
+	errs  []error
+	ended bool
+}
+
+func (s *testSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *testSpan) RecordError(err error) {
+	s.errs = append(s.errs, err)
+}
+
+func (s *testSpan) End() {
+	s.ended = true
+}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, spanName string) (context.Context, Span) {
+	s := &testSpan{name: spanName, attrs: map[string]interface{}{}}
+	t.spans = append(t.spans, s)
+	return ctx, s
+}
+
+func TestTracing(t *testing.T) {
+	t.Parallel()
+
+	tr := &testTracer{}
+	e, err := NewExiftool(Tracing(tr))
+	require.Nil(t, err)
+	defer e.Close()
+
+	e.ExtractMetadata("./testdata/20190404_131804.jpg", "./testdata/nonExisting.jpg")
+	e.WriteMetadata([]FileMetadata{{File: "./testdata/nonExisting.jpg"}})
+
+	require.Len(t, tr.spans, 2)
+
+	s := tr.spans[0]
+	assert.Equal(t, "exiftool.extract", s.name)
+	assert.True(t, s.ended)
+	assert.Equal(t, 2, s.attrs[AttrFiles])
+	assert.Equal(t, 1, s.attrs[AttrFailedFiles])
+	assert.True(t, s.attrs[AttrBytesRead].(int64) > 0)
+	assert.NotEmpty(t, s.attrs[AttrVersion])
+	assert.Equal(t, []error{ErrNotExist}, s.errs)
+
+	s = tr.spans[1]
+	assert.Equal(t, "exiftool.write", s.name)
+	assert.True(t, s.ended)
+	assert.Equal(t, 1, s.attrs[AttrFiles])
+	assert.Equal(t, 1, s.attrs[AttrFailedFiles])
+	assert.Equal(t, int64(0), s.attrs[AttrBytesRead])
+}
+
+func TestVersion(t *testing.T) {
+	t.Parallel()
+
+	e, err := NewExiftool()
+	require.Nil(t, err)
+	defer e.Close()
+
+	v, err := e.Version()
+	require.Nil(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^\d+\.\d+$`), v)
+
+	// the version is still readable after a command
+	e.ExtractMetadata("./testdata/20190404_131804.jpg")
+	v2, err := e.Version()
+	require.Nil(t, err)
+	assert.Equal(t, v, v2)
+}
@@ -0,0 +1,96 @@
+package exiftool
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// Transport connects an Exiftool instance to exiftool, or to a double of it speaking the
+// -stay_open protocol (see the exiftooltest package). By default, the exiftool binary is run.
+type Transport interface {
+	// Start starts exiftool with the given arguments (-stay_open True -@ - ...), returning its
+	// standard input and its merged standard and error outputs. It is called again, once Wait has
+	// returned, when exiftool is restarted (see Restart).
+	Start(args []string) (stdin io.WriteCloser, stdout io.ReadCloser, err error)
+	// Wait waits for exiftool to stop once its standard input and output are closed
+	Wait() error
+}
+
+// UseTransport connects the instance to exiftool with the given transport instead of running the
+// exiftool binary, whose path is then ignored
+// Sample :
+//   e, err := NewExiftool(UseTransport(exiftooltest.NewFakeTransport(handler)))
+func UseTransport(t Transport) func(*Exiftool) error {
+	return func(e *Exiftool) error {
+		e.transport = t
+		return nil
+	}
+}
+
+// NewProcessTransport returns the Transport running the exiftool binary (the default one), e.g. to
+// be wrapped by another Transport. Exiftool is killed when the application exits, even abruptly:
+// on Windows it is assigned to a job object, on Linux it gets a parent death signal (SIGKILL).
+func NewProcessTransport(binPath string) Transport {
+	return &processTransport{path: binPath}
+}
+
+type processTransport struct {
+	path  string
+	attrs processAttrs
+	cmd   *exec.Cmd
+	job   io.Closer
+}
+
+// Start starts exiftool, its stdout and stderr being merged
+func (p *processTransport) Start(args []string) (io.WriteCloser, io.ReadCloser, error) {
+	p.cmd = exec.Command(p.path, args...)
+	p.cmd.SysProcAttr = sysProcAttr(p.attrs)
+	// an os pipe (rather than an io.Pipe) is closed when exiftool exits, so that a crash is detected
+	// instead of blocking the reading of the output forever
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error when piping stdout: %w", err)
+	}
+
+	p.cmd.Stdout = w
+	p.cmd.Stderr = w
+
+	stdin, err := p.cmd.StdinPipe()
+	if err != nil {
+		r.Close()
+		w.Close()
+		return nil, nil, fmt.Errorf("error when piping stdin: %w", err)
+	}
+
+	err = p.cmd.Start()
+	// the write end is only used by exiftool
+	w.Close()
+	if err != nil {
+		r.Close()
+		return nil, nil, fmt.Errorf("error when executing command: %w", err)
+	}
+
+	if p.job, err = bindToParent(p.cmd.Process); err != nil {
+		p.cmd.Process.Kill()
+		p.cmd.Wait()
+		r.Close()
+		return nil, nil, err
+	}
+	return stdin, r, nil
+}
+
+func (p *processTransport) Wait() error {
+	if p.cmd == nil {
+		return fmt.Errorf("process not started")
+	}
+	err := p.cmd.Wait()
+	if p.job != nil {
+		if cErr := p.job.Close(); cErr != nil && err == nil {
+			err = fmt.Errorf("error when closing job object: %w", cErr)
+		}
+		p.job = nil
+	}
+	return err
+}
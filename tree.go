@@ -0,0 +1,27 @@
+package exiftool
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// walkFiles returns the regular files of the given directories and of their sub-directories, in
+// lexical order
+func walkFiles(dirs ...string) ([]string, error) {
+	var files []string
+	for _, d := range dirs {
+		err := filepath.Walk(d, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.Mode().IsRegular() {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}